@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	rdf "github.com/deiu/rdf2go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat   string
+	exportOutput   string
+	exportInferred bool
+	exportHash     bool
+	exportProfile  string
+
+	// exportNow stamps prov:generatedAtTime; tests replace it.
+	exportNow = time.Now
+)
+
+func init() {
+	exportCmd.PersistentFlags().StringVar(&exportFormat, "format", "turtle", "Output format: turtle, ntriples, jsonld, nquads, or trig")
+	exportCmd.PersistentFlags().StringVarP(&exportOutput, "output", "o", "", "Write to this file instead of stdout")
+	exportCmd.PersistentFlags().StringVar(&exportProfile, "profile", "", "Project through this profile from the vault config")
+	exportCmd.Flags().BoolVar(&exportInferred, "inferred", false, "Also write links inferred from inverse and symmetric relations")
+	exportCmd.Flags().BoolVar(&exportHash, "hash", false, "Print a SHA-256 digest of the canonical dataset instead of the data")
+	exportCmd.AddCommand(exportOntologyCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the vault's RDF projection",
+	Long: `Export the vault's RDF projection.
+
+With --format nquads or trig, each note's triples go in their own named graph,
+described in the default graph with its source file, content hash, and
+prov:generatedAtTime, so a triple store can drop and reload single notes.
+
+Output is canonical: triples are sorted and blank nodes labelled
+deterministically, so unchanged notes export identically. --hash
+prints a digest of the canonical dataset instead, for spotting semantic
+changes in CI; it ignores --format and leaves out the provenance, which
+changes on every run.
+
+--profile selects a projection profile from the vault config, which can
+trim bodies, keep only some predicates, leave notes out by tag, type, or
+frontmatter flag, and rewrite the base URI. Links to notes a profile
+leaves out are dropped too.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := export.ParseFormat(exportFormat)
+		if err != nil {
+			return err
+		}
+		proj, err := exportProjector()
+		if err != nil {
+			return err
+		}
+		if format.HasGraphs() && !exportHash {
+			quads, err := vaultQuads(cmd, proj)
+			if err != nil {
+				return err
+			}
+			return writeOutput(cmd, func(w io.Writer) error {
+				return export.WriteQuads(w, quads, format)
+			})
+		}
+
+		var triples []*rdf.Triple
+		proj.EachVaultTriple(vaultNotes(cmd, notes.Filter{}), func(t *rdf.Triple) bool {
+			triples = append(triples, t)
+			return true
+		})
+		if exportInferred {
+			triples = append(triples, proj.Infer(triples)...)
+		}
+		if exportHash {
+			return writeOutput(cmd, func(w io.Writer) error {
+				_, err := fmt.Fprintln(w, export.Hash(triples))
+				return err
+			})
+		}
+		return writeExport(cmd, triples)
+	},
+}
+
+var exportOntologyCmd = &cobra.Command{
+	Use:   "ontology",
+	Short: "Export an RDFS/OWL ontology for the terms the vault uses",
+	Long: `Declare every class and property that appears in the vault's RDF
+projection. Terms minted in the weave namespace get labels, domains, ranges,
+and sub-class and sub-property links; terms from other vocabularies are
+declared with any inverse or symmetric relations from the config.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		proj, err := exportProjector()
+		if err != nil {
+			return err
+		}
+		triples := proj.Ontology(func(yield func(*rdf.Triple) bool) {
+			proj.EachVaultTriple(vaultNotes(cmd, notes.Filter{}), yield)
+		})
+		return writeExport(cmd, triples)
+	},
+}
+
+// exportProjector is the configured projector with --profile applied.
+func exportProjector() (*rdfproj.Projector, error) {
+	if exportProfile == "" {
+		return projector(), nil
+	}
+	profile, ok := cfg.Profiles[exportProfile]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", exportProfile)
+	}
+	return projector().WithProfile(profile), nil
+}
+
+// vaultQuads projects every note into its own named graph. Inferred links
+// span notes, so with --inferred they get a graph of their own.
+func vaultQuads(cmd *cobra.Command, proj *rdfproj.Projector) ([]export.Quad, error) {
+	entries := slices.Collect(vaultEntries(cmd, notes.Filter{}))
+	all := make([]markdown.Note, len(entries))
+	for i, entry := range entries {
+		all[i] = entry.Note
+	}
+	proj = proj.WithVault(all)
+
+	generated := exportNow()
+	var quads []export.Quad
+	var triples []*rdf.Triple
+	for _, entry := range entries {
+		data, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", entry.Path, err)
+		}
+		rel, err := filepath.Rel(cfg.VaultPath, entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("relative path of %s: %w", entry.Path, err)
+		}
+		sum := sha256.Sum256(data)
+		src := rdfproj.Source{Path: filepath.ToSlash(rel), Hash: hex.EncodeToString(sum[:]), Generated: generated}
+
+		noteQuads := proj.NoteQuads(entry.Note, src)
+		for _, q := range noteQuads {
+			if q.Graph != "" {
+				triples = append(triples, q.Triple)
+			}
+		}
+		quads = append(quads, noteQuads...)
+	}
+
+	if exportInferred {
+		graph := proj.InferredGraph()
+		for _, t := range proj.Infer(triples) {
+			quads = append(quads, export.Quad{Triple: t, Graph: graph})
+		}
+	}
+	return quads, nil
+}
+
+// writeExport serializes triples in the chosen format to the chosen output.
+func writeExport(cmd *cobra.Command, triples []*rdf.Triple) error {
+	format, err := export.ParseFormat(exportFormat)
+	if err != nil {
+		return err
+	}
+	return writeOutput(cmd, func(w io.Writer) error {
+		return export.Write(w, triples, format)
+	})
+}
+
+// writeOutput calls write with the --output file, or with stdout.
+func writeOutput(cmd *cobra.Command, write func(io.Writer) error) error {
+	if exportOutput == "" {
+		return write(cmd.OutOrStdout())
+	}
+
+	f, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("create output: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
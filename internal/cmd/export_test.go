@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func exportVault(t *testing.T) string {
+	t.Helper()
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	target, err := notes.Create(vault, markdown.Note{Title: "Target"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Source", Links: []links.Link{{ID: target, Type: "elaborates"}}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return vault
+}
+
+func TestExportVault(t *testing.T) {
+	vault := exportVault(t)
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "--format", "ntriples"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := `<http://localhost/notes/source-20250101000000> <http://weave.dev/vocab#elaborates> <http://localhost/notes/target-20250101000000> .`
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("stdout missing %s:\n%s", want, stdout.String())
+	}
+}
+
+func TestExportResolvesLinks(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "Target", Aliases: []string{"the goal"}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	fuzzy := []links.Link{{ID: "the goal", Type: "elaborates"}, {ID: "target-2025", Type: "related"}}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Source", Links: fuzzy}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, format := range []string{"ntriples", "nquads"} {
+		resetFlags()
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"--vault", vault, "export", "--format", format})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		rootCmd.SetOut(nil)
+
+		for _, want := range []string{
+			`<http://localhost/notes/source-20250101000000> <http://weave.dev/vocab#elaborates> <http://localhost/notes/target-20250101000000> `,
+			`<http://localhost/notes/source-20250101000000> <http://www.w3.org/2004/02/skos/core#related> <http://localhost/notes/target-20250101000000> `,
+		} {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("%s output missing %s:\n%s", format, want, stdout.String())
+			}
+		}
+	}
+}
+
+func TestExportOntologyToFile(t *testing.T) {
+	vault := exportVault(t)
+	out := filepath.Join(t.TempDir(), "ontology.nt")
+
+	resetFlags()
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "ontology", "--format", "ntriples", "--output", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	want := `<http://weave.dev/vocab#elaborates> <http://www.w3.org/2000/01/rdf-schema#subPropertyOf> <http://weave.dev/vocab#linksTo> .`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("ontology missing %s:\n%s", want, data)
+	}
+}
+
+func TestExportInferred(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := notes.Create(vault, markdown.Note{Title: "Parent"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Child", Links: []links.Link{{ID: parent, Type: "broader"}}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	inferred := `<http://localhost/notes/parent-20250101000000> <http://www.w3.org/2004/02/skos/core#narrower> <http://localhost/notes/child-20250101000000> .`
+
+	for _, include := range []bool{false, true} {
+		resetFlags()
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		args := []string{"--vault", vault, "export", "--format", "ntriples"}
+		if include {
+			args = append(args, "--inferred")
+		}
+		rootCmd.SetArgs(args)
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if got := strings.Contains(stdout.String(), inferred); got != include {
+			t.Errorf("--inferred=%v: output contains inferred narrower = %v", include, got)
+		}
+	}
+	rootCmd.SetOut(nil)
+}
+
+func TestExportNQuads(t *testing.T) {
+	vault := exportVault(t)
+	exportNow = func() time.Time { return time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { exportNow = time.Now }()
+
+	data, err := os.ReadFile(filepath.Join(vault, "2025", "01", "source-20250101000000.md"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	sum := sha256.Sum256(data)
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "--format", "nquads"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	graph := "<http://localhost/graphs/notes/source-20250101000000>"
+	for _, want := range []string{
+		`<http://localhost/notes/source-20250101000000> <http://weave.dev/vocab#elaborates> <http://localhost/notes/target-20250101000000> ` + graph + ` .`,
+		graph + ` <http://www.w3.org/ns/prov#generatedAtTime> "2025-02-01T00:00:00Z"^^<http://www.w3.org/2001/XMLSchema#dateTime> .`,
+		graph + ` <http://weave.dev/vocab#sourcePath> "2025/01/source-20250101000000.md" .`,
+		graph + ` <http://weave.dev/vocab#contentHash> "sha256:` + hex.EncodeToString(sum[:]) + `" .`,
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %s:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestExportTriGInferred(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := notes.Create(vault, markdown.Note{Title: "Parent"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Child", Links: []links.Link{{ID: parent, Type: "broader"}}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "--format", "trig", "--inferred"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "<http://localhost/graphs/inferred> {\n  <http://localhost/notes/parent-20250101000000> <http://www.w3.org/2004/02/skos/core#narrower> <http://localhost/notes/child-20250101000000> .\n}"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("output missing inferred graph:\n%s", stdout.String())
+	}
+}
+
+func TestExportHash(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := notes.Create(vault, markdown.Note{Title: "Note", Tags: []string{"b", "a"}}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	hash := func() string {
+		t.Helper()
+		resetFlags()
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		defer rootCmd.SetOut(nil)
+		rootCmd.SetArgs([]string{"--vault", vault, "export", "--hash", "--format", "nquads"})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return strings.TrimSpace(stdout.String())
+	}
+
+	first := hash()
+	if len(first) != 64 {
+		t.Fatalf("hash = %q, want 64 hex digits", first)
+	}
+	if again := hash(); again != first {
+		t.Errorf("second hash = %s, want %s", again, first)
+	}
+
+	if err := notes.Update(vault, id, markdown.Note{ID: id, Title: "Renamed", Tags: []string{"a", "b"}, Created: ts}, ts); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if changed := hash(); changed == first {
+		t.Error("hash unchanged after renaming the note")
+	}
+}
+
+func TestExportProfile(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	secret, err := notes.Create(vault, markdown.Note{Title: "Secret", Body: "hidden", Extra: map[string]any{"private": true}}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Public", Body: "visible text", Links: []links.Link{{ID: secret}}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(vault, ".weave"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	config := "profiles:\n  public:\n    body: none\n    exclude:\n      flags: [private]\n    base_uri: https://example.org/\n"
+	if err := os.WriteFile(filepath.Join(vault, ".weave", "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, format := range []string{"ntriples", "nquads"} {
+		resetFlags()
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"--vault", vault, "export", "--profile", "public", "--format", format})
+		err := rootCmd.Execute()
+		rootCmd.SetOut(nil)
+		if err != nil {
+			t.Fatalf("%s: Execute() error = %v", format, err)
+		}
+
+		out := stdout.String()
+		if !strings.Contains(out, `<https://example.org/notes/public-20250101000000> <http://purl.org/dc/terms/title> "Public"`) {
+			t.Errorf("%s: output missing the public note under the profile's base URI:\n%s", format, out)
+		}
+		for _, leak := range []string{"secret", "Secret", "visible text", "localhost"} {
+			if strings.Contains(out, leak) {
+				t.Errorf("%s: output contains %q:\n%s", format, leak, out)
+			}
+		}
+	}
+
+	resetFlags()
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "--profile", "nope"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), `unknown profile "nope"`) {
+		t.Errorf("Execute() error = %v, want unknown profile", err)
+	}
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat string
+	importForce  bool
+)
+
+func init() {
+	importRDFCmd.Flags().StringVar(&importFormat, "format", "", "Input format: turtle, ntriples, or jsonld (default: from the file extension)")
+	importRDFCmd.Flags().BoolVar(&importForce, "force", false, "Overwrite vault notes even when they are as new as the import")
+	importCmd.AddCommand(importRDFCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import notes from other formats",
+}
+
+var importRDFCmd = &cobra.Command{
+	Use:   "rdf <file>",
+	Short: "Create or update notes from an RDF projection",
+	Long: `Read Turtle, N-Triples, or JSON-LD in the vocabulary written by the RDF
+projection and turn it back into notes. Pass - to read from stdin.
+
+Notes missing from the vault are created. Existing notes are updated only
+when the import is newer; otherwise they are reported as conflicts and left
+alone unless --force is given. Frontmatter keys weave does not know, such
+as private: true, are not in the projection: updated notes keep their own,
+and new notes are created without them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+
+		name := importFormat
+		if name == "" {
+			if path == "-" {
+				return fmt.Errorf("--format is required when reading from stdin")
+			}
+			name = filepath.Ext(path)
+		}
+		format, err := export.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+
+		var r io.Reader = cmd.InOrStdin()
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("open input: %w", err)
+			}
+			defer f.Close()
+			r = f
+		}
+
+		triples, err := export.Read(r, format)
+		if err != nil {
+			return err
+		}
+
+		// Conflicts are reported as an error; the usage text would bury them.
+		cmd.SilenceUsage = true
+		stdout := cmd.OutOrStdout()
+		stderr := cmd.ErrOrStderr()
+
+		imported, errs := projector().TriplesToNotes(triples)
+		for _, err := range errs {
+			fmt.Fprintf(stderr, "warning: skipped %v\n", err)
+		}
+
+		conflicts := 0
+		for _, note := range imported {
+			action, err := notes.Import(cfg.VaultPath, note, importForce)
+			if err != nil {
+				return fmt.Errorf("import %s: %w", note.ID, err)
+			}
+			if action == notes.ImportConflict {
+				conflicts++
+				fmt.Fprintf(stderr, "conflict: %s differs from the vault copy, which is not older\n", note.ID)
+				continue
+			}
+			fmt.Fprintf(stdout, "%s\t%s\n", action, note.ID)
+		}
+
+		if conflicts > 0 {
+			return fmt.Errorf("%d conflicting notes left unchanged; rerun with --force to overwrite them", conflicts)
+		}
+		return nil
+	},
+}
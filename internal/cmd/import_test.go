@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+)
+
+func TestImportRDF(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	source := []markdown.Note{
+		{ID: "fresh-20250101000000", Title: "Fresh", Created: ts, Modified: ts},
+		{ID: "kept-20250101000000", Title: "Imported", Created: ts, Modified: ts},
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Kept", Body: "local"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, rdfproj.VaultToTriples(source, ""), export.NTriples); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "vault.nt")
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "import", "rdf", file})
+
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 conflicting") {
+		t.Fatalf("Execute() error = %v, want conflict error", err)
+	}
+	if stdout.String() != "created\tfresh-20250101000000\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "conflict: kept-20250101000000") {
+		t.Errorf("stderr = %q, want conflict report", stderr.String())
+	}
+
+	kept, err := notes.Read(vault, "kept-20250101000000")
+	if err != nil || kept.Title != "Kept" {
+		t.Errorf("conflicting note = %+v, %v, want it untouched", kept, err)
+	}
+}
+
+func TestImportRDFOwnExport(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	target, err := notes.Create(vault, markdown.Note{Title: "Target", Body: "## Design Notes\n\nText ^q1\n"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, err = notes.Create(vault, markdown.Note{Title: "Source", Links: []links.Link{
+		{ID: target, Type: "related", Block: "q1"},
+		{ID: target, Type: "related", Anchor: "Design Notes"},
+	}}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	resetFlags()
+	file := filepath.Join(t.TempDir(), "vault.ttl")
+	rootCmd.SetArgs([]string{"--vault", vault, "export", "--output", file})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("export: Execute() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "import", "rdf", file})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("import: Execute() error = %v\n%s", err, stdout.String())
+	}
+	if strings.Contains(stdout.String(), "created") || strings.Contains(stdout.String(), "updated") {
+		t.Errorf("importing the vault's own export changed it:\n%s", stdout.String())
+	}
+}
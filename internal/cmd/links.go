@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/spf13/cobra"
+)
+
+var linksDryRun bool
+
+func init() {
+	linksNormalizeCmd.Flags().BoolVar(&linksDryRun, "dry-run", false, "Show the notes that would change without writing them")
+	linksCmd.AddCommand(linksNormalizeCmd, linksCheckCmd)
+	rootCmd.AddCommand(linksCmd)
+}
+
+var linksCmd = &cobra.Command{
+	Use:   "links",
+	Short: "Work with links between notes",
+}
+
+var linksNormalizeCmd = &cobra.Command{
+	Use:   "normalize",
+	Short: "Rewrite links to name their targets by ID",
+	Long: `Rewrite [[links]] in note bodies and links in frontmatter that name a note
+by the start of its ID, its title, or one of its aliases, so they use the
+note's full ID. Links that match no note, or several, are reported and left
+as written.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stdout := cmd.OutOrStdout()
+		stderr := cmd.ErrOrStderr()
+		now := time.Now()
+		entries := slices.Collect(vaultEntries(cmd, notes.Filter{}))
+
+		targets := make([]links.Target, len(entries))
+		for i, entry := range entries {
+			targets[i] = links.Target{ID: entry.Note.ID, Title: entry.Note.Title, Aliases: entry.Note.Aliases}
+		}
+		resolver := links.NewResolver(targets)
+
+		changed := 0
+		for _, entry := range entries {
+			rel := vaultPath(entry.Path)
+			rewritten := 0
+			resolve := func(link links.Link) links.Link {
+				canonical, err := resolver.ResolveLink(link)
+				if err != nil {
+					fmt.Fprintf(stderr, "warning: %s: %v\n", rel, err)
+					return link
+				}
+				if canonical != link {
+					rewritten++
+				}
+				return canonical
+			}
+
+			note := entry.Note
+			note.Body = links.ReplaceLinkRefs(note.Body, resolve)
+			note.Links = slices.Clone(note.Links)
+			for i := range note.Links {
+				note.Links[i] = resolve(note.Links[i])
+			}
+			if rewritten == 0 {
+				continue
+			}
+
+			if !linksDryRun {
+				if err := notes.Update(cfg.VaultPath, note.ID, note, now); err != nil {
+					return fmt.Errorf("update %s: %w", rel, err)
+				}
+			}
+			fmt.Fprintf(stdout, "%s\t%d links\n", rel, rewritten)
+			changed++
+		}
+
+		reportChanged(cmd, changed, linksDryRun)
+		return nil
+	},
+}
+
+var linksCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report links to missing notes, headings or blocks",
+	Long: `Check every [[link]] in note bodies and every link in frontmatter. A link
+is broken when it matches no note or several, or when it names a heading
+([[id#Heading]]) or block ([[id^block-id]]) the target does not have.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries := slices.Collect(vaultEntries(cmd, notes.Filter{}))
+
+		targets := make([]links.Target, len(entries))
+		bodies := make(map[string]string, len(entries))
+		for i, entry := range entries {
+			targets[i] = links.Target{ID: entry.Note.ID, Title: entry.Note.Title, Aliases: entry.Note.Aliases}
+			bodies[entry.Note.ID] = entry.Note.Body
+		}
+		resolver := links.NewResolver(targets)
+		structures := make(map[string]markdown.Structure)
+
+		cmd.SilenceUsage = true
+		out := cmd.OutOrStdout()
+		broken := 0
+		for _, entry := range entries {
+			rel := vaultPath(entry.Path)
+			report := func(format string, a ...any) {
+				fmt.Fprintf(out, "%s: %s\n", rel, fmt.Sprintf(format, a...))
+				broken++
+			}
+			for _, link := range append(links.ParseLinks(entry.Note.Body), entry.Note.Links...) {
+				link, err := resolver.ResolveLink(link)
+				if err != nil {
+					report("%v", err)
+					continue
+				}
+				if link.Anchor == "" && link.Block == "" {
+					continue
+				}
+				id := link.ID
+				s, ok := structures[id]
+				if !ok {
+					s = markdown.ParseStructure(bodies[id])
+					structures[id] = s
+				}
+				switch {
+				case link.Block != "" && !s.HasBlock(link.Block):
+					report("no block ^%s in %s", link.Block, id)
+				case link.Anchor != "" && !s.HasAnchor(link.Anchor):
+					report("no heading %q in %s", link.Anchor, id)
+				}
+			}
+		}
+		if broken > 0 {
+			return fmt.Errorf("%d broken links", broken)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func TestLinksNormalize(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	target, err := notes.Create(vault, markdown.Note{Title: "Go Generics", Aliases: []string{"generics"}}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Go Channels"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	source, err := notes.Create(vault, markdown.Note{
+		Title: "Source",
+		Body:  "See [[go generics]], [[related::generics|type params]], [[go-]] and [[nowhere]].",
+		Links: []links.Link{{ID: "go-gen", Type: "linksTo"}},
+	}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	run := func(args ...string) (string, string) {
+		t.Helper()
+		resetFlags()
+		var stdout, stderr bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetErr(&stderr)
+		defer rootCmd.SetOut(nil)
+		defer rootCmd.SetErr(nil)
+		rootCmd.SetArgs(append([]string{"--vault", vault, "links", "normalize"}, args...))
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		return stdout.String(), stderr.String()
+	}
+
+	stdout, stderr := run("--dry-run")
+	if !strings.Contains(stdout, "\t3 links\n") || !strings.HasSuffix(stdout, "1 notes would change\n") {
+		t.Errorf("dry run stdout:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, `"go-" is ambiguous`) || !strings.Contains(stderr, `no note matches "nowhere"`) {
+		t.Errorf("dry run stderr:\n%s", stderr)
+	}
+	if note, _ := notes.Read(vault, source); strings.Contains(note.Body, target) {
+		t.Errorf("dry run rewrote the body: %s", note.Body)
+	}
+
+	run()
+	note, err := notes.Read(vault, source)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := "See [[" + target + "]], [[related::" + target + "|type params]], [[go-]] and [[nowhere]]."
+	if note.Body != want {
+		t.Errorf("Body = %q, want %q", note.Body, want)
+	}
+	if len(note.Links) != 1 || note.Links[0].ID != target {
+		t.Errorf("Links = %+v, want the canonical ID", note.Links)
+	}
+
+	if stdout, _ := run(); !strings.HasSuffix(stdout, "0 notes changed\n") {
+		t.Errorf("second run stdout:\n%s", stdout)
+	}
+}
+
+func TestLinksCheck(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	target, err := notes.Create(vault, markdown.Note{Title: "Design", Body: "## Goals\n\nKeep it small. ^small"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "C# tips"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{
+		Title: "Source",
+		Body:  "[[Design#Goals]] [[design^small]] [[Design#Scope]] [[" + target + "^big]] [[nowhere]] [[C# tips]]",
+		Links: []links.Link{{ID: target, Type: "linksTo", Anchor: "goals"}},
+	}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "links", "check"})
+	err = rootCmd.Execute()
+	if err == nil || err.Error() != "3 broken links" {
+		t.Errorf("Execute() error = %v, want 3 broken links", err)
+	}
+	for _, want := range []string{`no heading "Scope" in ` + target, "no block ^big in " + target, `no note matches "nowhere"`} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/notes"
+	rdf "github.com/deiu/rdf2go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rdfDiffFormat      string
+	rdfDiffInputFormat string
+)
+
+func init() {
+	rdfDiffCmd.Flags().StringVar(&rdfDiffFormat, "format", "patch", "Output format: patch (RDF Patch) or sparql (SPARQL Update)")
+	rdfDiffCmd.Flags().StringVar(&rdfDiffInputFormat, "input-format", "", "Format of RDF file arguments (defaults to the file extension)")
+	rootCmd.AddCommand(rdfDiffCmd)
+}
+
+var rdfDiffCmd = &cobra.Command{
+	Use:   "rdf-diff <old> <new>",
+	Short: "Print the RDF changes between two vault states",
+	Long: `Compare two RDF projections and print the triples to remove and add as an
+RDF Patch or a SPARQL Update script.
+
+Each side is a vault directory, an RDF file, or a git revision of the
+configured vault. Vaults and revisions are projected with the current
+config.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var write func(io.Writer, export.Diff) error
+		switch rdfDiffFormat {
+		case "patch":
+			write = export.WritePatch
+		case "sparql":
+			write = export.WriteUpdate
+		default:
+			return fmt.Errorf("unknown diff format %q (want patch or sparql)", rdfDiffFormat)
+		}
+
+		cmd.SilenceUsage = true
+		before, err := loadDataset(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		after, err := loadDataset(cmd, args[1])
+		if err != nil {
+			return err
+		}
+		return write(cmd.OutOrStdout(), export.Compare(before, after))
+	},
+}
+
+// loadDataset projects a vault directory, reads an RDF file, or projects
+// a git revision, depending on what arg names.
+func loadDataset(cmd *cobra.Command, arg string) ([]*rdf.Triple, error) {
+	info, err := os.Stat(arg)
+	switch {
+	case err == nil && info.IsDir():
+		return projectDir(cmd, arg), nil
+	case err == nil:
+		return readRDFFile(arg)
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	dir, err := checkoutRevision(cmd.Context(), cfg.VaultPath, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	return projectDir(cmd, dir), nil
+}
+
+func projectDir(cmd *cobra.Command, dir string) []*rdf.Triple {
+	var triples []*rdf.Triple
+	projector().EachVaultTriple(dirNotes(cmd, dir, notes.Filter{}), func(t *rdf.Triple) bool {
+		triples = append(triples, t)
+		return true
+	})
+	return triples
+}
+
+func readRDFFile(path string) ([]*rdf.Triple, error) {
+	name := rdfDiffInputFormat
+	if name == "" {
+		name = filepath.Ext(path)
+	}
+	format, err := export.ParseFormat(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	triples, err := export.Read(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return triples, nil
+}
+
+// checkoutRevision extracts the vault as it was at rev into a temporary
+// directory, which the caller removes.
+func checkoutRevision(ctx context.Context, vault, rev string) (string, error) {
+	out, err := git(ctx, vault, "rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return "", err
+	}
+	top, prefix, _ := strings.Cut(strings.TrimRight(string(out), "\n"), "\n")
+	// archive is relative to the working directory, so run it from the top.
+	archive, err := git(ctx, top, "archive", "--format=tar", rev+":"+prefix)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := os.MkdirTemp("", "weave2-rev-")
+	if err != nil {
+		return "", err
+	}
+	if err := extractTar(bytes.NewReader(archive), dir); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("extract %s: %w", rev, err)
+	}
+	return dir, nil
+}
+
+// git runs a git command in dir and returns its output.
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) {
+			continue
+		}
+
+		path := filepath.Join(dir, hdr.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, tr); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func runRDFDiff(t *testing.T, args ...string) string {
+	t.Helper()
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs(append([]string{"rdf-diff"}, args...))
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return stdout.String()
+}
+
+func TestRDFDiffVaults(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	before, after := t.TempDir(), t.TempDir()
+	if _, err := notes.Create(before, markdown.Note{Title: "Note", Tags: []string{"old"}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(after, markdown.Note{Title: "Note", Tags: []string{"new"}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	patch := runRDFDiff(t, before, after)
+	for _, want := range []string{
+		`D <http://localhost/notes/note-20250101000000> <http://purl.org/dc/terms/subject> <http://localhost/tags/old> .`,
+		`A <http://localhost/notes/note-20250101000000> <http://purl.org/dc/terms/subject> <http://localhost/tags/new> .`,
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %s:\n%s", want, patch)
+		}
+	}
+	if strings.Contains(patch, "dc/terms/title") {
+		t.Errorf("patch includes the unchanged title:\n%s", patch)
+	}
+
+	update := runRDFDiff(t, "--format", "sparql", before, after)
+	if !strings.HasPrefix(update, "DELETE DATA {") || !strings.Contains(update, "INSERT DATA {") {
+		t.Errorf("update =\n%s", update)
+	}
+}
+
+func TestRDFDiffFiles(t *testing.T) {
+	dir := t.TempDir()
+	before := filepath.Join(dir, "before.nt")
+	after := filepath.Join(dir, "after.ttl")
+	if err := os.WriteFile(before, []byte("<http://e/a> <http://e/p> \"1\" .\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(after, []byte("<http://e/a> <http://e/p> \"1\" , \"2\" .\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	want := "TX .\nA <http://e/a> <http://e/p> \"2\" .\nTC .\n"
+	if got := runRDFDiff(t, before, after); got != want {
+		t.Errorf("patch = %q, want %q", got, want)
+	}
+}
+
+func TestRDFDiffGitRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	vault := filepath.Join(repo, "notes")
+	gitRun := func(args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.org"}, args...)...)
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := notes.Create(vault, markdown.Note{Title: "First"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	gitRun("init", "-q")
+	gitRun("add", ".")
+	gitRun("commit", "-q", "-m", "first")
+	if err := notes.Update(vault, id, markdown.Note{ID: id, Title: "Second", Created: ts}, ts); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	patch := runRDFDiff(t, "--vault", vault, "HEAD", vault)
+	for _, want := range []string{
+		`D <http://localhost/notes/first-20250101000000> <http://purl.org/dc/terms/title> "First" .`,
+		`A <http://localhost/notes/first-20250101000000> <http://purl.org/dc/terms/title> "Second" .`,
+	} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("patch missing %s:\n%s", want, patch)
+		}
+	}
+}
@@ -5,23 +5,27 @@ import (
 	"os"
 
 	"github.com/DeDude/weave2/internal/config"
+	"github.com/DeDude/weave2/internal/rdfproj"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfg        config.Config
-	vaultFlag  string
-	editorFlag string
+	cfg         config.Config
+	vaultFlag   string
+	editorFlag  string
+	baseURIFlag string
 )
 
 const (
-	envVault  = "WEAVE_VAULT"
-	envEditor = "WEAVE_EDITOR"
+	envVault   = "WEAVE_VAULT"
+	envEditor  = "WEAVE_EDITOR"
+	envBaseURI = "WEAVE_BASE_URI"
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&vaultFlag, "vault", "", "Path to notes vault (defaults to ./notes)")
 	rootCmd.PersistentFlags().StringVar(&editorFlag, "editor", "", "Editor command to record in config")
+	rootCmd.PersistentFlags().StringVar(&baseURIFlag, "base-uri", "", "Base URI for note and tag IRIs (defaults to http://localhost)")
 }
 
 func Execute() {
@@ -41,6 +45,7 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		loaded.BaseURI = resolveBaseURI()
 		cfg = loaded
 		return nil
 	},
@@ -63,3 +68,15 @@ func resolveInputs() (string, string) {
 	}
 	return vault, editor
 }
+
+// projector maps notes to RDF as configured.
+func projector() *rdfproj.Projector {
+	return rdfproj.New(cfg.BaseURI, cfg.Vocabulary).WithLanguage(cfg.Lang).WithStructure(cfg.Structure)
+}
+
+func resolveBaseURI() string {
+	if baseURIFlag != "" {
+		return baseURIFlag
+	}
+	return os.Getenv(envBaseURI)
+}
@@ -4,11 +4,33 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/DeDude/weave2/internal/notes"
 )
 
 func resetFlags() {
 	vaultFlag = ""
 	editorFlag = ""
+	baseURIFlag = ""
+	strictFlag = false
+	filterFlags = notes.Filter{}
+	sparqlFormat = ""
+	importFormat = ""
+	importForce = false
+	verifyFormat = "turtle"
+	exportFormat = "turtle"
+	exportOutput = ""
+	exportInferred = false
+	exportHash = false
+	exportProfile = ""
+	rdfDiffFormat = "patch"
+	rdfDiffInputFormat = ""
+	validateShapes = ""
+	validateShapesFormat = ""
+	tagsDryRun = false
+	tagsQuery = ""
+	tagsInto = ""
+	linksDryRun = false
 	rootCmd.SetArgs(nil)
 }
 
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	strictFlag  bool
+	filterFlags notes.Filter
+)
+
+func init() {
+	searchCmd.Flags().BoolVar(&strictFlag, "strict", false, "Fail on the first unreadable note instead of skipping it")
+	addFilterFlags(searchCmd, &filterFlags)
+	rootCmd.AddCommand(searchCmd)
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <term>",
+	Short: "Search notes across title, body, tags, and links",
+	Long: `Search notes across title, body, tags, and links.
+
+A word of the form tag:name keeps only notes tagged name or a tag nested
+under it, so tag:lang/go also finds notes tagged lang/go/generics.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if filterFlags.Month != 0 && filterFlags.Year == 0 {
+			return fmt.Errorf("--month requires --year")
+		}
+
+		query := search.Query{
+			Term:   strings.Join(args, " "),
+			Strict: strictFlag,
+			Filter: filterFlags,
+			Lang:   cfg.Lang,
+		}
+
+		results, skipped, err := search.Search(cfg.VaultPath, query)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range skipped {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipped %s: %s\n", s.Path, s.Reason)
+		}
+
+		for _, r := range results {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\t%s\n", r.Score, r.Note.ID, r.Note.Title)
+		}
+
+		return nil
+	},
+}
+
+func addFilterFlags(cmd *cobra.Command, f *notes.Filter) {
+	cmd.Flags().IntVar(&f.Year, "year", 0, "Only include notes from this year directory")
+	cmd.Flags().IntVar(&f.Month, "month", 0, "Only include notes from this month directory (requires --year)")
+	cmd.Flags().StringVar(&f.Type, "type", "", "Only include notes of this type")
+	cmd.Flags().StringVar(&f.Tag, "tag", "", "Only include notes with this tag or one nested under it")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func TestSearchWarnsOnSkippedFiles(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "Test Note"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	badFilePath := filepath.Join(vault, "2025", "01", "bad-file.md")
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout, stderr bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetOut(nil)
+	defer rootCmd.SetErr(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "search", "test"})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if !strings.Contains(stdout.String(), "test-note-20250101000000") {
+		t.Errorf("stdout = %q, want result for test note", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), badFilePath) {
+		t.Errorf("stderr = %q, want warning for %s", stderr.String(), badFilePath)
+	}
+}
+
+func TestSearchStrictFails(t *testing.T) {
+	vault := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vault, "2025", "01"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	badFilePath := filepath.Join(vault, "2025", "01", "bad-file.md")
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resetFlags()
+	var stderr bytes.Buffer
+	rootCmd.SetErr(&stderr)
+	defer rootCmd.SetErr(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "search", "--strict", "test"})
+
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("Execute() error = nil, want error with --strict")
+	}
+}
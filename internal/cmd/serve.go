@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/DeDude/weave2/internal/server"
+	"github.com/DeDude/weave2/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr string
+	serveOpts server.Options
+	servePoll bool
+)
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveOpts.SPARQL, "sparql", false, "Expose a SPARQL 1.1 Protocol endpoint at /sparql")
+	serveCmd.Flags().BoolVar(&serveOpts.API, "api", false, "Expose a read/write JSON API under /api")
+	serveCmd.Flags().BoolVar(&servePoll, "poll", false, "Poll the vault for changes instead of using file system notifications")
+	rootCmd.AddCommand(serveCmd)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Browse the vault in a web UI, refreshing as notes change",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return runServe(ctx, cmd)
+	},
+}
+
+func runServe(ctx context.Context, cmd *cobra.Command) error {
+	stderr := cmd.ErrOrStderr()
+
+	srv, errs := server.New(ctx, cfg.VaultPath, projector(), serveOpts)
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "warning: skipped %v\n", err)
+	}
+
+	ln, err := net.Listen("tcp", serveAddr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	httpSrv := &http.Server{Handler: srv.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		opts := watch.Options{Poll: servePoll, OnError: func(err error) {
+			fmt.Fprintf(stderr, "warning: %v; rescanning\n", err)
+		}}
+		err := srv.Watch(ctx, opts, func(err error) {
+			fmt.Fprintf(stderr, "warning: skipped %v\n", err)
+		})
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: live refresh stopped: %v\n", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpSrv.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(cmd.OutOrStdout(), "serving %s on http://%s\n", cfg.VaultPath, ln.Addr())
+	if serveOpts.SPARQL {
+		fmt.Fprintf(cmd.OutOrStdout(), "SPARQL endpoint: http://%s/sparql\n", ln.Addr())
+	}
+	if serveOpts.API {
+		fmt.Fprintf(cmd.OutOrStdout(), "JSON API: http://%s/api (spec at /api/openapi.yaml)\n", ln.Addr())
+	}
+
+	if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
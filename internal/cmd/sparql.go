@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/sparql"
+	rdf "github.com/deiu/rdf2go"
+	"github.com/spf13/cobra"
+)
+
+var sparqlFormat string
+
+func init() {
+	sparqlCmd.Flags().StringVar(&sparqlFormat, "format", "", "Output format: table, csv, or json for SELECT/ASK; turtle, ntriples, or jsonld for CONSTRUCT")
+	rootCmd.AddCommand(sparqlCmd)
+}
+
+var sparqlCmd = &cobra.Command{
+	Use:   "sparql <query>",
+	Short: "Run a SPARQL query over the vault's RDF projection",
+	Long: `Run a SPARQL SELECT, ASK, or CONSTRUCT query over the vault's RDF projection.
+Pass - as the query to read it from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		src := args[0]
+		if src == "-" {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return fmt.Errorf("read query: %w", err)
+			}
+			src = string(data)
+		}
+
+		query, err := sparql.Parse(src)
+		if err != nil {
+			return err
+		}
+
+		store := loadStore(cmd)
+		res := query.Eval(store)
+		out := cmd.OutOrStdout()
+
+		if res.Form == sparql.Construct {
+			format := export.Turtle
+			if sparqlFormat != "" {
+				if format, err = export.ParseFormat(sparqlFormat); err != nil {
+					return err
+				}
+			}
+			return export.Write(out, res.Triples, format)
+		}
+
+		format := sparql.Table
+		if sparqlFormat != "" {
+			if format, err = sparql.ParseResultFormat(sparqlFormat); err != nil {
+				return err
+			}
+		}
+		return sparql.WriteResults(out, res, format)
+	},
+}
+
+func loadStore(cmd *cobra.Command) *sparql.Store {
+	store := sparql.NewStore()
+	projector().EachVaultTriple(vaultNotes(cmd, notes.Filter{}), func(t *rdf.Triple) bool {
+		store.Add(t)
+		return true
+	})
+	return store
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func TestSparqlSelectCSV(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "Query Me", Tags: []string{"rdf"}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "--base-uri", "http://example.org", "sparql", "--format", "csv",
+		`SELECT ?note WHERE { ?note <http://purl.org/dc/terms/subject> <http://example.org/tags/rdf> }`})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := "note\r\nhttp://example.org/notes/query-me-20250101000000\r\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestSparqlConstructNTriples(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "Query Me"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "sparql", "--format", "ntriples",
+		`CONSTRUCT { ?n <http://example.org/label> ?t } WHERE { ?n <http://purl.org/dc/terms/title> ?t }`})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := `<http://localhost/notes/query-me-20250101000000> <http://example.org/label> "Query Me" .`
+	if strings.TrimSpace(stdout.String()) != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestSparqlUsesConfiguredVocabulary(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	paper, err := notes.Create(vault, markdown.Note{Title: "Paper"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := notes.Create(vault, markdown.Note{Title: "Ada", Type: "Person", Links: []links.Link{{ID: paper, Type: "cites"}}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(vault, ".weave"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	config := "vocabulary:\n  prefixes:\n    cito: http://purl.org/spar/cito/\n    foaf: http://xmlns.com/foaf/0.1/\n  relations:\n    cites: cito:cites\n  types:\n    Person: [foaf:Person]\n"
+	if err := os.WriteFile(filepath.Join(vault, ".weave", "config.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "sparql", "--format", "csv",
+		`SELECT ?title WHERE { ?n a <http://xmlns.com/foaf/0.1/Person> ; <http://purl.org/spar/cito/cites> ?p ; <http://purl.org/dc/terms/title> ?title }`})
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if want := "title\r\nAda\r\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
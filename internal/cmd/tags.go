@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagsDryRun bool
+	tagsQuery  string
+	tagsInto   string
+)
+
+func init() {
+	tagsCmd.PersistentFlags().BoolVar(&tagsDryRun, "dry-run", false, "Show the notes that would change without writing them")
+	tagsMergeCmd.Flags().StringVar(&tagsInto, "into", "", "Tag to merge into")
+	tagsAddCmd.Flags().StringVar(&tagsQuery, "query", "", "Search term selecting the notes to tag")
+	tagsRemoveCmd.Flags().StringVar(&tagsQuery, "query", "", "Search term selecting the notes to untag (default: all notes)")
+	tagsCmd.AddCommand(tagsRenameCmd, tagsMergeCmd, tagsAddCmd, tagsRemoveCmd)
+	rootCmd.AddCommand(tagsCmd)
+}
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with the number of notes using each",
+	Long: `List tags with the number of notes using each, or change tags across the
+vault with the subcommands. Changed notes are listed with their old and new
+tags; --dry-run lists them without writing.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		counts := make(map[string]int)
+		for note := range vaultNotes(cmd, notes.Filter{}) {
+			for _, tag := range note.Tags {
+				counts[tag]++
+			}
+		}
+
+		names := make([]string, 0, len(counts))
+		for name := range counts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d\t%s\n", counts[name], name)
+		}
+		return nil
+	},
+}
+
+var tagsRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag and the tags nested under it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, to := args[0], args[1]
+		if err := checkTags(from, to); err != nil {
+			return err
+		}
+		if from == to {
+			return fmt.Errorf("old and new tag are both %q", from)
+		}
+		return retag(cmd, nil, func(tags []string) []string {
+			return notes.RenameTag(tags, from, to)
+		})
+	},
+}
+
+var tagsMergeCmd = &cobra.Command{
+	Use:   "merge <tag>... --into <tag>",
+	Short: "Replace several tags with one",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tagsInto == "" {
+			return fmt.Errorf("--into is required")
+		}
+		if err := checkTags(append(args, tagsInto)...); err != nil {
+			return err
+		}
+		return retag(cmd, nil, func(tags []string) []string {
+			for _, from := range args {
+				tags = notes.RenameTag(tags, from, tagsInto)
+			}
+			return tags
+		})
+	},
+}
+
+var tagsAddCmd = &cobra.Command{
+	Use:   "add <tag>... --query <term>",
+	Short: "Add tags to the notes matching a search",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if tagsQuery == "" {
+			return fmt.Errorf("--query is required")
+		}
+		if err := checkTags(args...); err != nil {
+			return err
+		}
+		match, err := queryMatcher(cmd)
+		if err != nil {
+			return err
+		}
+		return retag(cmd, match, func(tags []string) []string {
+			return notes.AddTags(tags, args...)
+		})
+	},
+}
+
+var tagsRemoveCmd = &cobra.Command{
+	Use:   "remove <tag>... [--query <term>]",
+	Short: "Remove tags from the notes matching a search, or from every note",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var match func(markdown.Note) bool
+		if tagsQuery != "" {
+			var err error
+			if match, err = queryMatcher(cmd); err != nil {
+				return err
+			}
+		}
+		return retag(cmd, match, func(tags []string) []string {
+			return notes.RemoveTags(tags, args...)
+		})
+	},
+}
+
+func checkTags(tags ...string) error {
+	for _, tag := range tags {
+		if strings.Trim(tag, "/") == "" {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// queryMatcher selects the notes --query finds.
+func queryMatcher(cmd *cobra.Command) (func(markdown.Note) bool, error) {
+	results, skipped, err := search.Search(cfg.VaultPath, search.Query{Term: tagsQuery, Lang: cfg.Lang})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipped %s: %s\n", s.Path, s.Reason)
+	}
+
+	ids := make(map[string]bool, len(results))
+	for _, r := range results {
+		ids[r.Note.ID] = true
+	}
+	return func(note markdown.Note) bool { return ids[note.ID] }, nil
+}
+
+// retag applies change to the tags of every note match accepts, or of
+// every note when match is nil, and rewrites the notes whose tags change.
+func retag(cmd *cobra.Command, match func(markdown.Note) bool, change func([]string) []string) error {
+	stdout := cmd.OutOrStdout()
+	now := time.Now()
+	// Load everything before writing so the walk never sees a half-written vault.
+	entries := slices.Collect(vaultEntries(cmd, notes.Filter{}))
+
+	changed := 0
+	for _, entry := range entries {
+		note := entry.Note
+		if match != nil && !match(note) {
+			continue
+		}
+		before := note.Tags
+		note.Tags = change(slices.Clone(before))
+		if slices.Equal(note.Tags, before) {
+			continue
+		}
+
+		rel := vaultPath(entry.Path)
+		if !tagsDryRun {
+			if err := notes.Update(cfg.VaultPath, note.ID, note, now); err != nil {
+				return fmt.Errorf("update %s: %w", rel, err)
+			}
+		}
+		fmt.Fprintf(stdout, "%s\t%s -> %s\n", rel, strings.Join(before, ", "), strings.Join(note.Tags, ", "))
+		changed++
+	}
+
+	reportChanged(cmd, changed, tagsDryRun)
+	return nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func runTags(t *testing.T, args ...string) string {
+	t.Helper()
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("Execute(%v) error = %v", args, err)
+	}
+	return stdout.String()
+}
+
+func tagsVault(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ids := make(map[string]string)
+	for _, note := range []markdown.Note{
+		{Title: "Generics", Body: "type parameters", Tags: []string{"lang/go/generics", "todo"}},
+		{Title: "Channels", Body: "goroutines", Tags: []string{"lang/go", "golang"}},
+		{Title: "Traits", Body: "type classes", Tags: []string{"lang/rust"}},
+	} {
+		id, err := notes.Create(vault, note, ts)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[note.Title] = id
+	}
+	return vault, ids
+}
+
+func noteTags(t *testing.T, vault, id string) []string {
+	t.Helper()
+	note, err := notes.Read(vault, id)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return note.Tags
+}
+
+func TestTagsList(t *testing.T) {
+	vault, _ := tagsVault(t)
+	got := runTags(t, "--vault", vault, "tags")
+	want := "1\tgolang\n1\tlang/go\n1\tlang/go/generics\n1\tlang/rust\n1\ttodo\n"
+	if got != want {
+		t.Errorf("tags =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTagsRenameDryRun(t *testing.T) {
+	vault, ids := tagsVault(t)
+	out := runTags(t, "--vault", vault, "tags", "rename", "lang/go", "go", "--dry-run")
+	if !strings.Contains(out, "lang/go/generics, todo -> go/generics, todo") || !strings.HasSuffix(out, "2 notes would change\n") {
+		t.Errorf("dry run output:\n%s", out)
+	}
+	if got := noteTags(t, vault, ids["Generics"]); !slices.Equal(got, []string{"lang/go/generics", "todo"}) {
+		t.Errorf("dry run changed tags to %v", got)
+	}
+
+	out = runTags(t, "--vault", vault, "tags", "rename", "lang/go", "go")
+	if !strings.HasSuffix(out, "2 notes changed\n") {
+		t.Errorf("rename output:\n%s", out)
+	}
+	if got := noteTags(t, vault, ids["Generics"]); !slices.Equal(got, []string{"go/generics", "todo"}) {
+		t.Errorf("Generics tags = %v", got)
+	}
+}
+
+func TestTagsMerge(t *testing.T) {
+	vault, ids := tagsVault(t)
+	runTags(t, "--vault", vault, "tags", "merge", "golang", "lang/go", "--into", "go")
+	if got := noteTags(t, vault, ids["Channels"]); !slices.Equal(got, []string{"go"}) {
+		t.Errorf("Channels tags = %v, want [go]", got)
+	}
+	if got := noteTags(t, vault, ids["Traits"]); !slices.Equal(got, []string{"lang/rust"}) {
+		t.Errorf("Traits tags = %v, want unchanged", got)
+	}
+}
+
+func TestTagsAddAndRemove(t *testing.T) {
+	vault, ids := tagsVault(t)
+	out := runTags(t, "--vault", vault, "tags", "add", "types", "--query", "type")
+	if !strings.HasSuffix(out, "2 notes changed\n") {
+		t.Errorf("add output:\n%s", out)
+	}
+	if got := noteTags(t, vault, ids["Traits"]); !slices.Equal(got, []string{"lang/rust", "types"}) {
+		t.Errorf("Traits tags = %v", got)
+	}
+	if got := noteTags(t, vault, ids["Channels"]); slices.Contains(got, "types") {
+		t.Errorf("Channels tags = %v, want no types", got)
+	}
+
+	runTags(t, "--vault", vault, "tags", "remove", "types", "--query", "tag:lang/rust")
+	if got := noteTags(t, vault, ids["Traits"]); !slices.Equal(got, []string{"lang/rust"}) {
+		t.Errorf("Traits tags after remove = %v", got)
+	}
+	if got := noteTags(t, vault, ids["Generics"]); !slices.Contains(got, "types") {
+		t.Errorf("Generics tags after remove = %v, want types kept", got)
+	}
+
+	runTags(t, "--vault", vault, "tags", "remove", "types", "todo")
+	if got := noteTags(t, vault, ids["Generics"]); !slices.Equal(got, []string{"lang/go/generics"}) {
+		t.Errorf("Generics tags after removing everywhere = %v", got)
+	}
+}
+
+func TestTagsAddRequiresQuery(t *testing.T) {
+	vault, _ := tagsVault(t)
+	resetFlags()
+	rootCmd.SetArgs([]string{"--vault", vault, "tags", "add", "x"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "--query is required") {
+		t.Errorf("Execute() error = %v, want --query is required", err)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/shacl"
+	rdf "github.com/deiu/rdf2go"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateShapes       string
+	validateShapesFormat string
+)
+
+func init() {
+	validateCmd.Flags().StringVar(&validateShapes, "shapes", "", "SHACL shapes file")
+	validateCmd.Flags().StringVar(&validateShapesFormat, "shapes-format", "", "Format of the shapes file (defaults to the file extension)")
+	validateCmd.MarkFlagRequired("shapes")
+	rootCmd.AddCommand(validateCmd)
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the vault's RDF projection against SHACL shapes",
+	Long: `Validate the vault's RDF projection against a SHACL shapes graph and report
+each failure with the note and file it concerns.
+
+Supported: sh:targetClass, sh:targetNode, sh:property with IRI paths, and
+the minCount, maxCount, datatype, class, pattern, and in constraints, plus
+sh:severity and sh:message. Shapes using anything else are rejected.
+Patterns are Go regular expressions. Only violations make the command
+fail; warnings and info results are printed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := validateShapesFormat
+		if name == "" {
+			name = filepath.Ext(validateShapes)
+		}
+		format, err := export.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(validateShapes)
+		if err != nil {
+			return err
+		}
+		shapeTriples, err := export.Read(f, format)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", validateShapes, err)
+		}
+		shapes, err := shacl.Parse(shapeTriples)
+		if err != nil {
+			return fmt.Errorf("%s: %w", validateShapes, err)
+		}
+
+		// Each note owns its own subject and the blank nodes it mints, so
+		// results can point back at a file.
+		type owner struct{ id, path string }
+		owners := make(map[string]owner)
+		proj := projector()
+		var vault []markdown.Note
+		for entry := range vaultEntries(cmd, notes.Filter{}) {
+			vault = append(vault, entry.Note)
+			rel, err := filepath.Rel(cfg.VaultPath, entry.Path)
+			if err != nil {
+				rel = entry.Path
+			}
+			noteURI := proj.NoteURI(entry.Note.ID)
+			for _, t := range proj.NoteToTriples(entry.Note) {
+				if _, blank := t.Subject.(*rdf.BlankNode); blank || t.Subject.RawValue() == noteURI {
+					owners[t.Subject.String()] = owner{entry.Note.ID, filepath.ToSlash(rel)}
+				}
+			}
+		}
+		data := proj.VaultToTriples(vault)
+
+		cmd.SilenceUsage = true
+		out := cmd.OutOrStdout()
+		violations := 0
+		for _, r := range shacl.Validate(data, shapes) {
+			where := r.Focus.String()
+			if o, ok := owners[where]; ok {
+				where = fmt.Sprintf("%s (%s)", o.path, o.id)
+			}
+			if r.Path != "" {
+				where += " <" + r.Path + ">"
+			}
+			fmt.Fprintf(out, "%s: %s: %s\n", r.Severity, where, r.Message)
+			if r.Severity == "Violation" {
+				violations++
+			}
+		}
+		if violations > 0 {
+			return fmt.Errorf("%d SHACL violations", violations)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+const decisionShapes = `@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix dcterms: <http://purl.org/dc/terms/> .
+@prefix weave: <http://weave.dev/vocab#> .
+
+<urn:shapes:Decision> sh:targetClass weave:Decision ;
+  sh:property [ sh:path weave:status ; sh:minCount 1 ; sh:message "every Decision needs a status" ] .
+
+<urn:shapes:Note> sh:targetClass weave:Note ;
+  sh:property [ sh:path dcterms:title ; sh:pattern "^[A-Z]" ; sh:severity sh:Warning ] .
+`
+
+func runValidate(t *testing.T, vault string) (string, error) {
+	t.Helper()
+	shapes := filepath.Join(t.TempDir(), "shapes.ttl")
+	if err := os.WriteFile(shapes, []byte(decisionShapes), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	resetFlags()
+	var stdout bytes.Buffer
+	rootCmd.SetOut(&stdout)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"--vault", vault, "validate", "--shapes", shapes})
+	err := rootCmd.Execute()
+	return stdout.String(), err
+}
+
+func TestValidateReportsNotes(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "Use Go", Type: "Decision"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	out, err := runValidate(t, vault)
+	if err == nil || !strings.Contains(err.Error(), "1 SHACL violations") {
+		t.Errorf("Execute() error = %v, want 1 violation", err)
+	}
+	want := "Violation: 2025/01/use-go-20250101000000.md (use-go-20250101000000) <http://weave.dev/vocab#status>: every Decision needs a status\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestValidateWarningsPass(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vault, markdown.Note{Title: "lowercase"}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	out, err := runValidate(t, vault)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.HasPrefix(out, "Warning: 2025/01/lowercase-20250101000000.md") {
+		t.Errorf("output = %q, want a warning", out)
+	}
+}
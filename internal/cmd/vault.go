@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"iter"
+	"path/filepath"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/spf13/cobra"
+)
+
+// vaultNotes streams the configured vault, warning on stderr about every
+// file that cannot be loaded.
+func vaultNotes(cmd *cobra.Command, filter notes.Filter) iter.Seq[markdown.Note] {
+	return dirNotes(cmd, cfg.VaultPath, filter)
+}
+
+// vaultEntries is vaultNotes with each note's file path.
+func vaultEntries(cmd *cobra.Command, filter notes.Filter) iter.Seq[notes.Entry] {
+	return dirEntries(cmd, cfg.VaultPath, filter)
+}
+
+// dirNotes streams the vault at dir, warning like vaultNotes.
+func dirNotes(cmd *cobra.Command, dir string, filter notes.Filter) iter.Seq[markdown.Note] {
+	return func(yield func(markdown.Note) bool) {
+		for entry := range dirEntries(cmd, dir, filter) {
+			if !yield(entry.Note) {
+				return
+			}
+		}
+	}
+}
+
+func dirEntries(cmd *cobra.Command, dir string, filter notes.Filter) iter.Seq[notes.Entry] {
+	return func(yield func(notes.Entry) bool) {
+		for entry, err := range notes.All(cmd.Context(), dir, filter) {
+			if err != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: skipped %v\n", err)
+				continue
+			}
+			if !yield(entry) {
+				return
+			}
+		}
+	}
+}
+
+// vaultPath returns path relative to the vault, with forward slashes.
+func vaultPath(path string) string {
+	rel, err := filepath.Rel(cfg.VaultPath, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// reportChanged ends a command that rewrites notes with how many changed.
+func reportChanged(cmd *cobra.Command, changed int, dryRun bool) {
+	if dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "%d notes would change\n", changed)
+		return
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "%d notes changed\n", changed)
+}
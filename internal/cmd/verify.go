@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"github.com/spf13/cobra"
+)
+
+var verifyFormat string
+
+func init() {
+	verifyRDFCmd.Flags().StringVar(&verifyFormat, "format", "turtle", "Serialization to round-trip through: turtle, ntriples, or jsonld")
+	rootCmd.AddCommand(verifyRDFCmd)
+}
+
+var verifyRDFCmd = &cobra.Command{
+	Use:   "verify-rdf",
+	Short: "Check that the vault survives a round trip through RDF",
+	Long: `Project the vault to RDF, serialize and parse it again, convert the triples
+back into notes, and report every field that differs from the original.
+Frontmatter keys weave does not know are not projected, so notes that have
+them are reported under "extra".`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := export.ParseFormat(verifyFormat)
+		if err != nil {
+			return err
+		}
+
+		proj := projector()
+		original := slices.Collect(vaultNotes(cmd, notes.Filter{}))
+
+		var buf bytes.Buffer
+		if err := export.Write(&buf, proj.VaultToTriples(original), format); err != nil {
+			return err
+		}
+		triples, err := export.Read(&buf, format)
+		if err != nil {
+			return err
+		}
+		recovered, errs := proj.TriplesToNotes(triples)
+		for _, err := range errs {
+			fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+		}
+
+		cmd.SilenceUsage = true
+		losses := rdfproj.Compare(resolvedLinks(original), recovered)
+		out := cmd.OutOrStdout()
+		for _, loss := range losses {
+			fmt.Fprintln(out, loss)
+		}
+		if len(losses) > 0 {
+			return fmt.Errorf("%d fields lost in the %s round trip", len(losses), format)
+		}
+
+		fmt.Fprintf(out, "verified %d notes: no information lost\n", len(original))
+		return nil
+	},
+}
+
+// resolvedLinks returns vault with its links naming their notes by ID, as
+// the projection writes them. Links that resolve to no note are kept.
+func resolvedLinks(vault []markdown.Note) []markdown.Note {
+	targets := make([]links.Target, len(vault))
+	for i, note := range vault {
+		targets[i] = links.Target{ID: note.ID, Title: note.Title, Aliases: note.Aliases}
+	}
+	resolver := links.NewResolver(targets)
+
+	out := make([]markdown.Note, len(vault))
+	for i, note := range vault {
+		note.Links = slices.Clone(note.Links)
+		for j, link := range note.Links {
+			if resolved, err := resolver.ResolveLink(link); err == nil {
+				note.Links[j] = resolved
+			}
+		}
+		out[i] = note
+	}
+	return out
+}
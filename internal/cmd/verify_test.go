@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func TestVerifyRDFRoundTrip(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	parent, err := notes.Create(vault, markdown.Note{Title: "Parent", Type: "Concept", Tags: []string{"rdf", "two words"}}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, err = notes.Create(vault, markdown.Note{
+		Title: `Child "quoted" — ünïcode`,
+		Body:  "Line one\nLine \"two\"\ttabbed\\ and [[" + parent + "|a label]]\n",
+		Tags:  []string{"rdf"},
+		Links: []links.Link{
+			{ID: parent, Type: "broader", Label: "up \"there\""},
+			{ID: parent, Type: "linksTo"},
+			{ID: "parent", Type: "related"},
+		},
+	}, ts.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for _, format := range []string{"turtle", "ntriples", "jsonld"} {
+		resetFlags()
+		var stdout bytes.Buffer
+		rootCmd.SetOut(&stdout)
+		rootCmd.SetArgs([]string{"--vault", vault, "verify-rdf", "--format", format})
+
+		if err := rootCmd.Execute(); err != nil {
+			t.Errorf("%s: Execute() error = %v\n%s", format, err, stdout.String())
+			continue
+		}
+		if !strings.Contains(stdout.String(), "verified 2 notes") {
+			t.Errorf("%s: stdout = %q", format, stdout.String())
+		}
+	}
+	rootCmd.SetOut(nil)
+}
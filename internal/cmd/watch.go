@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/DeDude/weave2/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var watchOpts watch.Options
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchOpts.Poll, "poll", false, "Poll the vault instead of using file system notifications")
+	watchCmd.Flags().DurationVar(&watchOpts.PollInterval, "interval", time.Second, "Polling interval")
+	watchCmd.Flags().DurationVar(&watchOpts.Debounce, "debounce", 200*time.Millisecond, "Quiet period before reporting a batch of changes")
+	rootCmd.AddCommand(watchCmd)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the vault and keep the search index and graph up to date",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+
+		return runWatch(ctx, cmd)
+	},
+}
+
+func runWatch(ctx context.Context, cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+	stderr := cmd.ErrOrStderr()
+
+	opts := watchOpts
+	opts.OnError = func(err error) {
+		fmt.Fprintf(stderr, "warning: %v; rescanning\n", err)
+	}
+	w, err := watch.New(cfg.VaultPath, opts)
+	if err != nil {
+		return err
+	}
+
+	ix, errs := watch.NewIndexer(ctx, cfg.VaultPath, projector())
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	fmt.Fprintf(out, "indexed %d notes\n", ix.Search.Len())
+
+	return w.Run(ctx, func(events []watch.Event) {
+		for _, ev := range events {
+			if ev.Op == watch.Renamed {
+				fmt.Fprintf(out, "%s %s -> %s\n", ev.Op, ev.OldPath, ev.Path)
+				continue
+			}
+			fmt.Fprintf(out, "%s %s\n", ev.Op, ev.Path)
+		}
+		for _, err := range ix.Apply(events) {
+			fmt.Fprintf(stderr, "warning: %v\n", err)
+		}
+		fmt.Fprintf(out, "indexed %d notes\n", ix.Search.Len())
+	})
+}
@@ -1,21 +1,50 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"gopkg.in/yaml.v3"
 )
 
+// FileName is the vault-relative path of the optional config file.
+const FileName = ".weave/config.yaml"
+
 type Config struct {
 	VaultPath string
 	Editor    string
+	// BaseURI prefixes the IRIs minted for notes and tags. Empty means the
+	// projection default.
+	BaseURI string
+	// Vocabulary maps relationship and note types to RDF terms.
+	Vocabulary *rdfproj.Vocabulary
+	// Lang is the language of notes that do not set their own.
+	Lang string
+	// Structure projects headings, links and images in note bodies.
+	Structure bool
+	// Profiles are the named projection profiles export can select.
+	Profiles map[string]*rdfproj.Profile
+}
+
+// file is the layout of the config file.
+type file struct {
+	Lang       string                         `yaml:"lang"`
+	Structure  bool                           `yaml:"structure"`
+	Vocabulary rdfproj.VocabularySpec         `yaml:"vocabulary"`
+	Profiles   map[string]rdfproj.ProfileSpec `yaml:"profiles"`
 }
 
 func Default() Config {
 	return Config{
-		VaultPath: "notes",
-		Editor:    "",
+		VaultPath:  "notes",
+		Editor:     "",
+		Vocabulary: rdfproj.DefaultVocabulary(),
 	}
 }
 
@@ -35,9 +64,55 @@ func Load(vaultPath, editor string) (Config, error) {
 	}
 	cfg.VaultPath = absVault
 
+	if err := loadFile(&cfg); err != nil {
+		return Config{}, err
+	}
+
 	return cfg, nil
 }
 
+// loadFile applies the vault's config file, if it has one. Unknown keys
+// are rejected so typos do not silently fall back to defaults.
+func loadFile(cfg *Config) error {
+	path := filepath.Join(cfg.VaultPath, FileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var f file
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if f.Lang != "" && !markdown.ValidLang(f.Lang) {
+		return fmt.Errorf("%s: invalid lang %q", path, f.Lang)
+	}
+	cfg.Lang = f.Lang
+	cfg.Structure = f.Structure
+
+	vocab, err := rdfproj.NewVocabulary(f.Vocabulary)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.Vocabulary = vocab
+
+	cfg.Profiles = make(map[string]*rdfproj.Profile, len(f.Profiles))
+	for name, spec := range f.Profiles {
+		profile, err := rdfproj.NewProfile(spec, vocab)
+		if err != nil {
+			return fmt.Errorf("%s: profile %q: %w", path, name, err)
+		}
+		cfg.Profiles[name] = profile
+	}
+	return nil
+}
+
 func validateVaultPath(p string) (string, error) {
 	abs, err := filepath.Abs(p)
 	if err != nil {
@@ -79,6 +79,84 @@ func TestLoadErrorsWhenParentMissing(t *testing.T) {
 	}
 }
 
+func writeConfigFile(t *testing.T, vault, content string) {
+	t.Helper()
+	path := filepath.Join(vault, FileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestLoadVocabularyFromFile(t *testing.T) {
+	vault := t.TempDir()
+	writeConfigFile(t, vault, `lang: de
+structure: true
+profiles:
+  public:
+    body: excerpt
+    exclude:
+      flags: [private]
+vocabulary:
+  prefixes:
+    cito: http://purl.org/spar/cito/
+    foaf: http://xmlns.com/foaf/0.1/
+  relations:
+    cites: cito:cites
+  types:
+    Person: [foaf:Person]
+`)
+
+	cfg, err := Load(vault, "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Lang != "de" {
+		t.Errorf("Lang = %q, want de", cfg.Lang)
+	}
+	if !cfg.Structure {
+		t.Error("Structure = false, want true")
+	}
+	if cfg.Profiles["public"] == nil {
+		t.Errorf("Profiles = %v, want public", cfg.Profiles)
+	}
+	if got := cfg.Vocabulary.Predicate("cites"); got != "http://purl.org/spar/cito/cites" {
+		t.Errorf("Predicate(cites) = %q", got)
+	}
+	if got := cfg.Vocabulary.Classes("Person"); len(got) != 1 || got[0] != "http://xmlns.com/foaf/0.1/Person" {
+		t.Errorf("Classes(Person) = %v", got)
+	}
+}
+
+func TestLoadWithoutFileUsesDefaultVocabulary(t *testing.T) {
+	cfg, err := Load(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.Vocabulary.Predicate("broader"); got != "http://www.w3.org/2004/02/skos/core#broader" {
+		t.Errorf("Predicate(broader) = %q", got)
+	}
+}
+
+func TestLoadRejectsInvalidFile(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":    "vocabulary:\n  relation:\n    cites: x\n",
+		"bad vocabulary": "vocabulary:\n  relations:\n    cites: cito:cites\n",
+		"malformed yaml": "vocabulary: [\n",
+		"bad lang":       "lang: english please\n",
+		"bad profile":    "profiles:\n  slim:\n    body: tiny\n",
+	}
+	for name, content := range tests {
+		vault := t.TempDir()
+		writeConfigFile(t, vault, content)
+		if _, err := Load(vault, ""); err == nil {
+			t.Errorf("%s: Load() error = nil, want error", name)
+		}
+	}
+}
+
 func chdir(t *testing.T, dir string) {
 	t.Helper()
 	orig, err := os.Getwd()
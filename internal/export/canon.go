@@ -0,0 +1,371 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+const xsdString = "http://www.w3.org/2001/XMLSchema#string"
+
+// Hash returns the hex SHA-256 of the canonical N-Quads form of triples.
+// It depends only on the graph, not on triple order or blank node labels.
+func Hash(triples []*rdf.Triple) string {
+	sum := sha256.New()
+	for _, line := range canonicalLines(canonicalize(defaultGraph(triples))) {
+		sum.Write([]byte(line + "\n"))
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+func defaultGraph(triples []*rdf.Triple) []Quad {
+	quads := make([]Quad, len(triples))
+	for i, t := range triples {
+		quads[i] = Quad{Triple: t}
+	}
+	return quads
+}
+
+func canonicalLines(quads []Quad) []string {
+	lines := make([]string, len(quads))
+	for i, q := range quads {
+		lines[i] = quadLine(q, nil)
+	}
+	return lines
+}
+
+// canonicalize drops duplicate quads, relabels blank nodes following RDF
+// Dataset Canonicalization (RDFC-1.0), and sorts the result by its
+// canonical N-Quads form.
+func canonicalize(quads []Quad) []Quad {
+	c := &canonicalizer{
+		bnodeQuads: make(map[string][]Quad),
+		canonical:  newIssuer("c14n"),
+	}
+
+	seen := make(map[string]bool)
+	for _, q := range quads {
+		line := quadLine(q, nil)
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		c.quads = append(c.quads, q)
+		for _, id := range blankNodes(q) {
+			c.bnodeQuads[id] = append(c.bnodeQuads[id], q)
+		}
+	}
+
+	byHash := make(map[string][]string)
+	for id := range c.bnodeQuads {
+		h := c.hashFirstDegree(id)
+		byHash[h] = append(byHash[h], id)
+	}
+	hashes := make([]string, 0, len(byHash))
+	for h := range byHash {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+
+	var shared []string
+	for _, h := range hashes {
+		if len(byHash[h]) == 1 {
+			c.canonical.issue(byHash[h][0])
+		} else {
+			shared = append(shared, h)
+		}
+	}
+
+	for _, h := range shared {
+		var results []nDegreeResult
+		for _, id := range byHash[h] {
+			if _, ok := c.canonical.issued[id]; ok {
+				continue
+			}
+			temp := newIssuer("b")
+			temp.issue(id)
+			results = append(results, c.hashNDegree(id, temp))
+		}
+		sort.SliceStable(results, func(i, j int) bool { return results[i].hash < results[j].hash })
+		for _, r := range results {
+			for _, id := range r.issuer.order {
+				c.canonical.issue(id)
+			}
+		}
+	}
+
+	out := make([]Quad, len(c.quads))
+	for i, q := range c.quads {
+		out[i] = Quad{
+			Triple: rdf.NewTriple(c.relabel(q.Subject), q.Predicate, c.relabel(q.Object)),
+			Graph:  q.Graph,
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return quadLine(out[i], nil) < quadLine(out[j], nil) })
+	return out
+}
+
+type canonicalizer struct {
+	quads      []Quad
+	bnodeQuads map[string][]Quad
+	canonical  *issuer
+}
+
+func (c *canonicalizer) relabel(term rdf.Term) rdf.Term {
+	if b, ok := term.(*rdf.BlankNode); ok {
+		return rdf.NewBlankNode(c.canonical.issued[b.ID])
+	}
+	return term
+}
+
+// hashFirstDegree hashes the quads mentioning a blank node, with that node
+// written as _:a and every other blank node as _:z.
+func (c *canonicalizer) hashFirstDegree(id string) string {
+	var lines []string
+	for _, q := range c.bnodeQuads[id] {
+		lines = append(lines, quadLine(q, func(other string) string {
+			if other == id {
+				return "a"
+			}
+			return "z"
+		})+"\n")
+	}
+	sort.Strings(lines)
+	return hashString(strings.Join(lines, ""))
+}
+
+func (c *canonicalizer) hashRelated(related string, q Quad, iss *issuer, position string) string {
+	var b strings.Builder
+	b.WriteString(position)
+	if position != "g" {
+		b.WriteString(iriString(q.Predicate.RawValue()))
+	}
+	if id, ok := c.canonical.issued[related]; ok {
+		b.WriteString("_:" + id)
+	} else if id, ok := iss.issued[related]; ok {
+		b.WriteString("_:" + id)
+	} else {
+		b.WriteString(c.hashFirstDegree(related))
+	}
+	return hashString(b.String())
+}
+
+type nDegreeResult struct {
+	hash   string
+	issuer *issuer
+}
+
+// hashNDegree distinguishes blank nodes whose first-degree hashes collide
+// by exploring the paths to their neighbouring blank nodes.
+func (c *canonicalizer) hashNDegree(id string, iss *issuer) nDegreeResult {
+	related := make(map[string][]string)
+	for _, q := range c.bnodeQuads[id] {
+		for _, pos := range []struct {
+			name string
+			term rdf.Term
+		}{{"s", q.Subject}, {"o", q.Object}} {
+			b, ok := pos.term.(*rdf.BlankNode)
+			if !ok || b.ID == id {
+				continue
+			}
+			h := c.hashRelated(b.ID, q, iss, pos.name)
+			related[h] = append(related[h], b.ID)
+		}
+	}
+	hashes := make([]string, 0, len(related))
+	for h := range related {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+
+	var data strings.Builder
+	for _, h := range hashes {
+		data.WriteString(h)
+		var chosenPath string
+		var chosenIssuer *issuer
+
+		for _, perm := range permutations(related[h]) {
+			issCopy := iss.clone()
+			var path strings.Builder
+			var recursion []string
+			worse := func() bool {
+				return chosenPath != "" && path.Len() >= len(chosenPath) && path.String() > chosenPath
+			}
+
+			skip := false
+			for _, r := range perm {
+				if cid, ok := c.canonical.issued[r]; ok {
+					path.WriteString("_:" + cid)
+				} else {
+					if _, ok := issCopy.issued[r]; !ok {
+						recursion = append(recursion, r)
+					}
+					path.WriteString("_:" + issCopy.issue(r))
+				}
+				if worse() {
+					skip = true
+					break
+				}
+			}
+			if skip {
+				continue
+			}
+
+			for _, r := range recursion {
+				result := c.hashNDegree(r, issCopy)
+				path.WriteString("_:" + issCopy.issue(r))
+				path.WriteString("<" + result.hash + ">")
+				issCopy = result.issuer
+				if worse() {
+					skip = true
+					break
+				}
+			}
+			if skip {
+				continue
+			}
+
+			if chosenPath == "" || path.String() < chosenPath {
+				chosenPath = path.String()
+				chosenIssuer = issCopy
+			}
+		}
+
+		data.WriteString(chosenPath)
+		iss = chosenIssuer
+	}
+	return nDegreeResult{hash: hashString(data.String()), issuer: iss}
+}
+
+// issuer hands out sequential blank node labels, remembering the order in
+// which the original labels were seen.
+type issuer struct {
+	prefix string
+	issued map[string]string
+	order  []string
+}
+
+func newIssuer(prefix string) *issuer {
+	return &issuer{prefix: prefix, issued: make(map[string]string)}
+}
+
+func (i *issuer) issue(id string) string {
+	if label, ok := i.issued[id]; ok {
+		return label
+	}
+	label := fmt.Sprintf("%s%d", i.prefix, len(i.order))
+	i.issued[id] = label
+	i.order = append(i.order, id)
+	return label
+}
+
+func (i *issuer) clone() *issuer {
+	c := &issuer{prefix: i.prefix, issued: make(map[string]string, len(i.issued)), order: append([]string(nil), i.order...)}
+	for k, v := range i.issued {
+		c.issued[k] = v
+	}
+	return c
+}
+
+func permutations(ids []string) [][]string {
+	if len(ids) <= 1 {
+		return [][]string{append([]string(nil), ids...)}
+	}
+	var out [][]string
+	for i := range ids {
+		rest := make([]string, 0, len(ids)-1)
+		rest = append(rest, ids[:i]...)
+		rest = append(rest, ids[i+1:]...)
+		for _, p := range permutations(rest) {
+			out = append(out, append([]string{ids[i]}, p...))
+		}
+	}
+	return out
+}
+
+func blankNodes(q Quad) []string {
+	var ids []string
+	for _, term := range []rdf.Term{q.Subject, q.Object} {
+		if b, ok := term.(*rdf.BlankNode); ok {
+			ids = append(ids, b.ID)
+		}
+	}
+	return ids
+}
+
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// quadLine writes a quad in canonical N-Quads form, without the newline.
+// label, when set, renames blank nodes.
+func quadLine(q Quad, label func(string) string) string {
+	line := termString(q.Subject, label) + " " + termString(q.Predicate, label) + " " + termString(q.Object, label)
+	if q.Graph != "" {
+		line += " " + iriString(q.Graph)
+	}
+	return line + " ."
+}
+
+func termString(term rdf.Term, label func(string) string) string {
+	switch t := term.(type) {
+	case *rdf.Resource:
+		return iriString(t.URI)
+	case *rdf.BlankNode:
+		if label != nil {
+			return "_:" + label(t.ID)
+		}
+		return "_:" + t.ID
+	case *rdf.Literal:
+		s := `"` + escapeLiteral(t.Value) + `"`
+		if t.Language != "" {
+			return s + "@" + t.Language
+		}
+		if t.Datatype != nil && t.Datatype.RawValue() != xsdString {
+			return s + "^^" + iriString(t.Datatype.RawValue())
+		}
+		return s
+	}
+	return term.String()
+}
+
+func iriString(iri string) string {
+	return "<" + iri + ">"
+}
+
+// escapeLiteral escapes a literal the way canonical N-Triples does: named
+// escapes where they exist, \u for other control characters, and nothing
+// else.
+func escapeLiteral(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
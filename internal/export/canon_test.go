@@ -0,0 +1,101 @@
+package export
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+func linkedBlankNodes(a, b, c string) []*rdf.Triple {
+	p := rdf.NewResource("http://example.org/p")
+	return []*rdf.Triple{
+		rdf.NewTriple(rdf.NewBlankNode(a), p, rdf.NewBlankNode(b)),
+		rdf.NewTriple(rdf.NewBlankNode(b), p, rdf.NewBlankNode(c)),
+		rdf.NewTriple(rdf.NewBlankNode(c), p, rdf.NewBlankNode(a)),
+		rdf.NewTriple(rdf.NewBlankNode(a), rdf.NewResource("http://example.org/name"), rdf.NewLiteral("start")),
+	}
+}
+
+func TestHashIgnoresOrderAndLabels(t *testing.T) {
+	base := linkedBlankNodes("x", "y", "z")
+	want := Hash(base)
+
+	relabelled := linkedBlankNodes("n3", "n1", "n2")
+	reversed := slices.Clone(base)
+	slices.Reverse(reversed)
+	duplicated := append(slices.Clone(base), base[0])
+
+	for name, triples := range map[string][]*rdf.Triple{"relabelled": relabelled, "reversed": reversed, "duplicated": duplicated} {
+		if got := Hash(triples); got != want {
+			t.Errorf("%s: Hash() = %s, want %s", name, got, want)
+		}
+	}
+
+	changed := slices.Clone(base)
+	changed[3] = rdf.NewTriple(rdf.NewBlankNode("x"), rdf.NewResource("http://example.org/name"), rdf.NewLiteral("end"))
+	if Hash(changed) == want {
+		t.Error("Hash() unchanged after editing a literal")
+	}
+}
+
+func TestCanonicalizeSymmetricBlankNodes(t *testing.T) {
+	// Every node has the same first-degree hash, so labels come from the
+	// n-degree step.
+	p := rdf.NewResource("http://example.org/p")
+	ring := func(ids ...string) []Quad {
+		var quads []Quad
+		for i, id := range ids {
+			next := ids[(i+1)%len(ids)]
+			quads = append(quads, Quad{Triple: rdf.NewTriple(rdf.NewBlankNode(id), p, rdf.NewBlankNode(next))})
+		}
+		return quads
+	}
+
+	want := canonicalLines(canonicalize(ring("a", "b", "c", "d")))
+	if got := canonicalLines(canonicalize(ring("q", "m", "z", "e"))); !slices.Equal(got, want) {
+		t.Errorf("canonicalize() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, line := range want {
+		if !strings.HasPrefix(line, "_:c14n") {
+			t.Errorf("line %q not relabelled", line)
+		}
+	}
+}
+
+func TestWriteTurtleCanonical(t *testing.T) {
+	triples := append(sampleTriples(), rdf.NewTriple(
+		rdf.NewResource("http://example.org/notes/a"),
+		rdf.NewResource("http://weave.dev/vocab#linksTo"),
+		rdf.NewResource("http://example.org/notes/c"),
+	))
+	slices.Reverse(triples)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, triples, Turtle); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `<http://example.org/notes/a>
+  <http://purl.org/dc/terms/title> "A" ;
+  <http://weave.dev/vocab#linksTo> <http://example.org/notes/b> ,
+    <http://example.org/notes/c> .
+`
+	if buf.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestEscapeLiteral(t *testing.T) {
+	got := termString(rdf.NewLiteral("a\tb\"c\\d\x01é"), nil)
+	want := `"a\tb\"c\\d\u0001é"`
+	if got != want {
+		t.Errorf("termString() = %s, want %s", got, want)
+	}
+
+	typed := rdf.NewLiteralWithDatatype("x", rdf.NewResource(xsdString))
+	if got := termString(typed, nil); got != `"x"` {
+		t.Errorf("termString(xsd:string) = %s, want %q", got, `"x"`)
+	}
+}
@@ -0,0 +1,235 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+// Diff is the change between two graphs. Triples with blank nodes are
+// compared as whole blank node structures, since their labels mean nothing
+// across graphs; a changed link label removes one structure and adds
+// another. Removed and added blank nodes are labelled r0, r1, ... and
+// a0, a1, ... so the two sides never share a label.
+type Diff struct {
+	Removed []*rdf.Triple
+	Added   []*rdf.Triple
+}
+
+func (d Diff) Empty() bool {
+	return len(d.Removed) == 0 && len(d.Added) == 0
+}
+
+// Compare returns the triples to remove from before and add to it to get
+// after.
+func Compare(before, after []*rdf.Triple) Diff {
+	oldGround, oldBlank := splitBlank(before)
+	newGround, newBlank := splitBlank(after)
+
+	var d Diff
+	for _, line := range sortedDifference(oldGround, newGround) {
+		d.Removed = append(d.Removed, oldGround[line])
+	}
+	for _, line := range sortedDifference(newGround, oldGround) {
+		d.Added = append(d.Added, newGround[line])
+	}
+	d.Removed = append(d.Removed, relabel(blankDifference(oldBlank, newBlank), "r")...)
+	d.Added = append(d.Added, relabel(blankDifference(newBlank, oldBlank), "a")...)
+	return d
+}
+
+// WritePatch writes the diff as an RDF Patch transaction. Deleting blank
+// nodes only works against stores that kept their labels; WriteUpdate
+// matches them by structure instead.
+func WritePatch(w io.Writer, d Diff) error {
+	if d.Empty() {
+		return nil
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("TX .\n")
+	for _, t := range d.Removed {
+		bw.WriteString("D " + quadLine(Quad{Triple: t}, nil) + "\n")
+	}
+	for _, t := range d.Added {
+		bw.WriteString("A " + quadLine(Quad{Triple: t}, nil) + "\n")
+	}
+	bw.WriteString("TC .\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write patch: %w", err)
+	}
+	return nil
+}
+
+// WriteUpdate writes the diff as a SPARQL 1.1 Update script. DELETE DATA
+// cannot name blank nodes, so each removed blank node structure gets its
+// own DELETE WHERE with the blank nodes as variables.
+func WriteUpdate(w io.Writer, d Diff) error {
+	removedGround, removedBlank := splitBlank(d.Removed)
+	var ops []string
+
+	if len(removedGround) > 0 {
+		ops = append(ops, updateBlock("DELETE DATA", sortedValues(removedGround), false))
+	}
+	for _, component := range components(removedBlank) {
+		ops = append(ops, updateBlock("DELETE WHERE", component, true))
+	}
+	if len(d.Added) > 0 {
+		ops = append(ops, updateBlock("INSERT DATA", d.Added, false))
+	}
+
+	if len(ops) == 0 {
+		return nil
+	}
+	if _, err := io.WriteString(w, strings.Join(ops, " ;\n")+"\n"); err != nil {
+		return fmt.Errorf("write update: %w", err)
+	}
+	return nil
+}
+
+// updateBlock writes one update operation. With vars set, blank nodes
+// become variables.
+func updateBlock(op string, triples []*rdf.Triple, vars bool) string {
+	term := func(t rdf.Term) string {
+		if b, ok := t.(*rdf.BlankNode); ok && vars {
+			return "?" + b.ID
+		}
+		return termString(t, nil)
+	}
+
+	var b strings.Builder
+	b.WriteString(op + " {\n")
+	for _, t := range triples {
+		b.WriteString("  " + term(t.Subject) + " " + term(t.Predicate) + " " + term(t.Object) + " .\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// splitBlank keys triples without blank nodes by their canonical form and
+// returns the rest separately.
+func splitBlank(triples []*rdf.Triple) (map[string]*rdf.Triple, []*rdf.Triple) {
+	ground := make(map[string]*rdf.Triple)
+	var blank []*rdf.Triple
+	for _, t := range triples {
+		if len(blankNodes(Quad{Triple: t})) > 0 {
+			blank = append(blank, t)
+			continue
+		}
+		ground[quadLine(Quad{Triple: t}, nil)] = t
+	}
+	return ground, blank
+}
+
+func sortedDifference(a, b map[string]*rdf.Triple) []string {
+	var lines []string
+	for line := range a {
+		if _, ok := b[line]; !ok {
+			lines = append(lines, line)
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func sortedValues(m map[string]*rdf.Triple) []*rdf.Triple {
+	var triples []*rdf.Triple
+	for _, line := range sortedDifference(m, nil) {
+		triples = append(triples, m[line])
+	}
+	return triples
+}
+
+// blankDifference returns the blank node structures of a that b lacks,
+// each in canonical form, comparing them up to blank node labels.
+func blankDifference(a, b []*rdf.Triple) [][]*rdf.Triple {
+	have := make(map[string]int)
+	for _, component := range components(b) {
+		have[canonicalKey(component)]++
+	}
+
+	keys := make(map[string][]*rdf.Triple)
+	var missing []string
+	for _, component := range components(a) {
+		key := canonicalKey(component)
+		if have[key] > 0 {
+			have[key]--
+			continue
+		}
+		keys[key] = component
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+
+	out := make([][]*rdf.Triple, len(missing))
+	for i, key := range missing {
+		for _, q := range canonicalize(defaultGraph(keys[key])) {
+			out[i] = append(out[i], q.Triple)
+		}
+	}
+	return out
+}
+
+func canonicalKey(triples []*rdf.Triple) string {
+	return strings.Join(canonicalLines(canonicalize(defaultGraph(triples))), "\n")
+}
+
+// components groups triples that share blank nodes, in order of each
+// group's first triple.
+func components(triples []*rdf.Triple) [][]*rdf.Triple {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+	for _, t := range triples {
+		ids := blankNodes(Quad{Triple: t})
+		for _, id := range ids {
+			if _, ok := parent[id]; !ok {
+				parent[id] = id
+			}
+		}
+		if len(ids) == 2 {
+			parent[find(ids[0])] = find(ids[1])
+		}
+	}
+
+	index := make(map[string]int)
+	var out [][]*rdf.Triple
+	for _, t := range triples {
+		root := find(blankNodes(Quad{Triple: t})[0])
+		i, ok := index[root]
+		if !ok {
+			i = len(out)
+			index[root] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], t)
+	}
+	return out
+}
+
+// relabel gives every blank node in the components a fresh label with the
+// given prefix, numbered across all of them.
+func relabel(components [][]*rdf.Triple, prefix string) []*rdf.Triple {
+	iss := newIssuer(prefix)
+	var out []*rdf.Triple
+	for i, component := range components {
+		rename := func(term rdf.Term) rdf.Term {
+			if b, ok := term.(*rdf.BlankNode); ok {
+				return rdf.NewBlankNode(iss.issue(fmt.Sprintf("%d/%s", i, b.ID)))
+			}
+			return term
+		}
+		for _, t := range component {
+			out = append(out, rdf.NewTriple(rename(t.Subject), t.Predicate, rename(t.Object)))
+		}
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+func labelledLink(node, label string) []*rdf.Triple {
+	b := rdf.NewBlankNode(node)
+	return []*rdf.Triple{
+		rdf.NewTriple(b, rdf.NewResource("http://weave.dev/vocab#source"), rdf.NewResource("http://example.org/notes/a")),
+		rdf.NewTriple(b, rdf.NewResource("http://www.w3.org/2000/01/rdf-schema#label"), rdf.NewLiteral(label)),
+	}
+}
+
+func TestCompare(t *testing.T) {
+	title := func(s string) *rdf.Triple {
+		return rdf.NewTriple(rdf.NewResource("http://example.org/notes/a"), rdf.NewResource("http://purl.org/dc/terms/title"), rdf.NewLiteral(s))
+	}
+	before := append([]*rdf.Triple{title("Old")}, labelledLink("x", "same")...)
+	before = append(before, labelledLink("y", "before")...)
+	after := append([]*rdf.Triple{title("New")}, labelledLink("other", "same")...)
+	after = append(after, labelledLink("z", "after")...)
+
+	d := Compare(before, after)
+
+	var patch bytes.Buffer
+	if err := WritePatch(&patch, d); err != nil {
+		t.Fatalf("WritePatch() error = %v", err)
+	}
+	wantPatch := `TX .
+D <http://example.org/notes/a> <http://purl.org/dc/terms/title> "Old" .
+D _:r0 <http://weave.dev/vocab#source> <http://example.org/notes/a> .
+D _:r0 <http://www.w3.org/2000/01/rdf-schema#label> "before" .
+A <http://example.org/notes/a> <http://purl.org/dc/terms/title> "New" .
+A _:a0 <http://weave.dev/vocab#source> <http://example.org/notes/a> .
+A _:a0 <http://www.w3.org/2000/01/rdf-schema#label> "after" .
+TC .
+`
+	if patch.String() != wantPatch {
+		t.Errorf("WritePatch() =\n%s\nwant\n%s", patch.String(), wantPatch)
+	}
+
+	var update bytes.Buffer
+	if err := WriteUpdate(&update, d); err != nil {
+		t.Fatalf("WriteUpdate() error = %v", err)
+	}
+	wantUpdate := `DELETE DATA {
+  <http://example.org/notes/a> <http://purl.org/dc/terms/title> "Old" .
+} ;
+DELETE WHERE {
+  ?r0 <http://weave.dev/vocab#source> <http://example.org/notes/a> .
+  ?r0 <http://www.w3.org/2000/01/rdf-schema#label> "before" .
+} ;
+INSERT DATA {
+  <http://example.org/notes/a> <http://purl.org/dc/terms/title> "New" .
+  _:a0 <http://weave.dev/vocab#source> <http://example.org/notes/a> .
+  _:a0 <http://www.w3.org/2000/01/rdf-schema#label> "after" .
+}
+`
+	if update.String() != wantUpdate {
+		t.Errorf("WriteUpdate() =\n%s\nwant\n%s", update.String(), wantUpdate)
+	}
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	d := Compare(append(sampleTriples(), labelledLink("x", "l")...), append(labelledLink("y", "l"), sampleTriples()...))
+	if !d.Empty() {
+		t.Fatalf("Compare() = %+v, want no changes", d)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePatch(&buf, d); err != nil {
+		t.Fatalf("WritePatch() error = %v", err)
+	}
+	if err := WriteUpdate(&buf, d); err != nil {
+		t.Fatalf("WriteUpdate() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("empty diff wrote %q", buf.String())
+	}
+}
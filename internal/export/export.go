@@ -1 +1,244 @@
 package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+type Format string
+
+const (
+	Turtle   Format = "turtle"
+	NTriples Format = "ntriples"
+	JSONLD   Format = "jsonld"
+	// NQuads and TriG carry named graphs; see WriteQuads.
+	NQuads Format = "nquads"
+	TriG   Format = "trig"
+)
+
+var mimeTypes = map[Format]string{
+	Turtle:   "text/turtle",
+	NTriples: "application/n-triples",
+	JSONLD:   "application/ld+json",
+	NQuads:   "application/n-quads",
+	TriG:     "application/trig",
+}
+
+// ParseFormat accepts a format name or a common file extension.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "turtle", "ttl":
+		return Turtle, nil
+	case "ntriples", "n-triples", "nt":
+		return NTriples, nil
+	case "jsonld", "json-ld":
+		return JSONLD, nil
+	case "nquads", "n-quads", "nq":
+		return NQuads, nil
+	case "trig":
+		return TriG, nil
+	}
+	return "", fmt.Errorf("unknown RDF format %q (want turtle, ntriples, jsonld, nquads, or trig)", name)
+}
+
+func (f Format) MIMEType() string {
+	return mimeTypes[f]
+}
+
+// Write serializes triples in the given format. Output is canonical:
+// duplicates are dropped, blank nodes are relabelled, and triples are
+// sorted, so the same graph always produces the same bytes. rdf2go's
+// Graph.Serialize walks its triples in map order, so Turtle and JSON-LD
+// are written here; Read parses them back with the library.
+func Write(w io.Writer, triples []*rdf.Triple, format Format) error {
+	switch format {
+	case NTriples:
+		bw := bufio.NewWriter(w)
+		for _, line := range canonicalLines(canonicalize(defaultGraph(triples))) {
+			bw.WriteString(line + "\n")
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("write n-triples: %w", err)
+		}
+		return nil
+	case Turtle:
+		return writeTurtle(w, canonicalize(defaultGraph(triples)))
+	case JSONLD:
+		return writeJSONLD(w, canonicalize(defaultGraph(triples)))
+	case NQuads, TriG:
+		return WriteQuads(w, defaultGraph(triples), format)
+	}
+	return fmt.Errorf("unknown RDF format %q", format)
+}
+
+// writeTurtle groups sorted triples by subject and predicate.
+func writeTurtle(w io.Writer, quads []Quad) error {
+	bw := bufio.NewWriter(w)
+	var subject, predicate string
+	for i, q := range quads {
+		s, p, o := termString(q.Subject, nil), termString(q.Predicate, nil), termString(q.Object, nil)
+		switch {
+		case i == 0:
+			bw.WriteString(s + "\n  " + p + " " + o)
+		case s != subject:
+			bw.WriteString(" .\n\n" + s + "\n  " + p + " " + o)
+		case p != predicate:
+			bw.WriteString(" ;\n  " + p + " " + o)
+		default:
+			bw.WriteString(" ,\n    " + o)
+		}
+		subject, predicate = s, p
+	}
+	if len(quads) > 0 {
+		bw.WriteString(" .\n")
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write turtle: %w", err)
+	}
+	return nil
+}
+
+// writeJSONLD writes expanded JSON-LD: one node object per subject, in
+// sorted order.
+func writeJSONLD(w io.Writer, quads []Quad) error {
+	nodes := []map[string]any{}
+	var subject string
+	for _, q := range quads {
+		id := termString(q.Subject, nil)
+		if r, ok := q.Subject.(*rdf.Resource); ok {
+			id = r.URI
+		}
+		if len(nodes) == 0 || id != subject {
+			nodes = append(nodes, map[string]any{"@id": id})
+			subject = id
+		}
+		node := nodes[len(nodes)-1]
+		pred := q.Predicate.RawValue()
+		values, _ := node[pred].([]map[string]string)
+		node[pred] = append(values, jsonLDValue(q.Object))
+	}
+
+	data, err := json.MarshalIndent(nodes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("write jsonld: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write jsonld: %w", err)
+	}
+	return nil
+}
+
+func jsonLDValue(term rdf.Term) map[string]string {
+	switch t := term.(type) {
+	case *rdf.Resource:
+		return map[string]string{"@id": t.URI}
+	case *rdf.BlankNode:
+		return map[string]string{"@id": "_:" + t.ID}
+	case *rdf.Literal:
+		v := map[string]string{"@value": t.Value}
+		if t.Language != "" {
+			v["@language"] = t.Language
+		} else if t.Datatype != nil && t.Datatype.RawValue() != xsdString {
+			v["@type"] = t.Datatype.RawValue()
+		}
+		return v
+	}
+	return map[string]string{"@value": term.RawValue()}
+}
+
+// Read parses triples in the given format. N-Triples is a subset of Turtle,
+// so both go through the Turtle parser.
+func Read(r io.Reader, format Format) ([]*rdf.Triple, error) {
+	var mimeType string
+	switch format {
+	case Turtle, NTriples:
+		mimeType = mimeTypes[Turtle]
+	case JSONLD:
+		mimeType = mimeTypes[JSONLD]
+	case NQuads, TriG:
+		return nil, fmt.Errorf("reading %s is not supported", format)
+	default:
+		return nil, fmt.Errorf("unknown RDF format %q", format)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", format, err)
+	}
+	if mimeType == mimeTypes[Turtle] {
+		// The Turtle lexer spins forever on a token left open at end of
+		// input; a trailing newline makes it fail instead, except inside
+		// long strings, which are rejected up front.
+		if unterminatedLongString(string(data)) {
+			return nil, fmt.Errorf("parse %s: unterminated long string", format)
+		}
+		data = append(data, '\n')
+	}
+
+	g := rdf.NewGraph("")
+	if err := g.Parse(bytes.NewReader(data), mimeType); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", format, err)
+	}
+
+	var triples []*rdf.Triple
+	for t := range g.IterTriples() {
+		triples = append(triples, t)
+	}
+	return triples, nil
+}
+
+// unterminatedLongString reports whether a Turtle document ends inside a
+// triple-quoted string. IRIs, comments and short strings are skipped, so
+// quotes inside them do not count.
+func unterminatedLongString(src string) bool {
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case '<':
+			for i < len(src) && src[i] != '>' && src[i] != '\n' {
+				i++
+			}
+		case '"', '\'':
+			delim := strings.Repeat(string(c), 3)
+			if !strings.HasPrefix(src[i:], delim) {
+				// A short string ends at its quote or, unterminated, at
+				// the end of the line, where the parser reports it.
+				for i++; i < len(src) && src[i] != c && src[i] != '\n'; i++ {
+					if src[i] == '\\' {
+						i++
+					}
+				}
+				continue
+			}
+			closed := false
+			for i += 3; i < len(src); i++ {
+				if src[i] == '\\' {
+					i++
+					continue
+				}
+				if strings.HasPrefix(src[i:], delim) {
+					// Up to two quotes before the delimiter belong to the
+					// string, so the run of quotes ends it.
+					for i+1 < len(src) && src[i+1] == c {
+						i++
+					}
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+func sampleTriples() []*rdf.Triple {
+	return []*rdf.Triple{
+		rdf.NewTriple(
+			rdf.NewResource("http://example.org/notes/a"),
+			rdf.NewResource("http://purl.org/dc/terms/title"),
+			rdf.NewLiteral("A"),
+		),
+		rdf.NewTriple(
+			rdf.NewResource("http://example.org/notes/a"),
+			rdf.NewResource("http://weave.dev/vocab#linksTo"),
+			rdf.NewResource("http://example.org/notes/b"),
+		),
+	}
+}
+
+func TestWriteNTriples(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleTriples(), NTriples); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Write() produced %d lines, want 2", len(lines))
+	}
+	if lines[0] != `<http://example.org/notes/a> <http://purl.org/dc/terms/title> "A" .` {
+		t.Errorf("line 0 = %q", lines[0])
+	}
+}
+
+func TestWriteTurtleRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleTriples(), Turtle); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	g := rdf.NewGraph("")
+	if err := g.Parse(&buf, "text/turtle"); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if g.Len() != 2 {
+		t.Errorf("parsed %d triples, want 2", g.Len())
+	}
+}
+
+func TestWriteJSONLD(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleTriples(), JSONLD); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "http://example.org/notes/a") {
+		t.Errorf("JSON-LD output missing subject: %s", buf.String())
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Format
+	}{
+		{"turtle", Turtle},
+		{"ttl", Turtle},
+		{".nt", NTriples},
+		{"json-ld", JSONLD},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParseFormat("rdfxml"); err == nil {
+		t.Error("ParseFormat(rdfxml) error = nil, want error")
+	}
+}
+
+func TestReadRoundTrip(t *testing.T) {
+	for _, format := range []Format{Turtle, NTriples, JSONLD} {
+		var buf bytes.Buffer
+		if err := Write(&buf, sampleTriples(), format); err != nil {
+			t.Fatalf("Write(%s) error = %v", format, err)
+		}
+		triples, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("Read(%s) error = %v", format, err)
+		}
+		if len(triples) != len(sampleTriples()) {
+			t.Errorf("Read(%s) returned %d triples, want %d", format, len(triples), len(sampleTriples()))
+		}
+	}
+
+	for _, in := range []string{"<a> <b", `<http://a> <http://b> "x`, `<http://a> <http://b> """x`} {
+		if _, err := Read(strings.NewReader(in), Turtle); err == nil {
+			t.Errorf("Read(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestReadLongStringQuotes(t *testing.T) {
+	valid := []string{
+		`<http://a> <http://b> "it's '''quoted''' here" .`,
+		`<http://a> <http://b> """one ''' and \""" inside""" .`,
+		`<http://a> <http://b> '''ends with quotes""''' .`,
+		`<http://a> <http://b> "x" . # a """ comment` + "\n",
+	}
+	for _, in := range valid {
+		if _, err := Read(strings.NewReader(in), Turtle); err != nil {
+			t.Errorf("Read(%q) error = %v", in, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	body := rdf.NewTriple(rdf.NewResource("http://a"), rdf.NewResource("http://b"), rdf.NewLiteral("code:\n'''\nx = \"\"\"doc\"\"\"\n'''"))
+	if err := Write(&buf, []*rdf.Triple{body}, Turtle); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	triples, err := Read(&buf, Turtle)
+	if err != nil || len(triples) != 1 || triples[0].Object.RawValue() != body.Object.RawValue() {
+		t.Errorf("Read() = %v, %v, want the body back", triples, err)
+	}
+
+	if _, err := Read(strings.NewReader(`<http://a> <http://b> '''x""" .`), Turtle); err == nil {
+		t.Error("Read() of an unterminated ''' string error = nil")
+	}
+}
+
+// TestWriteParsesBack checks that the Turtle and JSON-LD writers produce
+// documents the library parser reads back to the same graph.
+func TestWriteParsesBack(t *testing.T) {
+	a, b := rdf.NewResource("http://example.org/notes/a"), rdf.NewBlankNode("link")
+	input := append(sampleTriples(),
+		rdf.NewTriple(a, rdf.NewResource("http://purl.org/dc/terms/title"), rdf.NewLiteralWithLanguage("Ä \"quoted\"\nline", "de")),
+		rdf.NewTriple(a, rdf.NewResource("http://purl.org/dc/terms/created"), rdf.NewLiteralWithDatatype("2025-01-01T00:00:00Z", rdf.NewResource("http://www.w3.org/2001/XMLSchema#dateTime"))),
+		rdf.NewTriple(a, rdf.NewResource("http://weave.dev/vocab#linksTo"), rdf.NewResource("http://example.org/notes/c#design")),
+		rdf.NewTriple(b, rdf.NewResource("http://weave.dev/vocab#source"), a),
+		rdf.NewTriple(b, rdf.NewResource("http://www.w3.org/2000/01/rdf-schema#label"), rdf.NewLiteral("more")),
+	)
+	for _, format := range []Format{Turtle, JSONLD} {
+		var buf bytes.Buffer
+		if err := Write(&buf, input, format); err != nil {
+			t.Fatalf("Write(%s) error = %v", format, err)
+		}
+		got, err := Read(bytes.NewReader(buf.Bytes()), format)
+		if err != nil {
+			t.Fatalf("Read(%s) error = %v\n%s", format, err, buf.String())
+		}
+		if Hash(got) != Hash(input) {
+			t.Errorf("%s did not parse back to the written graph:\n%s", format, buf.String())
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+// Quad is a triple placed in a named graph. An empty Graph means the
+// default graph.
+type Quad struct {
+	*rdf.Triple
+	Graph string
+}
+
+// HasGraphs reports whether the format can carry named graphs.
+func (f Format) HasGraphs() bool {
+	return f == NQuads || f == TriG
+}
+
+// WriteQuads serializes quads canonically, like Write. TriG writes the
+// default graph first and then each named graph in IRI order. Formats
+// without named graphs get the triples alone.
+func WriteQuads(w io.Writer, quads []Quad, format Format) error {
+	switch format {
+	case NQuads:
+		bw := bufio.NewWriter(w)
+		for _, line := range canonicalLines(canonicalize(quads)) {
+			bw.WriteString(line + "\n")
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("write n-quads: %w", err)
+		}
+		return nil
+	case TriG:
+		graphs := make(map[string][]Quad)
+		for _, q := range canonicalize(quads) {
+			graphs[q.Graph] = append(graphs[q.Graph], q)
+		}
+		names := make([]string, 0, len(graphs))
+		for name := range graphs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		bw := bufio.NewWriter(w)
+		for i, graph := range names {
+			if i > 0 {
+				bw.WriteString("\n")
+			}
+			if graph == "" {
+				bw.WriteString("{\n")
+			} else {
+				bw.WriteString(iriString(graph) + " {\n")
+			}
+			for _, q := range graphs[graph] {
+				bw.WriteString("  " + quadLine(Quad{Triple: q.Triple}, nil) + "\n")
+			}
+			bw.WriteString("}\n")
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("write trig: %w", err)
+		}
+		return nil
+	}
+
+	triples := make([]*rdf.Triple, len(quads))
+	for i, q := range quads {
+		triples[i] = q.Triple
+	}
+	return Write(w, triples, format)
+}
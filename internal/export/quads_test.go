@@ -0,0 +1,71 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func sampleQuads() []Quad {
+	triples := sampleTriples()
+	return []Quad{
+		{Triple: triples[0], Graph: "http://example.org/graphs/a"},
+		{Triple: triples[1]},
+		{Triple: triples[1], Graph: "http://example.org/graphs/a"},
+	}
+}
+
+func TestWriteNQuads(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteQuads(&buf, sampleQuads(), NQuads); err != nil {
+		t.Fatalf("WriteQuads() error = %v", err)
+	}
+
+	want := `<http://example.org/notes/a> <http://purl.org/dc/terms/title> "A" <http://example.org/graphs/a> .
+<http://example.org/notes/a> <http://weave.dev/vocab#linksTo> <http://example.org/notes/b> .
+<http://example.org/notes/a> <http://weave.dev/vocab#linksTo> <http://example.org/notes/b> <http://example.org/graphs/a> .
+`
+	if buf.String() != want {
+		t.Errorf("WriteQuads() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteTriG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteQuads(&buf, sampleQuads(), TriG); err != nil {
+		t.Fatalf("WriteQuads() error = %v", err)
+	}
+
+	want := `{
+  <http://example.org/notes/a> <http://weave.dev/vocab#linksTo> <http://example.org/notes/b> .
+}
+
+<http://example.org/graphs/a> {
+  <http://example.org/notes/a> <http://purl.org/dc/terms/title> "A" .
+  <http://example.org/notes/a> <http://weave.dev/vocab#linksTo> <http://example.org/notes/b> .
+}
+`
+	if buf.String() != want {
+		t.Errorf("WriteQuads() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteQuadsWithoutGraphs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteQuads(&buf, sampleQuads(), NTriples); err != nil {
+		t.Fatalf("WriteQuads() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "graphs") {
+		t.Errorf("N-Triples output names a graph:\n%s", buf.String())
+	}
+}
+
+func TestWriteTriplesAsQuads(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleTriples(), NQuads); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("Write() produced %d lines, want 2", got)
+	}
+}
@@ -1 +1,255 @@
 package graph
+
+import (
+	"iter"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	rdf "github.com/deiu/rdf2go"
+)
+
+// Edge is a link between two notes, identified by the predicate IRI the
+// projection assigned to it.
+type Edge struct {
+	From      string
+	To        string
+	Predicate string
+}
+
+// Graph indexes note-to-note links from the projected triples so neighbors
+// and backlinks can be looked up without rescanning the vault. It is safe
+// for concurrent use.
+type Graph struct {
+	mu         sync.RWMutex
+	notePrefix string
+	proj       *rdfproj.Projector
+	notes      map[string]markdown.Note
+	// stale is set when notes changed after the edges were built. Links
+	// may name a note by title or alias, so changing one note can move the
+	// edges of others; they are rebuilt on the next lookup.
+	stale bool
+	out   map[string][]Edge
+	in    map[string]map[string][]Edge
+}
+
+func New(proj *rdfproj.Projector) *Graph {
+	return &Graph{
+		proj:       proj,
+		notePrefix: proj.BaseURI() + "/notes/",
+		notes:      make(map[string]markdown.Note),
+		out:        make(map[string][]Edge),
+		in:         make(map[string]map[string][]Edge),
+	}
+}
+
+func Build(notes iter.Seq[markdown.Note], proj *rdfproj.Projector) *Graph {
+	g := New(proj)
+	for note := range notes {
+		g.SetNote(note)
+	}
+	return g
+}
+
+// SetNote adds a note or replaces its outgoing links.
+func (g *Graph) SetNote(note markdown.Note) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.notes[note.ID] = note
+	g.stale = true
+}
+
+// RemoveNote drops a note and its outgoing links. Links pointing at the
+// note from elsewhere are kept so they show up as dangling.
+func (g *Graph) RemoveNote(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.notes, id)
+	g.stale = true
+}
+
+// rlock locks g for reading, first rebuilding the edges if they are stale.
+func (g *Graph) rlock() {
+	g.mu.RLock()
+	for g.stale {
+		g.mu.RUnlock()
+		g.mu.Lock()
+		if g.stale {
+			g.index()
+		}
+		g.mu.Unlock()
+		g.mu.RLock()
+	}
+}
+
+// index projects every note against the whole vault, so links resolve the
+// way an export resolves them, and rebuilds the edges from the result.
+func (g *Graph) index() {
+	vault := make([]markdown.Note, 0, len(g.notes))
+	for _, note := range g.notes {
+		vault = append(vault, note)
+	}
+	proj := g.proj.WithVault(vault)
+
+	g.out = make(map[string][]Edge, len(g.notes))
+	g.in = make(map[string]map[string][]Edge)
+	for id, note := range g.notes {
+		edges := g.edgesFromTriples(id, proj.NoteToTriples(note))
+		g.out[id] = edges
+		for _, e := range edges {
+			if g.in[e.To] == nil {
+				g.in[e.To] = make(map[string][]Edge)
+			}
+			g.in[e.To][e.From] = append(g.in[e.To][e.From], e)
+		}
+	}
+	g.stale = false
+}
+
+func (g *Graph) Has(id string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.notes[id]
+	return ok
+}
+
+// IDs returns every note in the graph, sorted.
+func (g *Graph) IDs() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	ids := make([]string, 0, len(g.notes))
+	for id := range g.notes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Neighbors returns the links written in the note, sorted by target.
+func (g *Graph) Neighbors(id string) []Edge {
+	g.rlock()
+	defer g.mu.RUnlock()
+
+	edges := append([]Edge(nil), g.out[id]...)
+	sortEdges(edges)
+	return edges
+}
+
+// Backlinks returns the links pointing at the note, sorted by source.
+func (g *Graph) Backlinks(id string) []Edge {
+	g.rlock()
+	defer g.mu.RUnlock()
+
+	var edges []Edge
+	for _, fromEdges := range g.in[id] {
+		edges = append(edges, fromEdges...)
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// Edges returns every link in the graph, sorted.
+func (g *Graph) Edges() []Edge {
+	g.rlock()
+	defer g.mu.RUnlock()
+
+	var edges []Edge
+	for _, out := range g.out {
+		edges = append(edges, out...)
+	}
+	sortEdges(edges)
+	return edges
+}
+
+// Traverse walks outgoing links breadth-first from start, up to depth hops,
+// following only edges accepted by follow (all edges when nil). It returns
+// the visited note IDs in visit order, starting with start.
+func (g *Graph) Traverse(start string, depth int, follow func(Edge) bool) []string {
+	g.rlock()
+	defer g.mu.RUnlock()
+
+	visited := map[string]bool{start: true}
+	order := []string{start}
+	frontier := []string{start}
+
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []string
+		for _, id := range frontier {
+			edges := append([]Edge(nil), g.out[id]...)
+			sortEdges(edges)
+			for _, e := range edges {
+				if follow != nil && !follow(e) {
+					continue
+				}
+				if visited[e.To] {
+					continue
+				}
+				visited[e.To] = true
+				order = append(order, e.To)
+				next = append(next, e.To)
+			}
+		}
+		frontier = next
+	}
+
+	return order
+}
+
+func (g *Graph) edgesFromTriples(id string, triples []*rdf.Triple) []Edge {
+	var edges []Edge
+	subject := g.proj.NoteURI(id)
+	for _, t := range triples {
+		if t.Subject.RawValue() != subject {
+			continue
+		}
+		// Sections and other fragments of the note are not edges.
+		if _, ok := g.proj.Vocabulary().RelationType(t.Predicate.RawValue()); !ok {
+			continue
+		}
+		obj, ok := t.Object.(*rdf.Resource)
+		if !ok {
+			continue
+		}
+		target, ok := g.noteID(obj.URI)
+		if !ok {
+			continue
+		}
+		edges = append(edges, Edge{
+			From:      id,
+			To:        target,
+			Predicate: t.Predicate.RawValue(),
+		})
+	}
+	return edges
+}
+
+func (g *Graph) noteID(uri string) (string, bool) {
+	if !strings.HasPrefix(uri, g.notePrefix) {
+		return "", false
+	}
+	// A link to a heading or block is an edge to the note.
+	uri, _, _ = strings.Cut(uri, "#")
+	id, err := url.PathUnescape(strings.TrimPrefix(uri, g.notePrefix))
+	if err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+		return edges[i].Predicate < edges[j].Predicate
+	})
+}
@@ -0,0 +1,161 @@
+package graph
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+)
+
+var testProjector = rdfproj.New("http://example.org", nil)
+
+const (
+	linksTo = "http://weave.dev/vocab#linksTo"
+	broader = "http://www.w3.org/2004/02/skos/core#broader"
+)
+
+func testNotes() []markdown.Note {
+	return []markdown.Note{
+		{ID: "a-20250101000000", Title: "A", Type: "Note", Tags: []string{"go"}, Links: []links.Link{
+			{ID: "b-20250101000000", Type: "linksTo"},
+			{ID: "c-20250101000000", Type: "broader"},
+		}},
+		{ID: "b-20250101000000", Title: "B", Type: "Note", Links: []links.Link{
+			{ID: "a-20250101000000", Type: "linksTo"},
+		}},
+		{ID: "c-20250101000000", Title: "C", Type: "Note"},
+		{ID: "orphan-20250101000000", Title: "Orphan", Type: "Note"},
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	got := g.Neighbors("a-20250101000000")
+	want := []Edge{
+		{From: "a-20250101000000", To: "b-20250101000000", Predicate: linksTo},
+		{From: "a-20250101000000", To: "c-20250101000000", Predicate: broader},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Neighbors() = %v, want %v", got, want)
+	}
+}
+
+func TestBacklinksWithCycle(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	got := g.Backlinks("a-20250101000000")
+	want := []Edge{{From: "b-20250101000000", To: "a-20250101000000", Predicate: linksTo}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Backlinks() = %v, want %v", got, want)
+	}
+}
+
+func TestOrphan(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	if !g.Has("orphan-20250101000000") {
+		t.Fatal("Has(orphan) = false, want true")
+	}
+	if n := g.Neighbors("orphan-20250101000000"); len(n) != 0 {
+		t.Errorf("Neighbors(orphan) = %v, want none", n)
+	}
+	if b := g.Backlinks("orphan-20250101000000"); len(b) != 0 {
+		t.Errorf("Backlinks(orphan) = %v, want none", b)
+	}
+}
+
+func TestSetNoteReplacesLinks(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	g.SetNote(markdown.Note{ID: "a-20250101000000", Title: "A", Type: "Note"})
+
+	if n := g.Neighbors("a-20250101000000"); len(n) != 0 {
+		t.Errorf("Neighbors() after update = %v, want none", n)
+	}
+	if b := g.Backlinks("c-20250101000000"); len(b) != 0 {
+		t.Errorf("Backlinks(c) after update = %v, want none", b)
+	}
+}
+
+func TestRemoveNote(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	g.RemoveNote("b-20250101000000")
+
+	if g.Has("b-20250101000000") {
+		t.Error("Has(b) after remove = true, want false")
+	}
+	if b := g.Backlinks("a-20250101000000"); len(b) != 0 {
+		t.Errorf("Backlinks(a) after removing b = %v, want none", b)
+	}
+	if b := g.Backlinks("b-20250101000000"); len(b) != 1 {
+		t.Errorf("Backlinks(b) after remove = %v, want dangling link from a", b)
+	}
+}
+
+func TestTraverse(t *testing.T) {
+	g := Build(slices.Values(testNotes()), testProjector)
+
+	got := g.Traverse("b-20250101000000", 2, nil)
+	want := []string{"b-20250101000000", "a-20250101000000", "c-20250101000000"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse() = %v, want %v", got, want)
+	}
+
+	got = g.Traverse("a-20250101000000", 3, func(e Edge) bool { return e.Predicate == broader })
+	want = []string{"a-20250101000000", "c-20250101000000"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Traverse(broader only) = %v, want %v", got, want)
+	}
+}
+
+func TestLabelledLinkIsOneEdge(t *testing.T) {
+	g := New(testProjector)
+	g.SetNote(markdown.Note{ID: "a-20250101000000", Title: "A", Links: []links.Link{
+		{ID: "b-20250101000000", Type: "linksTo", Label: "B"},
+	}})
+
+	want := []Edge{{From: "a-20250101000000", To: "b-20250101000000", Predicate: linksTo}}
+	if got := g.Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges() = %v, want %v", got, want)
+	}
+}
+
+func TestFragmentLinkIsNoteEdge(t *testing.T) {
+	g := New(rdfproj.New("http://example.org", nil).WithStructure(true))
+	g.SetNote(markdown.Note{ID: "a-20250101000000", Title: "A", Body: "# Sections are not edges", Links: []links.Link{
+		{ID: "b-20250101000000", Type: "linksTo", Anchor: "Design"},
+	}})
+
+	want := []Edge{{From: "a-20250101000000", To: "b-20250101000000", Predicate: linksTo}}
+	if got := g.Edges(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Edges() = %v, want %v", got, want)
+	}
+}
+
+func TestLinksResolveByTitle(t *testing.T) {
+	g := New(testProjector)
+	g.SetNote(markdown.Note{ID: "a-20250101000000", Title: "A", Links: []links.Link{
+		{ID: "Target", Type: "linksTo"},
+	}})
+	if got := g.Edges(); len(got) != 1 || got[0].To != "Target" {
+		t.Errorf("Edges() before the target exists = %v, want a dangling edge to Target", got)
+	}
+
+	g.SetNote(markdown.Note{ID: "b-20250101000000", Title: "Target"})
+	want := []Edge{{From: "a-20250101000000", To: "b-20250101000000", Predicate: linksTo}}
+	if got := g.Backlinks("b-20250101000000"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Backlinks() = %v, want %v", got, want)
+	}
+
+	g.SetNote(markdown.Note{ID: "b-20250101000000", Title: "Renamed"})
+	if got := g.Backlinks("b-20250101000000"); len(got) != 0 {
+		t.Errorf("Backlinks() after retitling = %v, want none", got)
+	}
+}
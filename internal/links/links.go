@@ -6,10 +6,26 @@ type Link struct {
 	ID    string
 	Type  string
 	Label string
+	// Anchor names a heading in the target, as in [[id#Heading]]; Block
+	// is a block marked ^block-id in it, as in [[id^block-id]].
+	Anchor string `yaml:"anchor,omitempty"`
+	Block  string `yaml:"block,omitempty"`
 }
 
 const DefaultLinkType = "linksTo"
 
+// Ref is what the link points at as written in a wikilink: the note,
+// then #heading or ^block.
+func (l Link) Ref() string {
+	switch {
+	case l.Block != "":
+		return l.ID + "^" + l.Block
+	case l.Anchor != "":
+		return l.ID + "#" + l.Anchor
+	}
+	return l.ID
+}
+
 func FormatLink(id, relType, label string) string {
 	if relType == "" {
 		relType = DefaultLinkType
@@ -49,7 +65,7 @@ func isInCodeBlock(body string, pos int) bool {
 
 	lineStart := strings.LastIndex(body[:pos], "\n") + 1
 	inlinePart := body[lineStart:pos]
-	
+
 	backtickCount := 0
 	j := 0
 	for j < len(inlinePart) {
@@ -62,12 +78,85 @@ func isInCodeBlock(body string, pos int) bool {
 		}
 		j++
 	}
-	
+
 	return backtickCount%2 == 1
 }
 
 func ParseLinks(body string) []Link {
 	var out []Link
+	eachLink(body, func(start, end int, link Link) {
+		out = append(out, link)
+	})
+	return out
+}
+
+// ReplaceLinks returns body with every link outside code replaced by the
+// result of fn. Malformed links are left as written.
+func ReplaceLinks(body string, fn func(Link) string) string {
+	var b strings.Builder
+	last := 0
+	eachLink(body, func(start, end int, link Link) {
+		b.WriteString(body[last:start])
+		b.WriteString(fn(link))
+		last = end
+	})
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// RedactLinks returns body without the links outside code that drop
+// reports true for. A labelled link leaves its label as plain text; other
+// links are kept as written.
+func RedactLinks(body string, drop func(Link) bool) string {
+	var b strings.Builder
+	last := 0
+	eachLink(body, func(start, end int, link Link) {
+		b.WriteString(body[last:start])
+		last = end
+		if drop(link) {
+			b.WriteString(link.Label)
+			return
+		}
+		b.WriteString(body[start:end])
+	})
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// ReplaceLinkRefs returns body with what every link outside code points
+// at replaced by the Ref of the link fn returns. Relation types and labels
+// are kept as written, and so are links fn returns unchanged.
+func ReplaceLinkRefs(body string, fn func(Link) Link) string {
+	var b strings.Builder
+	last := 0
+	eachLink(body, func(start, end int, link Link) {
+		b.WriteString(body[last:start])
+		last = end
+		replaced := fn(link)
+		if replaced == link {
+			b.WriteString(body[start:end])
+			return
+		}
+
+		content := body[start+2 : end-2]
+		left, label, hasLabel := strings.Cut(content, "|")
+		prefix := ""
+		if relType, _, typed := strings.Cut(left, "::"); typed {
+			prefix = relType + "::"
+		}
+		b.WriteString("[[" + prefix + replaced.Ref())
+		if hasLabel {
+			b.WriteString("|" + label)
+		}
+		b.WriteString("]]")
+	})
+	b.WriteString(body[last:])
+	return b.String()
+}
+
+// eachLink calls fn with the byte range and parsed form of every valid link
+// in body, skipping code blocks and inline code.
+func eachLink(body string, fn func(start, end int, link Link)) {
 	start := 0
 
 	for {
@@ -76,7 +165,7 @@ func ParseLinks(body string) []Link {
 			break
 		}
 		open += start
-		
+
 		close := strings.Index(body[open+2:], "]]")
 		if close == -1 {
 			break
@@ -99,13 +188,11 @@ func ParseLinks(body string) []Link {
 
 		link := parseLinkContent(content)
 		if link.ID != "" {
-			out = append(out, link)
+			fn(open, close+2, link)
 		}
 
 		start = close + 2
 	}
-
-	return out
 }
 
 func parseLinkContent(content string) Link {
@@ -117,19 +204,34 @@ func parseLinkContent(content string) Link {
 		label = parts[1]
 	}
 
+	relType := DefaultLinkType
+	target := left
 	typeAndID := strings.SplitN(left, "::", 2)
 	if len(typeAndID) == 2 {
-		relType := typeAndID[0]
-		id := typeAndID[1]
-		if relType == "" || id == "" {
+		relType = typeAndID[0]
+		target = typeAndID[1]
+		if relType == "" {
 			return Link{}
 		}
-		return Link{ID: id, Type: relType, Label: label}
 	}
 
-	if left == "" {
+	id, anchor, block := splitTarget(target)
+	if id == "" {
 		return Link{}
 	}
+	return Link{ID: id, Type: relType, Label: label, Anchor: anchor, Block: block}
+}
 
-	return Link{ID: left, Type: DefaultLinkType, Label: label}
+// splitTarget separates a heading (#Heading) or block (^id or #^id) from
+// the note a link names.
+func splitTarget(target string) (id, anchor, block string) {
+	i := strings.IndexAny(target, "#^")
+	if i == -1 {
+		return target, "", ""
+	}
+	id, fragment := target[:i], strings.TrimPrefix(target[i:], "#")
+	if b, ok := strings.CutPrefix(fragment, "^"); ok {
+		return id, "", b
+	}
+	return id, fragment, ""
 }
@@ -6,7 +6,7 @@ import (
 )
 
 func TestFormatLink_DefaultTypeNoLabel(t *testing.T) {
-	got  := FormatLink("note-123", "", "")
+	got := FormatLink("note-123", "", "")
 	want := "[[note-123]]"
 
 	if got != want {
@@ -15,7 +15,7 @@ func TestFormatLink_DefaultTypeNoLabel(t *testing.T) {
 }
 
 func TestFormatLink_ExplicitDefaultTypeNoLabel(t *testing.T) {
-	got  := FormatLink("note-123", DefaultLinkType, "")
+	got := FormatLink("note-123", DefaultLinkType, "")
 	want := "[[note-123]]"
 
 	if got != want {
@@ -24,7 +24,7 @@ func TestFormatLink_ExplicitDefaultTypeNoLabel(t *testing.T) {
 }
 
 func TestFormatLink_DefaultTypeWithLabel(t *testing.T) {
-	got  := FormatLink("note-123", "", "My Note")
+	got := FormatLink("note-123", "", "My Note")
 	want := "[[note-123|My Note]]"
 
 	if got != want {
@@ -33,7 +33,7 @@ func TestFormatLink_DefaultTypeWithLabel(t *testing.T) {
 }
 
 func TestFormatLink_ExplicitDefaultTypeWithLabel(t *testing.T) {
-	got  := FormatLink("note-123", DefaultLinkType, "My Note")
+	got := FormatLink("note-123", DefaultLinkType, "My Note")
 	want := "[[note-123|My Note]]"
 
 	if got != want {
@@ -42,7 +42,7 @@ func TestFormatLink_ExplicitDefaultTypeWithLabel(t *testing.T) {
 }
 
 func TestFormatLink_TypedNoLabel(t *testing.T) {
-	got  := FormatLink("note-123", "related", "")
+	got := FormatLink("note-123", "related", "")
 	want := "[[related::note-123]]"
 
 	if got != want {
@@ -51,7 +51,7 @@ func TestFormatLink_TypedNoLabel(t *testing.T) {
 }
 
 func TestFormatLink_TypedWithLabel(t *testing.T) {
-	got  := FormatLink("note-123", "related", "Reference")
+	got := FormatLink("note-123", "related", "Reference")
 	want := "[[related::note-123|Reference]]"
 
 	if got != want {
@@ -68,7 +68,7 @@ func TestParseLinks_Empty(t *testing.T) {
 
 func TestParseLinks_DefaultNoLabel(t *testing.T) {
 	body := "See [[note-123]] for details."
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "note-123", Type: DefaultLinkType, Label: ""},
@@ -76,12 +76,12 @@ func TestParseLinks_DefaultNoLabel(t *testing.T) {
 
 	if !reflect.DeepEqual(got, want) {
 		t.Fatalf("ParseLinks() = %#v, want %#v", got, want)
-	} 
+	}
 }
 
 func TestParseLinks_DefaultWithLabel(t *testing.T) {
 	body := "See [[note-123|My Note]] for details."
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "note-123", Type: DefaultLinkType, Label: "My Note"},
@@ -120,7 +120,7 @@ func TestParseLinks_TypedWithLabel(t *testing.T) {
 
 func TestParseLinks_Multiple(t *testing.T) {
 	body := "A [[a-1]] B [[related::b-2|Bee]] C [[c-3|See]]"
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "a-1", Type: DefaultLinkType, Label: ""},
@@ -135,7 +135,7 @@ func TestParseLinks_Multiple(t *testing.T) {
 
 func TestParseLinks_SkipsMalformed(t *testing.T) {
 	body := "Bad [[no-close and [[::missingid]] and [[ok-1]]"
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "ok-1", Type: DefaultLinkType, Label: ""},
@@ -148,7 +148,7 @@ func TestParseLinks_SkipsMalformed(t *testing.T) {
 
 func TestParseLinks_SkipsInlineCode(t *testing.T) {
 	body := "Real [[note-1]] and example `[[note-2]]` end"
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "note-1", Type: DefaultLinkType, Label: ""},
@@ -161,7 +161,7 @@ func TestParseLinks_SkipsInlineCode(t *testing.T) {
 
 func TestParseLinks_SkipsFencedCodeBlock(t *testing.T) {
 	body := "Real [[note-1]] text\n```\n[[note-2]]\n```\nReal [[note-3]]"
-	got  := ParseLinks(body)
+	got := ParseLinks(body)
 
 	want := []Link{
 		{ID: "note-1", Type: DefaultLinkType, Label: ""},
@@ -172,3 +172,40 @@ func TestParseLinks_SkipsFencedCodeBlock(t *testing.T) {
 		t.Fatalf("ParseLinks() = %#v, want %#v", got, want)
 	}
 }
+
+func TestReplaceLinks(t *testing.T) {
+	body := "See [[a-1]] and [[broader::b-2|Bee]], not `[[c-3]]` or [[]]."
+	got := ReplaceLinks(body, func(l Link) string {
+		return "<" + l.Type + ":" + l.ID + ">"
+	})
+	want := "See <linksTo:a-1> and <broader:b-2>, not `[[c-3]]` or [[]]."
+
+	if got != want {
+		t.Fatalf("ReplaceLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestRedactLinks(t *testing.T) {
+	body := "See [[a-1#Plan]], [[broader::b-2|Bee]] and [[c-3]], not `[[b-2]]`."
+	got := RedactLinks(body, func(l Link) bool { return l.ID != "a-1" })
+	want := "See [[a-1#Plan]], Bee and , not `[[b-2]]`."
+
+	if got != want {
+		t.Fatalf("RedactLinks() = %q, want %q", got, want)
+	}
+}
+
+func TestParseLinks_HeadingsAndBlocks(t *testing.T) {
+	body := "[[note-1#Design Notes]] [[part::note-2#^abc-1|the quote]] [[note-3^xyz]] [[#Local]]"
+	got := ParseLinks(body)
+
+	want := []Link{
+		{ID: "note-1", Type: DefaultLinkType, Anchor: "Design Notes"},
+		{ID: "note-2", Type: "part", Label: "the quote", Block: "abc-1"},
+		{ID: "note-3", Type: DefaultLinkType, Block: "xyz"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseLinks() = %#v, want %#v", got, want)
+	}
+}
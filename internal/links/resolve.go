@@ -0,0 +1,124 @@
+package links
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Target is a note that links can name.
+type Target struct {
+	ID      string
+	Title   string
+	Aliases []string
+}
+
+// ResolveError reports a link target that names no note, or several.
+type ResolveError struct {
+	Name       string
+	Candidates []string
+}
+
+func (e *ResolveError) Error() string {
+	if len(e.Candidates) == 0 {
+		return fmt.Sprintf("no note matches %q", e.Name)
+	}
+	return fmt.Sprintf("%q is ambiguous: %s", e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// Resolver maps what links name to note IDs.
+type Resolver struct {
+	ids     []string
+	known   map[string]bool
+	titles  map[string][]string
+	aliases map[string][]string
+}
+
+func NewResolver(targets []Target) *Resolver {
+	r := &Resolver{
+		known:   make(map[string]bool),
+		titles:  make(map[string][]string),
+		aliases: make(map[string][]string),
+	}
+	for _, t := range targets {
+		if r.known[t.ID] {
+			continue
+		}
+		r.known[t.ID] = true
+		r.ids = append(r.ids, t.ID)
+		if t.Title != "" {
+			addName(r.titles, t.Title, t.ID)
+		}
+		for _, alias := range t.Aliases {
+			addName(r.aliases, alias, t.ID)
+		}
+	}
+	sort.Strings(r.ids)
+	return r
+}
+
+func addName(names map[string][]string, name, id string) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	for _, existing := range names[key] {
+		if existing == id {
+			return
+		}
+	}
+	names[key] = append(names[key], id)
+}
+
+// Resolve returns the ID of the note name refers to, trying in turn an
+// exact ID, the start of a single ID, and a title or alias, ignoring case.
+// A step that matches several notes is only reported if no later step
+// matches exactly one.
+func (r *Resolver) Resolve(name string) (string, error) {
+	if r.known[name] {
+		return name, nil
+	}
+
+	key := strings.ToLower(strings.TrimSpace(name))
+	steps := [][]string{r.prefixed(name), r.titles[key], r.aliases[key]}
+	var ambiguous []string
+	for _, ids := range steps {
+		switch {
+		case len(ids) == 1:
+			return ids[0], nil
+		case len(ids) > 1 && ambiguous == nil:
+			ambiguous = ids
+		}
+	}
+	sorted := append([]string(nil), ambiguous...)
+	sort.Strings(sorted)
+	return "", &ResolveError{Name: name, Candidates: sorted}
+}
+
+// ResolveLink returns l naming its note by ID. Titles and aliases may hold
+// # or ^, as in [[C# tips]], so a link with a heading or block is first
+// tried as a whole name, and only then as a note followed by a fragment.
+func (r *Resolver) ResolveLink(l Link) (Link, error) {
+	if l.Anchor != "" || l.Block != "" {
+		if id, err := r.Resolve(l.Ref()); err == nil {
+			l.ID, l.Anchor, l.Block = id, "", ""
+			return l, nil
+		}
+	}
+	id, err := r.Resolve(l.ID)
+	if err != nil {
+		return l, err
+	}
+	l.ID = id
+	return l, nil
+}
+
+// prefixed returns the IDs that start with prefix.
+func (r *Resolver) prefixed(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	i := sort.SearchStrings(r.ids, prefix)
+	var out []string
+	for ; i < len(r.ids) && strings.HasPrefix(r.ids[i], prefix); i++ {
+		out = append(out, r.ids[i])
+	}
+	return out
+}
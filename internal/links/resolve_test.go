@@ -0,0 +1,96 @@
+package links
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	r := NewResolver([]Target{
+		{ID: "go-generics-20250101000000", Title: "Go Generics", Aliases: []string{"generics", "type params"}},
+		{ID: "go-channels-20250102000000", Title: "Go Channels"},
+		{ID: "rust-traits-20250103000000", Title: "Traits", Aliases: []string{"generics"}},
+		{ID: "traits-20250104000000", Title: "Traits"},
+	})
+
+	tests := []struct {
+		name       string
+		want       string
+		candidates []string
+	}{
+		{"go-channels-20250102000000", "go-channels-20250102000000", nil},
+		{"go-gen", "go-generics-20250101000000", nil},
+		{"rust", "rust-traits-20250103000000", nil},
+		{"go channels", "go-channels-20250102000000", nil},
+		{"Type Params", "go-generics-20250101000000", nil},
+		{"go-", "", []string{"go-channels-20250102000000", "go-generics-20250101000000"}},
+		{"traits", "traits-20250104000000", nil},
+		{"Traits", "", []string{"rust-traits-20250103000000", "traits-20250104000000"}},
+		{"generics", "", []string{"go-generics-20250101000000", "rust-traits-20250103000000"}},
+		{"missing", "", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(tt.name)
+		if tt.candidates == nil {
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+			}
+			continue
+		}
+		var re *ResolveError
+		if !errors.As(err, &re) {
+			t.Errorf("Resolve(%q) = %q, %v; want a ResolveError", tt.name, got, err)
+			continue
+		}
+		if len(re.Candidates) != 0 || len(tt.candidates) != 0 {
+			if !reflect.DeepEqual(re.Candidates, tt.candidates) {
+				t.Errorf("Resolve(%q) candidates = %v, want %v", tt.name, re.Candidates, tt.candidates)
+			}
+		}
+	}
+}
+
+func TestResolveLink(t *testing.T) {
+	r := NewResolver([]Target{
+		{ID: "c-tips-20250101000000", Title: "C# tips"},
+		{ID: "x2-notes-20250102000000", Title: "x^2 notes"},
+		{ID: "design-20250103000000", Title: "Design"},
+		{ID: "c-20250104000000", Title: "C"},
+	})
+
+	tests := []struct {
+		content string
+		want    Link
+	}{
+		{"C# tips", Link{ID: "c-tips-20250101000000", Type: DefaultLinkType}},
+		{"x^2 notes|sq", Link{ID: "x2-notes-20250102000000", Type: DefaultLinkType, Label: "sq"}},
+		{"Design#Goals", Link{ID: "design-20250103000000", Type: DefaultLinkType, Anchor: "Goals"}},
+		{"part::design^q1", Link{ID: "design-20250103000000", Type: "part", Block: "q1"}},
+		{"C#Syntax", Link{ID: "c-20250104000000", Type: DefaultLinkType, Anchor: "Syntax"}},
+	}
+	for _, tt := range tests {
+		got, err := r.ResolveLink(parseLinkContent(tt.content))
+		if err != nil || got != tt.want {
+			t.Errorf("ResolveLink([[%s]]) = %+v, %v; want %+v", tt.content, got, err, tt.want)
+		}
+	}
+
+	if _, err := r.ResolveLink(parseLinkContent("missing#Goals")); err == nil {
+		t.Error("ResolveLink([[missing#Goals]]) error = nil")
+	}
+}
+
+func TestReplaceLinkRefs(t *testing.T) {
+	body := "See [[go-gen]], [[related::Traits#Bounds|the traits]], [[go-gen#^q1]], [[keep^b]] and `[[code]]`."
+	got := ReplaceLinkRefs(body, func(l Link) Link {
+		if l.ID != "keep" {
+			l.ID = "<" + l.ID + ">"
+		}
+		return l
+	})
+	want := "See [[<go-gen>]], [[related::<Traits>#Bounds|the traits]], [[<go-gen>^q1]], [[keep^b]] and `[[code]]`."
+	if got != want {
+		t.Errorf("ReplaceLinkRefs() = %q, want %q", got, want)
+	}
+}
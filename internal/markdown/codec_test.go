@@ -17,6 +17,8 @@ func TestRoundTrip(t *testing.T) {
 		Title:    "Hello World",
 		Body:     "Line 1\n\nLine 2",
 		Tags:     []string{"foo", "bar"},
+		Aliases:  []string{"Greeting", "hw"},
+		Lang:     "en-GB",
 		Created:  created,
 		Modified: modified,
 		Links: []links.Link{
@@ -50,6 +52,36 @@ title: oops
 	}
 }
 
+func TestReadRejectsInvalidLang(t *testing.T) {
+	input := []byte("---\nid: x\ntitle: t\nlang: not a tag\n---\n")
+	if _, err := Read(input); err == nil {
+		t.Fatalf("Read() error = nil, want error for invalid lang")
+	}
+}
+
+func TestRoundTripKeepsUnknownKeys(t *testing.T) {
+	input := []byte("---\nid: x\ntitle: t\nprivate: true\nsource: book\n---\n")
+	n, err := Read(input)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if n.Extra["private"] != true || n.Extra["source"] != "book" {
+		t.Fatalf("Extra = %v", n.Extra)
+	}
+
+	data, err := Write(n)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	again, err := Read(data)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if again.Extra["private"] != true || again.Extra["source"] != "book" {
+		t.Errorf("Extra after round trip = %v", again.Extra)
+	}
+}
+
 func TestWriteAddsFrontmatter(t *testing.T) {
 	n := Note{ID: "x", Title: "t", Body: "body"}
 	data, err := Write(n)
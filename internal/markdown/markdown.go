@@ -4,32 +4,45 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
-	"gopkg.in/yaml.v3"
 	"github.com/DeDude/weave2/internal/links"
+	"gopkg.in/yaml.v3"
 )
 
 type Note struct {
-	ID       string
-	Title    string
-	Body     string
-	Tags     []string
-	Type     string
+	ID    string
+	Title string
+	Body  string
+	Tags  []string
+	// Aliases are other names links may use for the note.
+	Aliases []string
+	Type    string
+	// Lang is the BCP 47 language of the note's text; empty means the
+	// vault default.
+	Lang     string
 	Created  time.Time
 	Modified time.Time
 	Links    []links.Link
+	// Extra holds the frontmatter keys weave does not know, such as
+	// private: true, so rewriting a note keeps them. RDF projections do
+	// not carry them.
+	Extra map[string]any
 }
 
 type frontmatter struct {
-	ID       string        `yaml:"id"`
-	Title    string        `yaml:"title"`
-	Tags     []string      `yaml:"tags,omitempty"`
-	Type     string        `yaml:"type,omitempty"`
-	Created  time.Time     `yaml:"created,omitempty"`
-	Modified time.Time     `yaml:"modified,omitempty"`
-	Links    []links.Link  `yaml:"links,omitempty"`
+	ID       string         `yaml:"id"`
+	Title    string         `yaml:"title"`
+	Tags     []string       `yaml:"tags,omitempty"`
+	Aliases  []string       `yaml:"aliases,omitempty"`
+	Type     string         `yaml:"type,omitempty"`
+	Lang     string         `yaml:"lang,omitempty"`
+	Created  time.Time      `yaml:"created,omitempty"`
+	Modified time.Time      `yaml:"modified,omitempty"`
+	Links    []links.Link   `yaml:"links,omitempty"`
+	Extra    map[string]any `yaml:",inline"`
 }
 
 func Write(n Note) ([]byte, error) {
@@ -37,10 +50,13 @@ func Write(n Note) ([]byte, error) {
 		ID:       n.ID,
 		Title:    n.Title,
 		Tags:     n.Tags,
+		Aliases:  n.Aliases,
 		Type:     n.Type,
+		Lang:     n.Lang,
 		Created:  n.Created,
 		Modified: n.Modified,
 		Links:    n.Links,
+		Extra:    n.Extra,
 	}
 
 	fmBytes, err := yaml.Marshal(fm)
@@ -92,15 +108,29 @@ func Read(data []byte) (Note, error) {
 		return Note{}, fmt.Errorf("unmarshal frontmatter: %w", err)
 	}
 
+	if fm.Lang != "" && !ValidLang(fm.Lang) {
+		return Note{}, fmt.Errorf("invalid lang %q", fm.Lang)
+	}
+
 	n := Note{
 		ID:       fm.ID,
 		Title:    fm.Title,
 		Tags:     fm.Tags,
+		Aliases:  fm.Aliases,
 		Type:     fm.Type,
+		Lang:     fm.Lang,
 		Created:  fm.Created,
 		Modified: fm.Modified,
 		Links:    fm.Links,
+		Extra:    fm.Extra,
 		Body:     body,
 	}
 	return n, nil
 }
+
+var langTag = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// ValidLang reports whether tag is shaped like a BCP 47 language tag.
+func ValidLang(tag string) bool {
+	return langTag.MatchString(tag)
+}
@@ -0,0 +1,181 @@
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Heading is an ATX heading in a note body.
+type Heading struct {
+	Level int
+	Text  string
+	// Anchor is the fragment that links to the heading, made unique
+	// within the body the way common renderers do.
+	Anchor string
+}
+
+// Reference is an inline link or image in a note body. Wikilinks are not
+// references; see the links package.
+type Reference struct {
+	Text  string
+	URL   string
+	Image bool
+}
+
+// Structure is the outline and references of a note body, outside code.
+type Structure struct {
+	Headings   []Heading
+	References []Reference
+	// Blocks are the IDs of lines marked ^block-id, in order.
+	Blocks []string
+}
+
+var (
+	fencePattern   = regexp.MustCompile("^ {0,3}(```|~~~)")
+	headingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	codeSpan       = regexp.MustCompile("`+[^`]*`+")
+	// Link text may hold one level of brackets, so a linked image matches
+	// as a link whose text is the image.
+	referencePattern = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\[\]]*\])*)\]\(\s*(?:<([^<>\n]*)>|([^\s()<>]+))(?:\s+(?:"[^"]*"|'[^']*'))?\s*\)`)
+	autolinkPattern  = regexp.MustCompile(`<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	blockPattern     = regexp.MustCompile(`(?:^|\s)\^([A-Za-z0-9-]+)[ \t]*$`)
+)
+
+// ParseStructure finds the headings, block IDs, links and images in body,
+// skipping fenced code blocks and code spans.
+func ParseStructure(body string) Structure {
+	var s Structure
+	anchors := make(Anchors)
+	var fence string
+
+	for _, line := range strings.Split(body, "\n") {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		if m := headingPattern.FindStringSubmatch(line); m != nil {
+			text := m[2]
+			s.Headings = append(s.Headings, Heading{
+				Level:  len(m[1]),
+				Text:   text,
+				Anchor: anchors.Next(text),
+			})
+			continue
+		}
+
+		line = codeSpan.ReplaceAllStringFunc(line, func(span string) string {
+			return strings.Repeat(" ", len(span))
+		})
+		if _, id, ok := CutBlockID(line); ok {
+			s.Blocks = append(s.Blocks, id)
+		}
+		s.References = appendReferences(s.References, line)
+	}
+	return s
+}
+
+// CutBlockID splits a trailing ^block-id marker off line, reporting
+// whether there was one.
+func CutBlockID(line string) (before, id string, found bool) {
+	m := blockPattern.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line, "", false
+	}
+	return line[:m[0]], line[m[2]:m[3]], true
+}
+
+// HasAnchor reports whether name, as written after # in a link, names a
+// heading. Headings match by text or by anchor, ignoring case.
+func (s Structure) HasAnchor(name string) bool {
+	anchor := Slug(name)
+	return slices.ContainsFunc(s.Headings, func(h Heading) bool {
+		return h.Anchor == anchor || strings.EqualFold(h.Text, name)
+	})
+}
+
+// HasBlock reports whether a line is marked ^id.
+func (s Structure) HasBlock(id string) bool {
+	return slices.Contains(s.Blocks, id)
+}
+
+func appendReferences(refs []Reference, text string) []Reference {
+	matches := referencePattern.FindAllStringSubmatchIndex(text, -1)
+	autolinks := autolinkPattern.FindAllStringSubmatchIndex(text, -1)
+
+	// Merge both kinds in order of appearance.
+	for len(matches) > 0 || len(autolinks) > 0 {
+		if len(autolinks) > 0 && (len(matches) == 0 || autolinks[0][0] < matches[0][0]) {
+			m := autolinks[0]
+			autolinks = autolinks[1:]
+			url := text[m[2]:m[3]]
+			refs = append(refs, Reference{Text: url, URL: url})
+			continue
+		}
+
+		m := matches[0]
+		matches = matches[1:]
+		for len(autolinks) > 0 && autolinks[0][0] < m[1] {
+			autolinks = autolinks[1:]
+		}
+		url := ""
+		if m[6] >= 0 {
+			url = text[m[6]:m[7]]
+		} else {
+			url = text[m[8]:m[9]]
+		}
+		label := text[m[4]:m[5]]
+		image := m[3] > m[2]
+		if !image {
+			refs = appendReferences(refs, label)
+		}
+		refs = append(refs, Reference{Text: label, URL: url, Image: image})
+	}
+	return refs
+}
+
+// Slug lower-cases text, drops punctuation and joins words with hyphens.
+func Slug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// Anchors hands out heading anchors in document order, numbering repeats
+// the way ParseStructure does, so renderers can give headings the same IDs.
+type Anchors map[string]int
+
+// Next returns the anchor for the next heading with the given text.
+func (a Anchors) Next(text string) string {
+	return uniqueAnchor(a, Slug(text))
+}
+
+func uniqueAnchor(seen map[string]int, anchor string) string {
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n == 0 && anchor != "" {
+		return anchor
+	}
+	if anchor == "" {
+		return "section-" + strconv.Itoa(n+1)
+	}
+	return anchor + "-" + strconv.Itoa(n)
+}
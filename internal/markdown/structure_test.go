@@ -0,0 +1,66 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStructureHeadings(t *testing.T) {
+	body := "# Intro\n\ntext\n\n## Set up ##\n\n```\n# not a heading\n```\n\n## Set up\n\n#tag line\n\n### C# and F#!\n"
+	want := []Heading{
+		{Level: 1, Text: "Intro", Anchor: "intro"},
+		{Level: 2, Text: "Set up", Anchor: "set-up"},
+		{Level: 2, Text: "Set up", Anchor: "set-up-1"},
+		{Level: 3, Text: "C# and F#!", Anchor: "c-and-f"},
+	}
+	if got := ParseStructure(body).Headings; !reflect.DeepEqual(got, want) {
+		t.Errorf("Headings = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStructureReferences(t *testing.T) {
+	body := "See [the spec](https://www.w3.org/TR/rdf11-concepts/ \"RDF\") and <https://example.org/a>.\n" +
+		"![diagram](img/d.png) [![badge](https://example.org/b.svg)](https://example.org/ci)\n" +
+		"`[code](https://example.org/no)` [[wiki]] [local](notes.md)\n" +
+		"~~~\n[fenced](https://example.org/no)\n~~~\n"
+	want := []Reference{
+		{Text: "the spec", URL: "https://www.w3.org/TR/rdf11-concepts/"},
+		{Text: "https://example.org/a", URL: "https://example.org/a"},
+		{Text: "diagram", URL: "img/d.png", Image: true},
+		{Text: "badge", URL: "https://example.org/b.svg", Image: true},
+		{Text: "![badge](https://example.org/b.svg)", URL: "https://example.org/ci"},
+		{Text: "local", URL: "notes.md"},
+	}
+	if got := ParseStructure(body).References; !reflect.DeepEqual(got, want) {
+		t.Errorf("References = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseStructureBlocks(t *testing.T) {
+	body := "# Design Notes\n\nA quoted claim. ^claim-1\n\n- item\n\n^list\n\n`x ^code`\n```\nfenced ^no\n```\nnot^inline\n"
+	s := ParseStructure(body)
+	if want := []string{"claim-1", "list"}; !reflect.DeepEqual(s.Blocks, want) {
+		t.Errorf("Blocks = %v, want %v", s.Blocks, want)
+	}
+	if !s.HasBlock("list") || s.HasBlock("no") {
+		t.Errorf("HasBlock() disagrees with Blocks %v", s.Blocks)
+	}
+	for _, name := range []string{"Design Notes", "design notes", "design-notes"} {
+		if !s.HasAnchor(name) {
+			t.Errorf("HasAnchor(%q) = false", name)
+		}
+	}
+	if s.HasAnchor("Design") {
+		t.Error(`HasAnchor("Design") = true`)
+	}
+}
+
+func TestCutBlockID(t *testing.T) {
+	before, id, ok := CutBlockID("A claim. ^claim-1 ")
+	if !ok || before != "A claim." || id != "claim-1" {
+		t.Errorf("CutBlockID() = %q, %q, %v", before, id, ok)
+	}
+	if _, _, ok := CutBlockID("x^2"); ok {
+		t.Error(`CutBlockID("x^2") found a block`)
+	}
+}
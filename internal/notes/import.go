@@ -0,0 +1,137 @@
+package notes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+type ImportAction int
+
+const (
+	ImportCreated ImportAction = iota
+	ImportUpdated
+	ImportUnchanged
+	// ImportConflict means the vault copy was not overwritten because it
+	// is as new as or newer than the import and differs from it.
+	ImportConflict
+)
+
+func (a ImportAction) String() string {
+	switch a {
+	case ImportCreated:
+		return "created"
+	case ImportUpdated:
+		return "updated"
+	case ImportUnchanged:
+		return "unchanged"
+	case ImportConflict:
+		return "conflict"
+	}
+	return fmt.Sprintf("ImportAction(%d)", int(a))
+}
+
+var importIDPattern = regexp.MustCompile(`^([a-z0-9-]+-)?\d{14}$`)
+
+// ValidID reports whether id has the shape GenerateID produces: an
+// optional lower-case slug followed by a 14-digit timestamp.
+func ValidID(id string) bool {
+	return importIDPattern.MatchString(id)
+}
+
+// Import writes a note that already carries its ID, such as one recovered
+// from an RDF export. An existing note is only replaced by a strictly newer
+// import unless force is set.
+func Import(vaultPath string, note markdown.Note, force bool) (ImportAction, error) {
+	if !ValidID(note.ID) {
+		return 0, fmt.Errorf("invalid ID %q", note.ID)
+	}
+	filePath, err := ResolvePath(vaultPath, note.ID)
+	if err != nil {
+		return 0, fmt.Errorf("resolve path: %w", err)
+	}
+
+	action := ImportCreated
+	existing, err := Read(vaultPath, note.ID)
+	switch {
+	case err == nil:
+		switch {
+		case sameNote(existing, note):
+			return ImportUnchanged, nil
+		case !force && !truncate(note.Modified).After(truncate(existing.Modified)):
+			return ImportConflict, nil
+		}
+		action = ImportUpdated
+		if note.Extra == nil {
+			// The projection does not carry unknown frontmatter keys.
+			note.Extra = existing.Extra
+		}
+	case errors.Is(err, fs.ErrNotExist):
+	default:
+		return 0, err
+	}
+
+	if note.Type == "" {
+		note.Type = "Note"
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return 0, fmt.Errorf("create directories: %w", err)
+	}
+	data, err := markdown.Write(note)
+	if err != nil {
+		return 0, fmt.Errorf("write markdown: %w", err)
+	}
+	if err := safeWrite(filePath, data); err != nil {
+		return 0, err
+	}
+	return action, nil
+}
+
+// truncate drops sub-second precision, which RDF timestamps do not carry.
+func truncate(t time.Time) time.Time {
+	return t.Truncate(time.Second)
+}
+
+// sameNote compares notes the way the RDF projection sees them: tag, alias
+// and link order, sub-second timestamps and unknown frontmatter keys do not
+// count.
+func sameNote(a, b markdown.Note) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(n markdown.Note) markdown.Note {
+	n.Created = truncate(n.Created).UTC()
+	n.Modified = truncate(n.Modified).UTC()
+	if n.Type == "" {
+		n.Type = "Note"
+	}
+	n.Tags = slices.Sorted(slices.Values(n.Tags))
+	n.Aliases = slices.Sorted(slices.Values(n.Aliases))
+	n.Extra = nil
+	n.Links = slices.SortedFunc(slices.Values(n.Links), func(x, y links.Link) int {
+		if c := strings.Compare(x.ID, y.ID); c != 0 {
+			return c
+		}
+		if c := strings.Compare(x.Type, y.Type); c != 0 {
+			return c
+		}
+		if c := strings.Compare(x.Label, y.Label); c != 0 {
+			return c
+		}
+		if c := strings.Compare(x.Anchor, y.Anchor); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Block, y.Block)
+	})
+	return n
+}
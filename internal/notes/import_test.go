@@ -0,0 +1,103 @@
+package notes
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestImport(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	note := markdown.Note{ID: "a-20250101000000", Title: "A", Created: ts, Modified: ts}
+
+	steps := []struct {
+		name  string
+		note  func(markdown.Note) markdown.Note
+		force bool
+		want  ImportAction
+	}{
+		{"new", func(n markdown.Note) markdown.Note { return n }, false, ImportCreated},
+		{"same", func(n markdown.Note) markdown.Note { return n }, false, ImportUnchanged},
+		{"same revision edited", func(n markdown.Note) markdown.Note { n.Body = "x"; return n }, false, ImportConflict},
+		{"forced", func(n markdown.Note) markdown.Note { n.Body = "x"; return n }, true, ImportUpdated},
+		{"newer", func(n markdown.Note) markdown.Note { n.Body = "y"; n.Modified = ts.Add(time.Minute); return n }, false, ImportUpdated},
+		{"older", func(n markdown.Note) markdown.Note { n.Body = "z"; return n }, false, ImportConflict},
+	}
+	for _, step := range steps {
+		got, err := Import(vault, step.note(note), step.force)
+		if err != nil {
+			t.Fatalf("%s: Import() error = %v", step.name, err)
+		}
+		if got != step.want {
+			t.Errorf("%s: Import() = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	read, err := Read(vault, note.ID)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if read.Body != "y" {
+		t.Errorf("Body = %q, want the newer import", read.Body)
+	}
+}
+
+func TestImportIgnoresLinkOrder(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	note := markdown.Note{ID: "a-20250101000000", Title: "A", Created: ts, Modified: ts, Links: []links.Link{
+		{ID: "b", Type: "linksTo", Anchor: "Design"},
+		{ID: "b", Type: "linksTo", Anchor: "Plan"},
+		{ID: "b", Type: "linksTo", Block: "q1"},
+	}}
+	if _, err := Import(vault, note, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	note.Links = slices.Clone(note.Links)
+	slices.Reverse(note.Links)
+	if got, err := Import(vault, note, false); err != nil || got != ImportUnchanged {
+		t.Errorf("Import() with links reordered = %v, %v, want %v", got, err, ImportUnchanged)
+	}
+}
+
+func TestImportRejectsUnsafeIDs(t *testing.T) {
+	vault := t.TempDir()
+	for _, id := range []string{"", "../../etc/x-20250101000000", "a/b-20250101000000", "no-timestamp"} {
+		if _, err := Import(vault, markdown.Note{ID: id, Title: "x"}, false); err == nil {
+			t.Errorf("Import(%q) error = nil, want error", id)
+		}
+	}
+}
+
+func TestImportKeepsUnknownFrontmatter(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	note := markdown.Note{ID: "a-20250101000000", Title: "A", Created: ts, Modified: ts, Extra: map[string]any{"private": true}}
+	if _, err := Import(vault, note, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	// A note read back from RDF has no unknown keys.
+	note.Extra = nil
+	if got, err := Import(vault, note, false); err != nil || got != ImportUnchanged {
+		t.Fatalf("Import() = %v, %v; want unchanged", got, err)
+	}
+	note.Title = "B"
+	note.Modified = ts.Add(time.Minute)
+	if _, err := Import(vault, note, false); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	read, err := Read(vault, note.ID)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if read.Title != "B" || read.Extra["private"] != true {
+		t.Errorf("note = %+v, want the new title and private kept", read)
+	}
+}
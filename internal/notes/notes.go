@@ -1,6 +1,7 @@
 package notes
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -45,6 +46,9 @@ func ResolvePath(vaultPath, id string) (string, error) {
 	if len(id) < 14 {
 		return "", fmt.Errorf("invalid ID: must be at least 14 characters, got %d", len(id))
 	}
+	if strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return "", fmt.Errorf("invalid ID %q", id)
+	}
 
 	timestamp := id[len(id)-14:]
 
@@ -148,58 +152,33 @@ func Delete(vaultPath, id string) error {
 
 func safeWrite(filePath string, data []byte) error {
 	tempPath := filePath + ".tmp"
-	
+
 	if err := os.WriteFile(tempPath, data, 0644); err != nil {
 		return fmt.Errorf("write temp file: %w", err)
 	}
-	
+
 	if err := os.Rename(tempPath, filePath); err != nil {
 		os.Remove(tempPath)
 		return fmt.Errorf("rename file: %w", err)
 	}
-	
+
 	return nil
 }
 
-func List(vaultPath string) ([]markdown.Note, []error) {
-	var notes []markdown.Note
-	var errors []error
-
-	err := filepath.Walk(vaultPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			errors = append(errors, fmt.Errorf("%s: %w", path, err))
-			return nil
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		if filepath.Ext(path) != ".md" {
-			return nil
-		}
-
-		data, err := os.ReadFile(path)
-
-		if err != nil {
-			errors = append(errors, fmt.Errorf("%s: read failed: %w", path, err))
-			return nil
-		}
-
-		note, err := markdown.Read(data)
-
-		if err != nil {
-			errors = append(errors, fmt.Errorf("%s: parse failed: %w", path, err))
-			return nil
-		}
+// LoadError records a vault file that could not be loaded and why.
+type LoadError struct {
+	Path string
+	Err  error
+}
 
-		notes = append(notes, note)
-		return nil
-	})
+func (e *LoadError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
 
-	if err != nil {
-		errors = append(errors, fmt.Errorf("walk vault: %w", err))
-	}
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
 
-	return notes, errors
+func List(vaultPath string) ([]markdown.Note, []error) {
+	return ListContext(context.Background(), vaultPath)
 }
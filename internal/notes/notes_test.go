@@ -1,6 +1,7 @@
 package notes
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -108,6 +109,14 @@ func TestResolvePathInvalidID(t *testing.T) {
 	}
 }
 
+func TestResolvePathRejectsTraversal(t *testing.T) {
+	for _, id := range []string{"../../etc/x-20250101000000", `..\x-20250101000000`, "a..b-20250101000000"} {
+		if _, err := ResolvePath("/vault", id); err == nil {
+			t.Errorf("ResolvePath(%q) error = nil, want error", id)
+		}
+	}
+}
+
 func TestCreate(t *testing.T) {
 	vaultPath := t.TempDir()
 
@@ -334,9 +343,9 @@ func TestList(t *testing.T) {
 	}
 
 	loaded, errs := List(vaultPath)
-	
+
 	if len(errs) > 0 {
-		t.Fatalf("List() returned errors: %v", errs)	
+		t.Fatalf("List() returned errors: %v", errs)
 	}
 
 	if len(loaded) != 3 {
@@ -353,13 +362,13 @@ func TestList(t *testing.T) {
 	}
 }
 
-func TestListEmpty(t *testing.T){
+func TestListEmpty(t *testing.T) {
 	vaultPath := t.TempDir()
 
 	loaded, errs := List(vaultPath)
-	
+
 	if len(errs) > 0 {
-		t.Fatalf("List() returned errors: %v", errs)	
+		t.Fatalf("List() returned errors: %v", errs)
 	}
 
 	if len(loaded) != 0 {
@@ -369,35 +378,63 @@ func TestListEmpty(t *testing.T){
 
 func TestListSkipsBadFiles(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note := markdown.Note{
 		Title: "Good Note",
 		Body:  "Valid content",
 	}
-	
+
 	ts := time.Date(2025, 1, 22, 10, 0, 0, 0, time.UTC)
 	_, err := Create(vaultPath, note, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
+
 	badFilePath := vaultPath + "/2025/01/bad-file.md"
 	err = os.WriteFile(badFilePath, []byte("not valid markdown"), 0644)
 	if err != nil {
 		t.Fatalf("WriteFile() error = %v", err)
 	}
-	
+
 	loaded, errs := List(vaultPath)
-	
+
 	if len(errs) == 0 {
 		t.Fatal("List() returned no errors, expected error for bad file")
 	}
-	
+
 	if len(loaded) != 1 {
 		t.Fatalf("List() returned %d notes, want 1 (should skip bad file)", len(loaded))
 	}
-	
+
 	if loaded[0].Title != "Good Note" {
 		t.Errorf("loaded note title = %q, want %q", loaded[0].Title, "Good Note")
 	}
 }
+
+func TestListReportsLoadErrorPath(t *testing.T) {
+	vaultPath := t.TempDir()
+
+	if err := os.MkdirAll(vaultPath+"/2025/01", 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	badFilePath := vaultPath + "/2025/01/bad-file.md"
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, errs := List(vaultPath)
+
+	if len(errs) != 1 {
+		t.Fatalf("List() returned %d errors, want 1", len(errs))
+	}
+
+	var loadErr *LoadError
+	if !errors.As(errs[0], &loadErr) {
+		t.Fatalf("List() error = %T, want *LoadError", errs[0])
+	}
+
+	if loadErr.Path != badFilePath {
+		t.Errorf("LoadError.Path = %q, want %q", loadErr.Path, badFilePath)
+	}
+}
@@ -0,0 +1,56 @@
+package notes
+
+import (
+	"slices"
+	"strings"
+)
+
+// HasTag reports whether tags holds tag or a tag nested under it, so
+// lang/go matches lang/go/generics but not lang/gopher.
+func HasTag(tags []string, tag string) bool {
+	tag = strings.Trim(tag, "/")
+	for _, t := range tags {
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameTag replaces from, and every tag nested under it, with to, keeping
+// the nesting: renaming lang/go to golang turns lang/go/generics into
+// golang/generics. Tags that end up the same are kept once.
+func RenameTag(tags []string, from, to string) []string {
+	from = strings.Trim(from, "/")
+	to = strings.Trim(to, "/")
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t == from {
+			t = to
+		} else if rest, ok := strings.CutPrefix(t, from+"/"); ok {
+			t = to + "/" + rest
+		}
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// AddTags appends the given tags that tags lacks.
+func AddTags(tags []string, add ...string) []string {
+	out := slices.Clone(tags)
+	for _, t := range add {
+		if !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// RemoveTags drops the given tags. Tags nested under them are kept.
+func RemoveTags(tags []string, remove ...string) []string {
+	return slices.DeleteFunc(slices.Clone(tags), func(t string) bool {
+		return slices.Contains(remove, t)
+	})
+}
@@ -0,0 +1,50 @@
+package notes
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHasTag(t *testing.T) {
+	tags := []string{"lang/go/generics", "rdf"}
+	tests := map[string]bool{
+		"lang":             true,
+		"lang/go":          true,
+		"lang/go/":         true,
+		"lang/go/generics": true,
+		"lang/gopher":      false,
+		"lang/go/gen":      false,
+		"rdf":              true,
+		"rd":               false,
+	}
+	for tag, want := range tests {
+		if got := HasTag(tags, tag); got != want {
+			t.Errorf("HasTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tags := []string{"lang/go", "lang/go/generics", "lang/gopher", "golang"}
+	got := RenameTag(tags, "lang/go", "golang")
+	want := []string{"golang", "golang/generics", "lang/gopher"}
+	if !slices.Equal(got, want) {
+		t.Errorf("RenameTag() = %v, want %v", got, want)
+	}
+	if !slices.Equal(tags, []string{"lang/go", "lang/go/generics", "lang/gopher", "golang"}) {
+		t.Errorf("RenameTag() modified its input: %v", tags)
+	}
+}
+
+func TestAddAndRemoveTags(t *testing.T) {
+	tags := []string{"a", "a/b"}
+	if got := AddTags(tags, "c", "a"); !slices.Equal(got, []string{"a", "a/b", "c"}) {
+		t.Errorf("AddTags() = %v", got)
+	}
+	if got := RemoveTags(tags, "a"); !slices.Equal(got, []string{"a/b"}) {
+		t.Errorf("RemoveTags() = %v", got)
+	}
+	if !slices.Equal(tags, []string{"a", "a/b"}) {
+		t.Errorf("input modified: %v", tags)
+	}
+}
@@ -0,0 +1,249 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+// Entry is a note loaded from the vault together with the file it came from.
+type Entry struct {
+	Path string
+	Note markdown.Note
+}
+
+// Filter narrows a walk to part of the vault. Zero fields match everything.
+// Year and Month select the notes/<year>/<month> directories without reading
+// the rest of the tree; Type and Tag are checked after a note is parsed.
+// Tag also matches tags nested under it.
+type Filter struct {
+	Year  int
+	Month int
+	Type  string
+	Tag   string
+}
+
+func (f Filter) root(vaultPath string) string {
+	if f.Year == 0 {
+		return vaultPath
+	}
+	root := filepath.Join(vaultPath, fmt.Sprintf("%04d", f.Year))
+	if f.Month != 0 {
+		root = filepath.Join(root, fmt.Sprintf("%02d", f.Month))
+	}
+	return root
+}
+
+// Match reports whether an already loaded entry passes the filter, using the
+// entry's directory for Year and Month.
+func (f Filter) Match(entry Entry) bool {
+	dir := filepath.Dir(entry.Path)
+	if f.Month != 0 && filepath.Base(dir) != fmt.Sprintf("%02d", f.Month) {
+		return false
+	}
+	if f.Year != 0 && filepath.Base(filepath.Dir(dir)) != fmt.Sprintf("%04d", f.Year) {
+		return false
+	}
+	return f.matches(entry.Note)
+}
+
+func (f Filter) matches(note markdown.Note) bool {
+	if f.Type != "" {
+		noteType := note.Type
+		if noteType == "" {
+			noteType = "Note"
+		}
+		if noteType != f.Type {
+			return false
+		}
+	}
+	if f.Tag != "" && !HasTag(note.Tags, f.Tag) {
+		return false
+	}
+	return true
+}
+
+// WalkFunc is called once per vault file in path order. err is a *LoadError
+// when the file could not be loaded; returning a non-nil error stops the walk.
+type WalkFunc func(entry Entry, err error) error
+
+var loadWorkers = runtime.GOMAXPROCS(0)
+
+type loadResult struct {
+	entry Entry
+	err   error
+	skip  bool
+}
+
+var errStopIteration = errors.New("stop iteration")
+
+// Walk reads and parses the vault's notes on a bounded pool of workers and
+// hands them to fn in deterministic path order. Only a small window of
+// parsed notes is held in memory at a time.
+func Walk(ctx context.Context, vaultPath string, fn WalkFunc) error {
+	return walk(ctx, vaultPath, Filter{}, fn)
+}
+
+// All returns an iterator over the vault's notes in path order, restricted
+// by filter. Load failures are yielded as *LoadError alongside the file's
+// path; breaking out of the loop stops loading.
+func All(ctx context.Context, vaultPath string, filter Filter) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		err := walk(ctx, vaultPath, filter, func(entry Entry, err error) error {
+			if !yield(entry, err) {
+				return errStopIteration
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(Entry{Path: vaultPath}, &LoadError{Path: vaultPath, Err: fmt.Errorf("walk vault: %w", err)})
+		}
+	}
+}
+
+func walk(ctx context.Context, vaultPath string, filter Filter, fn WalkFunc) error {
+	paths, walkErrs, err := collectPaths(filter.root(vaultPath), filter.Year != 0)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := loadWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	slots := make([]chan loadResult, len(paths))
+	for i := range slots {
+		slots[i] = make(chan loadResult, 1)
+	}
+
+	// window bounds how far the workers may run ahead of fn.
+	window := make(chan struct{}, workers*2)
+
+	go func() {
+		defer close(jobs)
+		for i := range paths {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				if err, ok := walkErrs[paths[i]]; ok {
+					slots[i] <- loadResult{entry: Entry{Path: paths[i]}, err: err}
+					continue
+				}
+				res := loadFile(paths[i])
+				if res.err == nil && !filter.matches(res.entry.Note) {
+					res.skip = true
+				}
+				slots[i] <- res
+			}
+		}()
+	}
+
+	for i := range paths {
+		var res loadResult
+		select {
+		case res = <-slots[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-window
+
+		if res.skip {
+			continue
+		}
+		if err := fn(res.entry, res.err); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+// ListContext is List with cancellation.
+func ListContext(ctx context.Context, vaultPath string) ([]markdown.Note, []error) {
+	var notes []markdown.Note
+	var errs []error
+
+	for entry, err := range All(ctx, vaultPath, Filter{}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		notes = append(notes, entry.Note)
+	}
+
+	return notes, errs
+}
+
+func collectPaths(root string, optional bool) ([]string, map[string]error, error) {
+	var paths []string
+	walkErrs := make(map[string]error)
+
+	if optional {
+		if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+			return nil, walkErrs, nil
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			paths = append(paths, path)
+			walkErrs[path] = &LoadError{Path: path, Err: err}
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("walk vault: %w", err)
+	}
+
+	return paths, walkErrs, nil
+}
+
+func loadFile(path string) loadResult {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return loadResult{entry: Entry{Path: path}, err: &LoadError{Path: path, Err: fmt.Errorf("read failed: %w", err)}}
+	}
+
+	note, err := markdown.Read(data)
+	if err != nil {
+		return loadResult{entry: Entry{Path: path}, err: &LoadError{Path: path, Err: fmt.Errorf("parse failed: %w", err)}}
+	}
+
+	return loadResult{entry: Entry{Path: path, Note: note}}
+}
@@ -0,0 +1,178 @@
+package notes
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func createNotes(t *testing.T, vaultPath string, count int) {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		ts := base.AddDate(0, 0, i*7)
+		if _, err := Create(vaultPath, markdown.Note{Title: "Note"}, ts); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+func TestWalkPathOrder(t *testing.T) {
+	vaultPath := t.TempDir()
+	createNotes(t, vaultPath, 20)
+
+	var paths []string
+	err := Walk(context.Background(), vaultPath, func(entry Entry, err error) error {
+		if err != nil {
+			t.Fatalf("Walk() entry error = %v", err)
+		}
+		paths = append(paths, entry.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	if len(paths) != 20 {
+		t.Fatalf("Walk() visited %d notes, want 20", len(paths))
+	}
+	if !sort.StringsAreSorted(paths) {
+		t.Errorf("Walk() paths not in order: %v", paths)
+	}
+}
+
+func TestWalkEarlyStop(t *testing.T) {
+	vaultPath := t.TempDir()
+	createNotes(t, vaultPath, 10)
+
+	stop := errors.New("stop")
+	visited := 0
+	err := Walk(context.Background(), vaultPath, func(entry Entry, err error) error {
+		visited++
+		if visited == 3 {
+			return stop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, stop) {
+		t.Fatalf("Walk() error = %v, want %v", err, stop)
+	}
+	if visited != 3 {
+		t.Errorf("Walk() visited %d notes, want 3", visited)
+	}
+}
+
+func TestWalkCancelled(t *testing.T) {
+	vaultPath := t.TempDir()
+	createNotes(t, vaultPath, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Walk(ctx, vaultPath, func(entry Entry, err error) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Walk() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestWalkReportsBadFiles(t *testing.T) {
+	vaultPath := t.TempDir()
+	createNotes(t, vaultPath, 1)
+
+	badFilePath := vaultPath + "/2025/01/bad-file.md"
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	var loaded, failed int
+	err := Walk(context.Background(), vaultPath, func(entry Entry, err error) error {
+		if err != nil {
+			failed++
+			if entry.Path != badFilePath {
+				t.Errorf("failed entry path = %q, want %q", entry.Path, badFilePath)
+			}
+			return nil
+		}
+		loaded++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	if loaded != 1 || failed != 1 {
+		t.Errorf("Walk() loaded=%d failed=%d, want 1 and 1", loaded, failed)
+	}
+}
+
+func TestAllFilters(t *testing.T) {
+	vaultPath := t.TempDir()
+
+	fixtures := []struct {
+		note markdown.Note
+		ts   time.Time
+	}{
+		{markdown.Note{Title: "Jan Go", Tags: []string{"go"}}, time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{markdown.Note{Title: "Jan Rdf", Tags: []string{"rdf"}, Type: "Decision"}, time.Date(2025, 1, 11, 0, 0, 0, 0, time.UTC)},
+		{markdown.Note{Title: "Feb Go", Tags: []string{"go"}}, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{markdown.Note{Title: "Old Go", Tags: []string{"go"}}, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, f := range fixtures {
+		if _, err := Create(vaultPath, f.note, f.ts); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"none", Filter{}, []string{"Old Go", "Jan Go", "Jan Rdf", "Feb Go"}},
+		{"year", Filter{Year: 2025}, []string{"Jan Go", "Jan Rdf", "Feb Go"}},
+		{"month", Filter{Year: 2025, Month: 1}, []string{"Jan Go", "Jan Rdf"}},
+		{"tag", Filter{Tag: "go"}, []string{"Old Go", "Jan Go", "Feb Go"}},
+		{"type", Filter{Type: "Decision"}, []string{"Jan Rdf"}},
+		{"missing month", Filter{Year: 2025, Month: 6}, nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for entry, err := range All(context.Background(), vaultPath, tt.filter) {
+			if err != nil {
+				t.Fatalf("%s: All() error = %v", tt.name, err)
+			}
+			got = append(got, entry.Note.Title)
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: All() titles = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllBreak(t *testing.T) {
+	vaultPath := t.TempDir()
+	createNotes(t, vaultPath, 10)
+
+	visited := 0
+	for range All(context.Background(), vaultPath, Filter{}) {
+		visited++
+		if visited == 2 {
+			break
+		}
+	}
+
+	if visited != 2 {
+		t.Errorf("All() visited %d notes, want 2", visited)
+	}
+}
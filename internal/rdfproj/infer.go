@@ -0,0 +1,61 @@
+package rdfproj
+
+import (
+	"sort"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+// InferredGraph names the graph that holds inferred triples, keeping them
+// apart from what the notes actually assert.
+func (p *Projector) InferredGraph() string {
+	return p.baseURI + "/graphs/inferred"
+}
+
+// Infer materializes the links implied by the vocabulary's inverse and
+// symmetric declarations: skos:narrower for every skos:broader, skos:related
+// in both directions, and any configured pairs. Only triples between
+// resources are considered, and nothing already in triples is repeated.
+// The result is sorted so repeated runs agree.
+func (p *Projector) Infer(triples []*rdf.Triple) []*rdf.Triple {
+	asserted := make(map[string]bool, len(triples))
+	for _, t := range triples {
+		asserted[t.String()] = true
+	}
+
+	var inferred []*rdf.Triple
+	add := func(s, pred, o rdf.Term) {
+		t := rdf.NewTriple(s, pred, o)
+		key := t.String()
+		if asserted[key] {
+			return
+		}
+		asserted[key] = true
+		inferred = append(inferred, t)
+	}
+
+	for _, t := range triples {
+		if _, ok := t.Subject.(*rdf.Resource); !ok {
+			continue
+		}
+		if _, ok := t.Object.(*rdf.Resource); !ok {
+			continue
+		}
+		pred := t.Predicate.RawValue()
+		if inv, ok := p.vocab.Inverse(pred); ok {
+			add(t.Object, rdf.NewResource(inv), t.Subject)
+		}
+		if p.vocab.Symmetric(pred) {
+			add(t.Object, t.Predicate, t.Subject)
+		}
+	}
+
+	sortTriples(inferred)
+	return inferred
+}
+
+func sortTriples(triples []*rdf.Triple) {
+	sort.Slice(triples, func(i, j int) bool {
+		return triples[i].String() < triples[j].String()
+	})
+}
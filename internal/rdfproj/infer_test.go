@@ -0,0 +1,49 @@
+package rdfproj
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestInfer(t *testing.T) {
+	v, err := NewVocabulary(VocabularySpec{
+		Relations: map[string]string{"cites": "http://purl.org/spar/cito/cites", "citedBy": "http://purl.org/spar/cito/isCitedBy"},
+		Inverses:  map[string]string{"cites": "citedBy"},
+	})
+	if err != nil {
+		t.Fatalf("NewVocabulary() error = %v", err)
+	}
+	p := New("http://example.org", v)
+
+	notes := []markdown.Note{
+		{ID: "a", Title: "A", Tags: []string{"go"}, Links: []links.Link{
+			{ID: "b", Type: "broader", Label: "parent"},
+			{ID: "c", Type: "related"},
+			{ID: "d", Type: "cites"},
+			{ID: "e", Type: "linksTo"},
+		}},
+		// Already asserted in both directions, so nothing to infer.
+		{ID: "c", Title: "C", Links: []links.Link{{ID: "a", Type: "related"}}},
+	}
+
+	var got []string
+	for _, triple := range p.Infer(p.VaultToTriples(notes)) {
+		got = append(got, triple.String())
+	}
+	want := []string{
+		`<http://example.org/notes/b> <http://www.w3.org/2004/02/skos/core#narrower> <http://example.org/notes/a> .`,
+		`<http://example.org/notes/d> <http://purl.org/spar/cito/isCitedBy> <http://example.org/notes/a> .`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Infer() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestInferredGraph(t *testing.T) {
+	if got := New("http://example.org", nil).InferredGraph(); got != "http://example.org/graphs/inferred" {
+		t.Errorf("InferredGraph() = %q", got)
+	}
+}
@@ -0,0 +1,189 @@
+package rdfproj
+
+import (
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	ontologyIRI       = "http://weave.dev/vocab"
+	owlOntology       = "http://www.w3.org/2002/07/owl#Ontology"
+	owlClass          = "http://www.w3.org/2002/07/owl#Class"
+	owlObjectProperty = "http://www.w3.org/2002/07/owl#ObjectProperty"
+	owlDatatypeProp   = "http://www.w3.org/2002/07/owl#DatatypeProperty"
+	owlSymmetric      = "http://www.w3.org/2002/07/owl#SymmetricProperty"
+	owlInverseOf      = "http://www.w3.org/2002/07/owl#inverseOf"
+	rdfProperty       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Property"
+	rdfsSubClassOf    = "http://www.w3.org/2000/01/rdf-schema#subClassOf"
+	rdfsSubPropertyOf = "http://www.w3.org/2000/01/rdf-schema#subPropertyOf"
+	rdfsDomain        = "http://www.w3.org/2000/01/rdf-schema#domain"
+	rdfsRange         = "http://www.w3.org/2000/01/rdf-schema#range"
+	rdfsIsDefinedBy   = "http://www.w3.org/2000/01/rdf-schema#isDefinedBy"
+	rdfsSeeAlso       = "http://www.w3.org/2000/01/rdf-schema#seeAlso"
+	rdfsResource      = "http://www.w3.org/2000/01/rdf-schema#Resource"
+	skosNamespace     = "http://www.w3.org/2004/02/skos/core#"
+	weaveNote         = weaveVocab + "Note"
+	weaveLinksTo      = weaveVocab + "linksTo"
+)
+
+// Ontology declares every class and property used in the given projected
+// triples. Terms in the weave namespace are fully described, with labels,
+// domains, ranges and their place in the hierarchy; terms borrowed from
+// other vocabularies are only typed, plus any inverse or symmetric
+// declarations from the vocabulary.
+func (p *Projector) Ontology(triples iter.Seq[*rdf.Triple]) []*rdf.Triple {
+	classes := make(map[string]bool)
+	objectProps := make(map[string]bool)
+	datatypeProps := make(map[string]bool)
+
+	for t := range triples {
+		pred := t.Predicate.RawValue()
+		if pred == rdfType {
+			classes[t.Object.RawValue()] = true
+			continue
+		}
+		if _, ok := t.Object.(*rdf.Literal); ok {
+			datatypeProps[pred] = true
+		} else {
+			objectProps[pred] = true
+		}
+	}
+
+	o := &ontology{}
+	o.add(ontologyIRI, rdfType, owlOntology)
+	o.label(ontologyIRI, "weave vocabulary")
+
+	for _, class := range sortedKeys(classes) {
+		if definedElsewhere(class) {
+			continue
+		}
+		o.add(class, rdfType, owlClass)
+		if !isWeaveTerm(class) {
+			continue
+		}
+		o.describe(class)
+		if class != weaveNote && class != weaveLink {
+			o.add(class, rdfsSubClassOf, weaveNote)
+		}
+		for _, super := range p.superClasses(class) {
+			o.add(class, rdfsSubClassOf, super)
+		}
+	}
+
+	for _, prop := range sortedKeys(objectProps) {
+		if !definedElsewhere(prop) {
+			o.add(prop, rdfType, owlObjectProperty)
+		}
+		if prop == weaveSource || prop == weaveTarget || prop == weaveRelation {
+			o.describe(prop)
+			o.add(prop, rdfsDomain, weaveLink)
+			switch prop {
+			case weaveRelation:
+				o.add(prop, rdfsRange, rdfProperty)
+			case weaveSource:
+				o.add(prop, rdfsRange, weaveNote)
+			default:
+				o.add(prop, rdfsRange, rdfsResource)
+			}
+			continue
+		}
+		if _, isLink := p.vocab.RelationType(prop); !isLink {
+			continue
+		}
+		if inv, ok := p.vocab.Inverse(prop); ok {
+			o.add(prop, owlInverseOf, inv)
+		}
+		if p.vocab.Symmetric(prop) {
+			o.add(prop, rdfType, owlSymmetric)
+		}
+		if !isWeaveTerm(prop) {
+			continue
+		}
+		o.describe(prop)
+		o.add(prop, rdfsDomain, weaveNote)
+		// Links to a heading or block point at a section or fragment of
+		// the note, not at a note.
+		o.add(prop, rdfsRange, rdfsResource)
+		if prop == weaveLinksTo {
+			o.add(prop, rdfsSubPropertyOf, rdfsSeeAlso)
+		} else {
+			o.add(prop, rdfsSubPropertyOf, weaveLinksTo)
+		}
+	}
+
+	for _, prop := range sortedKeys(datatypeProps) {
+		if objectProps[prop] || definedElsewhere(prop) {
+			continue
+		}
+		o.add(prop, rdfType, owlDatatypeProp)
+		if isWeaveTerm(prop) {
+			o.describe(prop)
+		}
+	}
+
+	return o.triples
+}
+
+// superClasses returns the other classes a weave class is always paired
+// with by the vocabulary, such as schema:Article for weave:Note.
+func (p *Projector) superClasses(class string) []string {
+	var supers []string
+	for _, classes := range p.vocab.types {
+		if !slices.Contains(classes, class) {
+			continue
+		}
+		for _, other := range classes {
+			if other != class && !slices.Contains(supers, other) {
+				supers = append(supers, other)
+			}
+		}
+	}
+	sort.Strings(supers)
+	return supers
+}
+
+type ontology struct {
+	triples []*rdf.Triple
+}
+
+func (o *ontology) add(s, p, obj string) {
+	o.triples = append(o.triples, rdf.NewTriple(rdf.NewResource(s), rdf.NewResource(p), rdf.NewResource(obj)))
+}
+
+func (o *ontology) label(s, label string) {
+	o.triples = append(o.triples, rdf.NewTriple(rdf.NewResource(s), rdf.NewResource(rdfsLabel), rdf.NewLiteralWithLanguage(label, "en")))
+}
+
+// describe labels a weave term and ties it to the ontology.
+func (o *ontology) describe(term string) {
+	o.label(term, humanize(strings.TrimPrefix(term, weaveVocab)))
+	o.add(term, rdfsIsDefinedBy, ontologyIRI)
+}
+
+// definedElsewhere reports whether a term's own vocabulary already types
+// it. SKOS declares its labels as annotation properties and rdfs:label is
+// built in, so typing them again here would contradict those definitions.
+func definedElsewhere(iri string) bool {
+	return strings.HasPrefix(iri, skosNamespace) || iri == rdfsLabel
+}
+
+func isWeaveTerm(iri string) bool {
+	return strings.HasPrefix(iri, weaveVocab)
+}
+
+// humanize turns a camelCase local name into lower-case words.
+func humanize(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
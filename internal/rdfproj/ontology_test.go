@@ -0,0 +1,84 @@
+package rdfproj
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func ontologyLines(p *Projector, notes []markdown.Note) []string {
+	var lines []string
+	for _, t := range p.Ontology(slices.Values(p.VaultToTriples(notes))) {
+		lines = append(lines, t.String())
+	}
+	return lines
+}
+
+func TestOntology(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "a-20250101000000", Title: "A", Type: "Note", Tags: []string{"go"}, Links: []links.Link{
+			{ID: "b-20250101000000", Type: "elaborates", Label: "more"},
+			{ID: "b-20250101000000", Type: "broader"},
+		}},
+		{ID: "b-20250101000000", Title: "B", Type: "Idea"},
+	}
+	lines := ontologyLines(New("http://example.org", nil), notes)
+
+	want := []string{
+		`<http://weave.dev/vocab> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#Ontology> .`,
+		`<http://weave.dev/vocab#Idea> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <http://weave.dev/vocab#Note> .`,
+		`<http://weave.dev/vocab#Note> <http://www.w3.org/2000/01/rdf-schema#subClassOf> <http://schema.org/Article> .`,
+		`<http://schema.org/Article> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#Class> .`,
+		`<http://weave.dev/vocab#elaborates> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#ObjectProperty> .`,
+		`<http://weave.dev/vocab#elaborates> <http://www.w3.org/2000/01/rdf-schema#subPropertyOf> <http://weave.dev/vocab#linksTo> .`,
+		`<http://weave.dev/vocab#elaborates> <http://www.w3.org/2000/01/rdf-schema#domain> <http://weave.dev/vocab#Note> .`,
+		`<http://weave.dev/vocab#elaborates> <http://www.w3.org/2000/01/rdf-schema#label> "elaborates"@en .`,
+		`<http://www.w3.org/2004/02/skos/core#broader> <http://www.w3.org/2002/07/owl#inverseOf> <http://www.w3.org/2004/02/skos/core#narrower> .`,
+		`<http://weave.dev/vocab#source> <http://www.w3.org/2000/01/rdf-schema#domain> <http://weave.dev/vocab#Link> .`,
+		`<http://weave.dev/vocab#target> <http://www.w3.org/2000/01/rdf-schema#range> <http://www.w3.org/2000/01/rdf-schema#Resource> .`,
+		`<http://weave.dev/vocab#elaborates> <http://www.w3.org/2000/01/rdf-schema#range> <http://www.w3.org/2000/01/rdf-schema#Resource> .`,
+		`<http://purl.org/dc/terms/title> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#DatatypeProperty> .`,
+		`<http://purl.org/dc/terms/subject> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/2002/07/owl#ObjectProperty> .`,
+	}
+	for _, w := range want {
+		if !slices.Contains(lines, w) {
+			t.Errorf("ontology missing %s", w)
+		}
+	}
+
+	for _, line := range lines {
+		if strings.Contains(line, "skos/core#broader> <http://www.w3.org/2000/01/rdf-schema#domain>") {
+			t.Errorf("ontology redefines an external term: %s", line)
+		}
+		if strings.HasPrefix(line, "<http://www.w3.org/2004/02/skos/core#") && strings.Contains(line, "rdf-syntax-ns#type") {
+			t.Errorf("ontology retypes a SKOS term: %s", line)
+		}
+		if strings.Contains(line, "rdf-schema#range> <http://weave.dev/vocab#Note>") && !strings.HasPrefix(line, "<http://weave.dev/vocab#source>") {
+			t.Errorf("link property ranges over notes only: %s", line)
+		}
+		if strings.Contains(line, "linksTo> <http://www.w3.org/2000/01/rdf-schema#label>") {
+			t.Errorf("ontology declares unused weave:linksTo: %s", line)
+		}
+	}
+}
+
+func TestOntologyDeterministic(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "a-20250101000000", Title: "A", Links: []links.Link{{ID: "b-20250101000000", Type: "x"}, {ID: "b-20250101000000", Type: "y"}}},
+	}
+	p := New("", nil)
+	if a, b := ontologyLines(p, notes), ontologyLines(p, notes); !slices.Equal(a, b) {
+		t.Errorf("ontology output differs between runs:\n%v\n%v", a, b)
+	}
+}
+
+func TestHumanize(t *testing.T) {
+	for in, want := range map[string]string{"linksTo": "links to", "Note": "note", "seeAlso": "see also"} {
+		if got := humanize(in); got != want {
+			t.Errorf("humanize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
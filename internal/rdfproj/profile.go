@@ -0,0 +1,230 @@
+package rdfproj
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	rdf "github.com/deiu/rdf2go"
+)
+
+const defaultExcerptLength = 280
+
+// ProfileSpec is the user-facing description of a projection profile, as
+// written in the vault config.
+type ProfileSpec struct {
+	// Body is full (the default), excerpt, or none.
+	Body string `yaml:"body"`
+	// ExcerptLength caps excerpts, in characters. Zero means 280.
+	ExcerptLength int `yaml:"excerpt_length"`
+	// Predicates, when set, are the only predicates written. Terms may be
+	// full IRIs or prefixed names.
+	Predicates []string `yaml:"predicates"`
+	// Include, when set, keeps only the notes it matches. Exclude drops
+	// the notes it matches, even included ones.
+	Include NoteSelector `yaml:"include"`
+	Exclude NoteSelector `yaml:"exclude"`
+	// BaseURI replaces the configured base URI.
+	BaseURI string `yaml:"base_uri"`
+}
+
+// NoteSelector matches notes with any of its tags or types, or with any
+// of its frontmatter flags set to true.
+type NoteSelector struct {
+	Tags  []string `yaml:"tags"`
+	Types []string `yaml:"types"`
+	Flags []string `yaml:"flags"`
+}
+
+// Profile restricts what a projector writes; build one with NewProfile.
+type Profile struct {
+	body             string
+	excerptLength    int
+	predicates       map[string]bool
+	include, exclude NoteSelector
+	baseURI          string
+}
+
+func NewProfile(spec ProfileSpec, vocab *Vocabulary) (*Profile, error) {
+	if vocab == nil {
+		vocab = defaultVocabulary
+	}
+	pr := &Profile{
+		body:          spec.Body,
+		excerptLength: spec.ExcerptLength,
+		include:       spec.Include,
+		exclude:       spec.Exclude,
+		baseURI:       spec.BaseURI,
+	}
+
+	switch pr.body {
+	case "":
+		pr.body = "full"
+	case "full", "excerpt", "none":
+	default:
+		return nil, fmt.Errorf("unknown body mode %q (want full, excerpt, or none)", spec.Body)
+	}
+	if pr.excerptLength < 0 {
+		return nil, fmt.Errorf("excerpt_length must not be negative")
+	}
+	if pr.excerptLength == 0 {
+		pr.excerptLength = defaultExcerptLength
+	}
+	if pr.baseURI != "" && !isAbsoluteIRI(pr.baseURI) {
+		return nil, fmt.Errorf("base_uri %q is not an absolute IRI", pr.baseURI)
+	}
+	pr.baseURI = strings.TrimRight(pr.baseURI, "/")
+
+	if len(spec.Predicates) > 0 {
+		pr.predicates = make(map[string]bool)
+		for _, term := range spec.Predicates {
+			iri, err := vocab.Expand(term)
+			if err != nil {
+				return nil, fmt.Errorf("predicates: %w", err)
+			}
+			pr.predicates[iri] = true
+		}
+	}
+	return pr, nil
+}
+
+// WithProfile returns a copy of p that projects through pr.
+func (p *Projector) WithProfile(pr *Profile) *Projector {
+	c := *p
+	c.profile = pr
+	if pr != nil && pr.baseURI != "" {
+		c.baseURI = pr.baseURI
+	}
+	return &c
+}
+
+// WithVault returns a copy of p that knows the notes of the vault. Links
+// naming a note by title, alias or ID prefix point at its IRI, and links
+// to whichever notes the profile leaves out are dropped, so their IRIs do
+// not leak.
+func (p *Projector) WithVault(notes []markdown.Note) *Projector {
+	c := *p
+	c.hidden = make(map[string]bool)
+	targets := make([]links.Target, 0, len(notes))
+	for _, note := range notes {
+		if !p.profile.Selects(note) {
+			c.hidden[note.ID] = true
+		}
+		targets = append(targets, links.Target{ID: note.ID, Title: note.Title, Aliases: note.Aliases})
+	}
+	c.resolver = links.NewResolver(targets)
+	return &c
+}
+
+// resolveLink names the note link points at by ID. Links the vault cannot
+// resolve are kept as written.
+func (p *Projector) resolveLink(link links.Link) links.Link {
+	if p.resolver == nil {
+		return link
+	}
+	if resolved, err := p.resolver.ResolveLink(link); err == nil {
+		return resolved
+	}
+	return link
+}
+
+// redactLinks drops the links to hidden notes from body, so the body does
+// not name them either.
+func (p *Projector) redactLinks(body string) string {
+	if len(p.hidden) == 0 {
+		return body
+	}
+	return links.RedactLinks(body, func(link links.Link) bool { return p.hidden[p.resolveLink(link).ID] })
+}
+
+// Selects reports whether the profile keeps note. A nil profile keeps
+// every note.
+func (pr *Profile) Selects(note markdown.Note) bool {
+	if pr == nil {
+		return true
+	}
+	if !pr.include.empty() && !pr.include.matches(note) {
+		return false
+	}
+	return !pr.exclude.matches(note)
+}
+
+// filtersNotes reports whether the profile can leave notes out.
+func (pr *Profile) filtersNotes() bool {
+	return pr != nil && (!pr.include.empty() || !pr.exclude.empty())
+}
+
+func (s NoteSelector) empty() bool {
+	return len(s.Tags) == 0 && len(s.Types) == 0 && len(s.Flags) == 0
+}
+
+func (s NoteSelector) matches(note markdown.Note) bool {
+	noteType := note.Type
+	if noteType == "" {
+		noteType = "Note"
+	}
+	if slices.Contains(s.Types, noteType) {
+		return true
+	}
+	// A selector tag also matches the tags nested under it.
+	for _, tag := range s.Tags {
+		if notes.HasTag(note.Tags, tag) {
+			return true
+		}
+	}
+	for _, flag := range s.Flags {
+		if note.Extra[flag] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyText is the body the profile writes for a note.
+func (pr *Profile) bodyText(body string) string {
+	if pr == nil {
+		return body
+	}
+	switch pr.body {
+	case "none":
+		return ""
+	case "excerpt":
+		return excerpt(body, pr.excerptLength)
+	}
+	return body
+}
+
+// keep reports whether the profile writes triples with predicate.
+func (pr *Profile) keep(t *rdf.Triple) bool {
+	return pr == nil || pr.predicates == nil || pr.predicates[t.Predicate.RawValue()]
+}
+
+// excerpt is the first paragraph of body that is not a heading, with
+// whitespace collapsed and cut at a word boundary to at most n characters.
+func excerpt(body string, n int) string {
+	var para string
+	for _, block := range strings.Split(body, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block != "" && !strings.HasPrefix(block, "#") {
+			para = strings.Join(strings.Fields(block), " ")
+			break
+		}
+	}
+
+	runes := []rune(para)
+	if len(runes) <= n {
+		return para
+	}
+	cut := n
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		cut = n
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + "…"
+}
@@ -0,0 +1,169 @@
+package rdfproj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestNewProfileRejectsInvalidSpec(t *testing.T) {
+	tests := map[string]ProfileSpec{
+		"body mode":      {Body: "summary"},
+		"excerpt length": {Body: "excerpt", ExcerptLength: -1},
+		"predicate":      {Predicates: []string{"nope:title"}},
+		"base URI":       {BaseURI: "example.org"},
+	}
+	for name, spec := range tests {
+		if _, err := NewProfile(spec, nil); err == nil {
+			t.Errorf("%s: NewProfile() error = nil", name)
+		}
+	}
+}
+
+func TestProfileSelects(t *testing.T) {
+	profile, err := NewProfile(ProfileSpec{
+		Include: NoteSelector{Tags: []string{"shared"}, Types: []string{"Person"}},
+		Exclude: NoteSelector{Tags: []string{"draft"}, Flags: []string{"private"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewProfile() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		note markdown.Note
+		want bool
+	}{
+		{"included tag", markdown.Note{Tags: []string{"shared"}}, true},
+		{"included type", markdown.Note{Type: "Person"}, true},
+		{"not included", markdown.Note{Tags: []string{"other"}}, false},
+		{"excluded tag wins", markdown.Note{Tags: []string{"shared", "draft"}}, false},
+		{"included nested tag", markdown.Note{Tags: []string{"shared/team"}}, true},
+		{"excluded nested tag", markdown.Note{Tags: []string{"shared", "draft/v2"}}, false},
+		{"tag prefix is not nesting", markdown.Note{Tags: []string{"shared", "drafts"}}, true},
+		{"private flag", markdown.Note{Tags: []string{"shared"}, Extra: map[string]any{"private": true}}, false},
+		{"flag not true", markdown.Note{Tags: []string{"shared"}, Extra: map[string]any{"private": "no"}}, true},
+	}
+	for _, tt := range tests {
+		if got := profile.Selects(tt.note); got != tt.want {
+			t.Errorf("%s: Selects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProfileBodyAndPredicates(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Tags: []string{"x"}, Body: "# Heading\n\nFirst paragraph that\ngoes on for a while.\n\nSecond."}
+
+	excerptProfile, err := NewProfile(ProfileSpec{Body: "excerpt", ExcerptLength: 20}, nil)
+	if err != nil {
+		t.Fatalf("NewProfile() error = %v", err)
+	}
+	p := New("http://example.org", nil).WithProfile(excerptProfile)
+	var body string
+	for _, triple := range p.NoteToTriples(note) {
+		if triple.Predicate.RawValue() == schemaText {
+			body = triple.Object.RawValue()
+		}
+	}
+	if body != "First paragraph that…" {
+		t.Errorf("excerpt = %q, want %q", body, "First paragraph that…")
+	}
+
+	slim, err := NewProfile(ProfileSpec{Body: "none", Predicates: []string{"dcterms:title", "<" + dctermsSubject + ">"}}, nil)
+	if err != nil {
+		t.Fatalf("NewProfile() error = %v", err)
+	}
+	for _, triple := range New("http://example.org", nil).WithProfile(slim).NoteToTriples(note) {
+		if pred := triple.Predicate.RawValue(); pred != dctermsTitle && pred != dctermsSubject {
+			t.Errorf("unexpected predicate %s", pred)
+		}
+	}
+}
+
+func TestProfileBodyModeLimitsStructure(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Body: "# Confidential merger\n\nSee [deal](https://example.com/deal).\n\n## Terms\n\n[price](https://example.com/price)"}
+
+	objects := func(spec ProfileSpec) map[string]bool {
+		t.Helper()
+		profile, err := NewProfile(spec, nil)
+		if err != nil {
+			t.Fatalf("NewProfile() error = %v", err)
+		}
+		found := make(map[string]bool)
+		for _, triple := range New("http://example.org", nil).WithStructure(true).WithProfile(profile).NoteToTriples(note) {
+			found[triple.Object.RawValue()] = true
+		}
+		return found
+	}
+
+	none := objects(ProfileSpec{Body: "none"})
+	for _, leaked := range []string{"Confidential merger", "Terms", "https://example.com/deal"} {
+		if none[leaked] {
+			t.Errorf("body: none exported %q", leaked)
+		}
+	}
+
+	ex := objects(ProfileSpec{Body: "excerpt"})
+	if !ex["https://example.com/deal"] {
+		t.Error("body: excerpt dropped a link in the excerpt")
+	}
+	for _, leaked := range []string{"Confidential merger", "https://example.com/price"} {
+		if ex[leaked] {
+			t.Errorf("body: excerpt exported %q from outside the excerpt", leaked)
+		}
+	}
+}
+
+func TestEachVaultTriple_ProfileDropsLinksToHiddenNotes(t *testing.T) {
+	profile, err := NewProfile(ProfileSpec{Exclude: NoteSelector{Tags: []string{"private"}}, BaseURI: "https://example.org/"}, nil)
+	if err != nil {
+		t.Fatalf("NewProfile() error = %v", err)
+	}
+	notes := []markdown.Note{
+		{ID: "pub", Title: "Pub", Links: []links.Link{{ID: "priv", Type: "linksTo"}, {ID: "other", Type: "linksTo"}}},
+		{ID: "priv", Title: "Priv", Tags: []string{"private"}},
+	}
+
+	var lines []string
+	for _, triple := range New("http://example.org", nil).WithProfile(profile).VaultToTriples(notes) {
+		lines = append(lines, triple.String())
+	}
+	out := strings.Join(lines, "\n")
+	if strings.Contains(out, "priv") {
+		t.Errorf("output mentions the private note:\n%s", out)
+	}
+	if !strings.Contains(out, "<https://example.org/notes/pub> <http://weave.dev/vocab#linksTo> <https://example.org/notes/other>") {
+		t.Errorf("output missing the link to a note outside the vault:\n%s", out)
+	}
+}
+
+func TestNoteToTriples_ProfileRedactsLinksToHiddenNotes(t *testing.T) {
+	body := "See [[priv]], [[related::priv#Plan|the plan]] and [[pub#Intro]]."
+	notes := []markdown.Note{
+		{ID: "pub", Title: "Pub", Body: body},
+		{ID: "priv", Title: "Priv", Tags: []string{"private"}},
+	}
+	want := map[string]string{
+		"full":    "See , the plan and [[pub#Intro]].",
+		"excerpt": "See , the plan and [[pub#Intro]].",
+	}
+	for mode, text := range want {
+		profile, err := NewProfile(ProfileSpec{Body: mode, Exclude: NoteSelector{Tags: []string{"private"}}}, nil)
+		if err != nil {
+			t.Fatalf("NewProfile() error = %v", err)
+		}
+		p := New("http://example.org", nil).WithProfile(profile).WithVault(notes)
+
+		var got []string
+		for _, triple := range p.NoteToTriples(notes[0]) {
+			if triple.Predicate.RawValue() == schemaText {
+				got = append(got, triple.Object.RawValue())
+			}
+		}
+		if len(got) != 1 || got[0] != text {
+			t.Errorf("%s body = %q, want %q", mode, got, text)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package rdfproj
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/markdown"
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	provEntity          = "http://www.w3.org/ns/prov#Entity"
+	provGeneratedAtTime = "http://www.w3.org/ns/prov#generatedAtTime"
+	weaveSourcePath     = weaveVocab + "sourcePath"
+	weaveContentHash    = weaveVocab + "contentHash"
+)
+
+// Source describes the file a note was loaded from.
+type Source struct {
+	// Path is relative to the vault, with forward slashes.
+	Path string
+	// Hash is the hex SHA-256 of the file's contents.
+	Hash string
+	// Generated is when the projection was made.
+	Generated time.Time
+}
+
+// NoteGraph names the graph that holds a note's triples.
+func (p *Projector) NoteGraph(id string) string {
+	return p.baseURI + "/graphs/notes/" + url.PathEscape(id)
+}
+
+// NoteQuads places a note's triples in the note's own graph, followed by
+// PROV-O metadata about that graph in the default graph. A store can then
+// replace one note by dropping its graph, and skip notes whose content
+// hash has not changed.
+func (p *Projector) NoteQuads(note markdown.Note, src Source) []export.Quad {
+	if !p.profile.Selects(note) {
+		return nil
+	}
+	graph := p.NoteGraph(note.ID)
+
+	var quads []export.Quad
+	for _, t := range p.NoteToTriples(note) {
+		quads = append(quads, export.Quad{Triple: t, Graph: graph})
+	}
+
+	subject := rdf.NewResource(graph)
+	meta := []*rdf.Triple{
+		rdf.NewTriple(subject, rdf.NewResource(rdfType), rdf.NewResource(provEntity)),
+		rdf.NewTriple(subject, rdf.NewResource(provGeneratedAtTime),
+			rdf.NewLiteralWithDatatype(src.Generated.UTC().Format(time.RFC3339Nano), rdf.NewResource(xsdDateTime))),
+		rdf.NewTriple(subject, rdf.NewResource(weaveSourcePath), rdf.NewLiteral(src.Path)),
+		rdf.NewTriple(subject, rdf.NewResource(weaveContentHash), rdf.NewLiteral("sha256:"+src.Hash)),
+	}
+	for _, t := range meta {
+		quads = append(quads, export.Quad{Triple: t})
+	}
+	return quads
+}
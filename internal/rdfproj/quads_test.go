@@ -0,0 +1,56 @@
+package rdfproj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestNoteQuads(t *testing.T) {
+	p := New("http://example.org", nil)
+	note := markdown.Note{ID: "a b", Title: "A", Tags: []string{"go"}, Links: []links.Link{{ID: "c", Type: "related", Label: "see"}}}
+	src := Source{
+		Path:      "2025/01/a.md",
+		Hash:      "abc123",
+		Generated: time.Date(2025, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+
+	graph := p.NoteGraph(note.ID)
+	if graph != "http://example.org/graphs/notes/a%20b" {
+		t.Errorf("NoteGraph() = %q", graph)
+	}
+
+	quads := p.NoteQuads(note, src)
+	inGraph := 0
+	meta := make(map[string]string)
+	for _, q := range quads {
+		switch q.Graph {
+		case graph:
+			inGraph++
+		case "":
+			if q.Subject.RawValue() != graph {
+				t.Errorf("metadata about %s, want %s", q.Subject, graph)
+			}
+			meta[q.Predicate.RawValue()] = q.Object.String()
+		default:
+			t.Errorf("quad in unexpected graph %q", q.Graph)
+		}
+	}
+
+	if want := len(p.NoteToTriples(note)); inGraph != want {
+		t.Errorf("note graph has %d triples, want %d", inGraph, want)
+	}
+	want := map[string]string{
+		rdfType:             "<" + provEntity + ">",
+		provGeneratedAtTime: `"2025-01-02T02:04:05Z"^^<` + xsdDateTime + ">",
+		weaveSourcePath:     `"2025/01/a.md"`,
+		weaveContentHash:    `"sha256:abc123"`,
+	}
+	for pred, obj := range want {
+		if meta[pred] != obj {
+			t.Errorf("%s = %s, want %s", pred, meta[pred], obj)
+		}
+	}
+}
@@ -2,9 +2,13 @@ package rdfproj
 
 import (
 	"fmt"
+	"iter"
 	"net/url"
-	"strings"
+	"regexp"
+	"slices"
+	"time"
 
+	"github.com/DeDude/weave2/internal/links"
 	"github.com/DeDude/weave2/internal/markdown"
 	rdf "github.com/deiu/rdf2go"
 )
@@ -17,40 +21,98 @@ const (
 	dctermsCreated  = "http://purl.org/dc/terms/created"
 	dctermsModified = "http://purl.org/dc/terms/modified"
 	dctermsSubject  = "http://purl.org/dc/terms/subject"
+	dctermsLanguage = "http://purl.org/dc/terms/language"
 	schemaText      = "http://schema.org/text"
 	skosConcept     = "http://www.w3.org/2004/02/skos/core#Concept"
 	skosPrefLabel   = "http://www.w3.org/2004/02/skos/core#prefLabel"
+	skosAltLabel    = "http://www.w3.org/2004/02/skos/core#altLabel"
+	rdfsLabel       = "http://www.w3.org/2000/01/rdf-schema#label"
+	weaveLink       = "http://weave.dev/vocab#Link"
+	weaveSource     = "http://weave.dev/vocab#source"
+	weaveTarget     = "http://weave.dev/vocab#target"
+	weaveRelation   = "http://weave.dev/vocab#relation"
 	xsdDateTime     = "http://www.w3.org/2001/XMLSchema#dateTime"
+	xsdLanguage     = "http://www.w3.org/2001/XMLSchema#language"
+
+	weaveVocab = "http://weave.dev/vocab#"
 
 	defaultBaseURI = "http://localhost"
 )
 
-var relationshipPredicates = map[string]string{
-	"linksTo":  "http://weave.dev/vocab#linksTo",
-	"related":  "http://www.w3.org/2004/02/skos/core#related",
-	"broader":  "http://www.w3.org/2004/02/skos/core#broader",
-	"narrower": "http://www.w3.org/2004/02/skos/core#narrower",
-	"seeAlso":  "http://www.w3.org/2000/01/rdf-schema#seeAlso",
+var blankNodeChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Projector maps notes to RDF under a base URI and vocabulary.
+type Projector struct {
+	baseURI   string
+	vocab     *Vocabulary
+	lang      string
+	structure bool
+	profile   *Profile
+	// hidden holds the IDs of notes the profile leaves out.
+	hidden map[string]bool
+	// resolver names link targets by ID once the vault is known.
+	resolver *links.Resolver
+}
+
+// New returns a projector. An empty base URI and a nil vocabulary select
+// the defaults.
+func New(baseURI string, vocab *Vocabulary) *Projector {
+	if vocab == nil {
+		vocab = defaultVocabulary
+	}
+	return &Projector{baseURI: sanitizeBaseURI(baseURI), vocab: vocab}
+}
+
+// WithLanguage returns a copy of p that tags the text of notes without
+// their own lang with lang.
+func (p *Projector) WithLanguage(lang string) *Projector {
+	c := *p
+	c.lang = lang
+	return &c
+}
+
+// Language is the default language set by WithLanguage.
+func (p *Projector) Language() string {
+	return p.lang
 }
 
-var noteTypeMap = map[string][]string{
-	"Note": {
-		"http://weave.dev/vocab#Note",
-		"http://schema.org/Article",
-	},
+func (p *Projector) BaseURI() string {
+	return p.baseURI
+}
+
+func (p *Projector) Vocabulary() *Vocabulary {
+	return p.vocab
+}
+
+// NoteURI returns the IRI a note is projected to.
+func (p *Projector) NoteURI(id string) string {
+	return makeNoteURI(p.baseURI, id)
 }
 
 func NoteToTriples(note markdown.Note, baseURI string) []*rdf.Triple {
-	baseURI = sanitizeBaseURI(baseURI)
+	return New(baseURI, nil).NoteToTriples(note)
+}
+
+func (p *Projector) NoteToTriples(note markdown.Note) []*rdf.Triple {
+	if !p.profile.Selects(note) {
+		return nil
+	}
+	baseURI := p.baseURI
 	var triples []*rdf.Triple
 	noteURI := makeNoteURI(baseURI, note.ID)
+	lang := note.Lang
+	if lang == "" {
+		lang = p.lang
+	}
+	text := func(value string) rdf.Term {
+		if lang == "" {
+			return rdf.NewLiteral(value)
+		}
+		return rdf.NewLiteralWithLanguage(value, lang)
+	}
 
 	// rdf:type (dual typing)
-	types := noteTypeMap[note.Type]
-	if types == nil {
-		types = []string{"http://weave.dev/vocab#" + note.Type}
-	}
-	for _, t := range types {
+	for _, t := range p.vocab.Classes(note.Type) {
 		triples = append(triples, rdf.NewTriple(
 			rdf.NewResource(noteURI),
 			rdf.NewResource(rdfType),
@@ -69,17 +131,41 @@ func NoteToTriples(note markdown.Note, baseURI string) []*rdf.Triple {
 	triples = append(triples, rdf.NewTriple(
 		rdf.NewResource(noteURI),
 		rdf.NewResource(dctermsTitle),
-		rdf.NewLiteral(note.Title),
+		text(note.Title),
 	))
 
+	// skos:altLabel
+	for _, alias := range note.Aliases {
+		triples = append(triples, rdf.NewTriple(
+			rdf.NewResource(noteURI),
+			rdf.NewResource(skosAltLabel),
+			text(alias),
+		))
+	}
+
+	// dcterms:language
+	if lang != "" {
+		triples = append(triples, rdf.NewTriple(
+			rdf.NewResource(noteURI),
+			rdf.NewResource(dctermsLanguage),
+			rdf.NewLiteralWithDatatype(lang, rdf.NewResource(xsdLanguage)),
+		))
+	}
+
 	// schema:text (body)
-	if note.Body != "" {
+	body := p.profile.bodyText(p.redactLinks(note.Body))
+	if body != "" {
 		triples = append(triples, rdf.NewTriple(
 			rdf.NewResource(noteURI),
 			rdf.NewResource(schemaText),
-			rdf.NewLiteral(note.Body),
+			text(body),
 		))
 	}
+	// Structure describes only the body the profile writes, so headings
+	// and links of a withheld body stay out too.
+	if p.structure && body != "" {
+		triples = append(triples, structureTriples(body, noteURI, text)...)
+	}
 
 	// dcterms:created
 	if !note.Created.IsZero() {
@@ -87,7 +173,7 @@ func NoteToTriples(note markdown.Note, baseURI string) []*rdf.Triple {
 			rdf.NewResource(noteURI),
 			rdf.NewResource(dctermsCreated),
 			rdf.NewLiteralWithDatatype(
-				note.Created.Format("2006-01-02T15:04:05Z07:00"),
+				note.Created.Format(time.RFC3339Nano),
 				rdf.NewResource(xsdDateTime),
 			),
 		))
@@ -99,89 +185,145 @@ func NoteToTriples(note markdown.Note, baseURI string) []*rdf.Triple {
 			rdf.NewResource(noteURI),
 			rdf.NewResource(dctermsModified),
 			rdf.NewLiteralWithDatatype(
-				note.Modified.Format("2006-01-02T15:04:05Z07:00"),
+				note.Modified.Format(time.RFC3339Nano),
 				rdf.NewResource(xsdDateTime),
 			),
 		))
 	}
 
-	// Tags
+	// Tags; nested tags share their ancestors' definitions.
+	seenTags := make(map[string]bool)
 	for _, tag := range note.Tags {
-		tagURI := makeTagURI(baseURI, tag)
-
 		// dcterms:subject
 		triples = append(triples, rdf.NewTriple(
 			rdf.NewResource(noteURI),
 			rdf.NewResource(dctermsSubject),
-			rdf.NewResource(tagURI),
-		))
-
-		// rdf:type skos:Concept
-		triples = append(triples, rdf.NewTriple(
-			rdf.NewResource(tagURI),
-			rdf.NewResource(rdfType),
-			rdf.NewResource(skosConcept),
-		))
-
-		// skos:prefLabel
-		triples = append(triples, rdf.NewTriple(
-			rdf.NewResource(tagURI),
-			rdf.NewResource(skosPrefLabel),
-			rdf.NewLiteral(tag),
+			rdf.NewResource(makeTagURI(baseURI, tag)),
 		))
+		for _, triple := range p.tagTriples(tag, text) {
+			if key := triple.String(); !seenTags[key] {
+				seenTags[key] = true
+				triples = append(triples, triple)
+			}
+		}
 	}
 
 	// Links
-	for _, link := range note.Links {
-		predicate := mapRelationshipType(link.Type)
-		targetURI := makeNoteURI(baseURI, link.ID)
+	for i, link := range note.Links {
+		link = p.resolveLink(link)
+		if p.hidden[link.ID] {
+			continue
+		}
+		predicate := p.vocab.Predicate(link.Type)
+		targetURI := makeLinkURI(baseURI, link)
 		triples = append(triples, rdf.NewTriple(
 			rdf.NewResource(noteURI),
 			rdf.NewResource(predicate),
 			rdf.NewResource(targetURI),
 		))
+
+		// Labels belong to the link, not either note, so labelled links
+		// are reified as weave:Link nodes. So are links to a heading: the
+		// target IRI has the heading's anchor, and the node keeps the
+		// heading as written.
+		if link.Label != "" || link.Anchor != "" {
+			node := rdf.NewBlankNode(linkNodeID(note.ID, i))
+			triples = append(triples,
+				rdf.NewTriple(node, rdf.NewResource(rdfType), rdf.NewResource(weaveLink)),
+				rdf.NewTriple(node, rdf.NewResource(weaveSource), rdf.NewResource(noteURI)),
+				rdf.NewTriple(node, rdf.NewResource(weaveRelation), rdf.NewResource(predicate)),
+				rdf.NewTriple(node, rdf.NewResource(weaveTarget), rdf.NewResource(targetURI)),
+			)
+			if link.Label != "" {
+				triples = append(triples, rdf.NewTriple(node, rdf.NewResource(rdfsLabel), text(link.Label)))
+			}
+			if link.Anchor != "" {
+				triples = append(triples, rdf.NewTriple(node, rdf.NewResource(weaveAnchor), rdf.NewLiteral(link.Anchor)))
+			}
+		}
 	}
 
-	return triples
+	return slices.DeleteFunc(triples, func(t *rdf.Triple) bool { return !p.profile.keep(t) })
 }
 
 func VaultToTriples(notes []markdown.Note, baseURI string) []*rdf.Triple {
-	baseURI = sanitizeBaseURI(baseURI)
+	return New(baseURI, nil).VaultToTriples(notes)
+}
+
+func (p *Projector) VaultToTriples(notes []markdown.Note) []*rdf.Triple {
 	var triples []*rdf.Triple
+	p.EachVaultTriple(slices.Values(notes), func(triple *rdf.Triple) bool {
+		triples = append(triples, triple)
+		return true
+	})
+	return triples
+}
+
+// EachVaultTriple projects a stream of notes, passing each triple to fn as
+// soon as its note is converted. Tag definitions are deduplicated as in
+// VaultToTriples; a tag used in several languages keeps a label for each.
+// Links are resolved against the whole vault, so unless p comes from
+// WithVault it reads every note first. Returning false from fn stops the projection.
+func EachVaultTriple(notes iter.Seq[markdown.Note], baseURI string, fn func(*rdf.Triple) bool) {
+	New(baseURI, nil).EachVaultTriple(notes, fn)
+}
+
+func (p *Projector) EachVaultTriple(notes iter.Seq[markdown.Note], fn func(*rdf.Triple) bool) {
+	if p.resolver == nil {
+		// Links may name notes by title or alias, and links to left-out
+		// notes are dropped, which takes knowing every note before
+		// projecting any.
+		all := slices.Collect(notes)
+		p = p.WithVault(all)
+		notes = slices.Values(all)
+	}
 	seenTags := make(map[string]bool)
 
-	for _, note := range notes {
-		noteTriples := NoteToTriples(note, baseURI)
+	for note := range notes {
+		noteTriples := p.NoteToTriples(note)
 
 		for _, triple := range noteTriples {
-			if isTagDefinitionTriple(triple, baseURI) {
-				key := triple.Subject.String() + triple.Predicate.String()
-				if !seenTags[key] {
-					seenTags[key] = true
-					triples = append(triples, triple)
+			if isTagDefinitionTriple(triple, p.baseURI) {
+				key := triple.String()
+				if seenTags[key] {
+					continue
 				}
-			} else {
-				triples = append(triples, triple)
+				seenTags[key] = true
+			}
+			if !fn(triple) {
+				return
 			}
 		}
 	}
-
-	return triples
 }
 
-func mapRelationshipType(relType string) string {
-	if pred, ok := relationshipPredicates[relType]; ok {
-		return pred
-	}
-	return fmt.Sprintf("http://weave.dev/vocab#%s", relType)
+// linkNodeID names the blank node for a note's i-th link, unique across the
+// vault so projections of different notes can be merged.
+func linkNodeID(noteID string, i int) string {
+	return fmt.Sprintf("link-%s-%d", blankNodeChars.ReplaceAllString(noteID, "_"), i)
 }
 
 func makeNoteURI(baseURI, id string) string {
 	return fmt.Sprintf("%s/notes/%s", baseURI, url.PathEscape(id))
 }
 
-func makeTagURI(baseURI, tag string) string {
-	return fmt.Sprintf("%s/tags/%s", baseURI, url.PathEscape(tag))
+// makeLinkURI returns the IRI a link points at: the target note, or a
+// fragment of it for a heading or block. Heading fragments are the
+// section IRIs structural projection gives the heading.
+func makeLinkURI(baseURI string, link links.Link) string {
+	uri := makeNoteURI(baseURI, link.ID)
+	switch {
+	case link.Block != "":
+		return uri + "#" + url.PathEscape("^"+link.Block)
+	case link.Anchor != "":
+		return uri + "#" + url.PathEscape(markdown.Slug(link.Anchor))
+	}
+	return uri
+}
+
+// NoteURI returns the IRI a note is projected to.
+func NoteURI(baseURI, id string) string {
+	return New(baseURI, nil).NoteURI(id)
 }
 
 func sanitizeBaseURI(baseURI string) string {
@@ -190,13 +332,3 @@ func sanitizeBaseURI(baseURI string) string {
 	}
 	return baseURI
 }
-
-func isTagDefinitionTriple(triple *rdf.Triple, baseURI string) bool {
-	subj := triple.Subject.String()
-	pred := triple.Predicate.String()
-
-	isTagSubject := strings.Contains(subj, "/tags/")
-	isTagPredicate := pred == "<"+rdfType+">" || pred == "<"+skosPrefLabel+">"
-
-	return isTagSubject && isTagPredicate
-}
@@ -1,12 +1,14 @@
 package rdfproj
 
 import (
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/DeDude/weave2/internal/links"
 	"github.com/DeDude/weave2/internal/markdown"
+	rdf "github.com/deiu/rdf2go"
 )
 
 func TestNoteToTriples_Basic(t *testing.T) {
@@ -45,7 +47,7 @@ func TestNoteToTriples_Basic(t *testing.T) {
 	}
 
 	if !hasType || !hasID || !hasTitle || !hasBody {
-		t.Errorf("Missing expected triples: hasType=%v, hasID=%v, hasTitle=%v, hasBody=%v", 
+		t.Errorf("Missing expected triples: hasType=%v, hasID=%v, hasTitle=%v, hasBody=%v",
 			hasType, hasID, hasTitle, hasBody)
 	}
 }
@@ -135,7 +137,7 @@ func TestNoteToTriples_WithTags(t *testing.T) {
 		if strings.Contains(s, "dc/terms/subject") {
 			subjectCount++
 		}
-		if strings.Contains(s, "skos/core#Concept") {
+		if strings.Contains(s, "skos/core#Concept>") {
 			conceptCount++
 		}
 		if strings.Contains(s, "skos/core#prefLabel") {
@@ -185,7 +187,7 @@ func TestNoteToTriples_WithLinks(t *testing.T) {
 	}
 }
 
-func TestMapRelationshipType_Known(t *testing.T) {
+func TestDefaultVocabularyPredicate_Known(t *testing.T) {
 	tests := []struct {
 		relType string
 		want    string
@@ -198,19 +200,19 @@ func TestMapRelationshipType_Known(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := mapRelationshipType(tt.relType)
+		got := DefaultVocabulary().Predicate(tt.relType)
 		if got != tt.want {
-			t.Errorf("mapRelationshipType(%q) = %q, want %q", tt.relType, got, tt.want)
+			t.Errorf("Predicate(%q) = %q, want %q", tt.relType, got, tt.want)
 		}
 	}
 }
 
-func TestMapRelationshipType_Unknown(t *testing.T) {
-	got := mapRelationshipType("customType")
+func TestDefaultVocabularyPredicate_Unknown(t *testing.T) {
+	got := DefaultVocabulary().Predicate("customType")
 	want := "http://weave.dev/vocab#customType"
 
 	if got != want {
-		t.Errorf("mapRelationshipType(customType) = %q, want %q", got, want)
+		t.Errorf("Predicate(customType) = %q, want %q", got, want)
 	}
 }
 
@@ -300,3 +302,134 @@ func TestNoteToTriples_EmptyBaseURI(t *testing.T) {
 		t.Error("Expected default baseURI (localhost) to be used")
 	}
 }
+
+func TestEachVaultTriple_MatchesVaultToTriples(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "note1-20250101000000", Title: "Note 1", Type: "Note", Tags: []string{"golang"}},
+		{ID: "note2-20250102000000", Title: "Note 2", Type: "Note", Tags: []string{"golang"}},
+	}
+
+	want := VaultToTriples(notes, "http://example.org")
+
+	var got []string
+	EachVaultTriple(slices.Values(notes), "http://example.org", func(triple *rdf.Triple) bool {
+		got = append(got, triple.String())
+		return true
+	})
+
+	if len(got) != len(want) {
+		t.Fatalf("EachVaultTriple() produced %d triples, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i].String() {
+			t.Errorf("triple %d = %s, want %s", i, got[i], want[i].String())
+		}
+	}
+}
+
+func TestVaultToTriples_ResolvesLinks(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "a-20250101000000", Title: "A", Links: []links.Link{
+			{ID: "my title", Type: "linksTo"},
+			{ID: "b-2025", Type: "related", Anchor: "Plan"},
+			{ID: "C# tips", Type: "linksTo"},
+			{ID: "nowhere", Type: "linksTo"},
+		}},
+		{ID: "b-20250101000000", Title: "My Title"},
+		{ID: "c-20250101000000", Title: "C# tips"},
+	}
+
+	found := make(map[string]bool)
+	for _, triple := range VaultToTriples(notes, "http://example.org") {
+		found[triple.String()] = true
+	}
+	from := "<http://example.org/notes/a-20250101000000> "
+	for _, want := range []string{
+		from + "<http://weave.dev/vocab#linksTo> <http://example.org/notes/b-20250101000000> .",
+		from + "<http://www.w3.org/2004/02/skos/core#related> <http://example.org/notes/b-20250101000000#plan> .",
+		from + "<http://weave.dev/vocab#linksTo> <http://example.org/notes/c-20250101000000> .",
+		from + "<http://weave.dev/vocab#linksTo> <http://example.org/notes/nowhere> .",
+	} {
+		if !found[want] {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
+
+func TestEachVaultTriple_Stop(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "note1-20250101000000", Title: "Note 1", Type: "Note"},
+		{ID: "note2-20250102000000", Title: "Note 2", Type: "Note"},
+	}
+
+	count := 0
+	EachVaultTriple(slices.Values(notes), "http://example.org", func(triple *rdf.Triple) bool {
+		count++
+		return count < 3
+	})
+
+	if count != 3 {
+		t.Errorf("EachVaultTriple() visited %d triples after stop, want 3", count)
+	}
+}
+
+func TestNoteURI(t *testing.T) {
+	if got := NoteURI("", "a b"); got != defaultBaseURI+"/notes/a%20b" {
+		t.Errorf("NoteURI() = %q", got)
+	}
+	if got := NoteURI("http://example.org", "x"); got != "http://example.org/notes/x" {
+		t.Errorf("NoteURI() = %q", got)
+	}
+}
+
+func TestNoteToTriples_Language(t *testing.T) {
+	p := New("http://example.org", nil).WithLanguage("en")
+	note := markdown.Note{ID: "a", Title: "Hallo", Body: "Text", Lang: "de", Tags: []string{"gruss"},
+		Links: []links.Link{{ID: "b", Type: "linksTo", Label: "siehe"}}}
+
+	found := make(map[string]string)
+	for _, triple := range p.NoteToTriples(note) {
+		found[triple.Predicate.RawValue()] = triple.Object.String()
+	}
+	want := map[string]string{
+		dctermsTitle:    `"Hallo"@de`,
+		schemaText:      `"Text"@de`,
+		skosPrefLabel:   `"gruss"@de`,
+		rdfsLabel:       `"siehe"@de`,
+		dctermsLanguage: `"de"^^<` + xsdLanguage + `>`,
+	}
+	for pred, obj := range want {
+		if found[pred] != obj {
+			t.Errorf("%s = %s, want %s", pred, found[pred], obj)
+		}
+	}
+
+	note.Lang = ""
+	for _, triple := range p.NoteToTriples(note) {
+		if triple.Predicate.RawValue() == dctermsTitle && triple.Object.String() != `"Hallo"@en` {
+			t.Errorf("title without lang = %s, want the default @en", triple.Object)
+		}
+	}
+	for _, triple := range New("http://example.org", nil).NoteToTriples(note) {
+		if triple.Predicate.RawValue() == dctermsLanguage {
+			t.Errorf("dcterms:language emitted without any language")
+		}
+	}
+}
+
+func TestVaultToTriples_TagLabelPerLanguage(t *testing.T) {
+	notes := []markdown.Note{
+		{ID: "a", Title: "A", Lang: "en", Tags: []string{"go"}},
+		{ID: "b", Title: "B", Lang: "de", Tags: []string{"go"}},
+		{ID: "c", Title: "C", Lang: "de", Tags: []string{"go"}},
+	}
+	labels := 0
+	for _, triple := range VaultToTriples(notes, "http://example.org") {
+		if triple.Predicate.RawValue() == skosPrefLabel {
+			labels++
+		}
+	}
+	if labels != 2 {
+		t.Errorf("got %d tag labels, want one per language", labels)
+	}
+}
@@ -0,0 +1,244 @@
+package rdfproj
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	rdf "github.com/deiu/rdf2go"
+)
+
+// TriplesToNotes reverses NoteToTriples: every subject typed with a weave
+// class or carrying a dcterms:identifier becomes a note. Triples the
+// projection never emits are ignored. Tags and links come back sorted,
+// since RDF graphs are unordered. Subjects that cannot be turned into a
+// note are reported as errors and left out.
+func TriplesToNotes(triples []*rdf.Triple, baseURI string) ([]markdown.Note, []error) {
+	return New(baseURI, nil).TriplesToNotes(triples)
+}
+
+func (p *Projector) TriplesToNotes(triples []*rdf.Triple) ([]markdown.Note, []error) {
+	baseURI := p.baseURI
+	notePrefix := baseURI + "/notes/"
+	tagPrefix := baseURI + "/tags/"
+
+	bySubject := make(map[string][]*rdf.Triple)
+	var order []string
+	for _, t := range triples {
+		s, ok := t.Subject.(*rdf.Resource)
+		if !ok {
+			continue
+		}
+		if _, seen := bySubject[s.URI]; !seen {
+			order = append(order, s.URI)
+		}
+		bySubject[s.URI] = append(bySubject[s.URI], t)
+	}
+
+	// Nested tags are labelled with their last segment, so their
+	// notation, the full tag, takes precedence.
+	labels := make(map[string]string)
+	notations := make(map[string]bool)
+	for _, t := range triples {
+		subject := t.Subject.RawValue()
+		switch t.Predicate.RawValue() {
+		case skosNotation:
+			labels[subject] = t.Object.RawValue()
+			notations[subject] = true
+		case skosPrefLabel:
+			if !notations[subject] {
+				labels[subject] = t.Object.RawValue()
+			}
+		}
+	}
+	reified := reifiedLinks(triples)
+
+	// Note IRIs map to IDs up front so links resolve even when the
+	// target's IRI was minted under a different base.
+	ids := make(map[string]string)
+	for _, subject := range order {
+		if id, ok := p.noteID(subject, bySubject[subject], notePrefix); ok {
+			ids[subject] = id
+		}
+	}
+
+	var notes []markdown.Note
+	var errs []error
+	for _, subject := range order {
+		id, ok := ids[subject]
+		if !ok {
+			continue
+		}
+		note, err := p.tripleNote(id, bySubject[subject], ids, labels, reified, notePrefix, tagPrefix)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subject, err))
+			continue
+		}
+		notes = append(notes, note)
+	}
+
+	sort.Slice(notes, func(i, j int) bool { return notes[i].ID < notes[j].ID })
+	return notes, errs
+}
+
+func (p *Projector) noteID(subject string, triples []*rdf.Triple, notePrefix string) (string, bool) {
+	var classes []string
+	for _, t := range triples {
+		switch t.Predicate.RawValue() {
+		case dctermsID:
+			return t.Object.RawValue(), true
+		case rdfType:
+			classes = append(classes, t.Object.RawValue())
+		}
+	}
+	if _, isNote := p.vocab.NoteType(classes); !isNote {
+		return "", false
+	}
+	id, ok := strings.CutPrefix(subject, notePrefix)
+	if !ok {
+		return "", false
+	}
+	id, err := url.PathUnescape(id)
+	return id, err == nil
+}
+
+// linkKey identifies a link by its source, predicate and target IRIs.
+type linkKey struct {
+	source, relation, target string
+}
+
+// reifiedLink is what a weave:Link node adds to the triple it describes.
+type reifiedLink struct {
+	label, anchor string
+}
+
+// reifiedLinks collects the labels and heading anchors of weave:Link nodes.
+func reifiedLinks(triples []*rdf.Triple) map[linkKey]reifiedLink {
+	nodes := make(map[string]map[string]string)
+	for _, t := range triples {
+		node := t.Subject.String()
+		if nodes[node] == nil {
+			nodes[node] = make(map[string]string)
+		}
+		nodes[node][t.Predicate.RawValue()] = t.Object.RawValue()
+	}
+
+	reified := make(map[linkKey]reifiedLink)
+	for _, props := range nodes {
+		if props[rdfType] != weaveLink {
+			continue
+		}
+		key := linkKey{props[weaveSource], props[weaveRelation], props[weaveTarget]}
+		reified[key] = reifiedLink{label: props[rdfsLabel], anchor: props[weaveAnchor]}
+	}
+	return reified
+}
+
+func (p *Projector) tripleNote(id string, triples []*rdf.Triple, ids, labels map[string]string, reified map[linkKey]reifiedLink, notePrefix, tagPrefix string) (markdown.Note, error) {
+	note := markdown.Note{ID: id}
+	var classes []string
+
+	for _, t := range triples {
+		pred := t.Predicate.RawValue()
+		obj := t.Object.RawValue()
+
+		switch pred {
+		case dctermsID:
+		case dctermsTitle:
+			note.Title = obj
+		case schemaText:
+			note.Body = obj
+		case dctermsCreated, dctermsModified:
+			ts, err := time.Parse(time.RFC3339, obj)
+			if err != nil {
+				return markdown.Note{}, fmt.Errorf("invalid %s: %w", pred, err)
+			}
+			if pred == dctermsCreated {
+				note.Created = ts
+			} else {
+				note.Modified = ts
+			}
+		case dctermsLanguage:
+			note.Lang = obj
+		case skosAltLabel:
+			note.Aliases = append(note.Aliases, obj)
+		case dctermsSubject:
+			if tag, ok := tagName(obj, labels, tagPrefix); ok {
+				note.Tags = append(note.Tags, tag)
+			}
+		case rdfType:
+			classes = append(classes, obj)
+		default:
+			relType, ok := p.vocab.RelationType(pred)
+			if !ok {
+				continue
+			}
+			base, fragment, _ := strings.Cut(obj, "#")
+			target, ok := ids[base]
+			if !ok {
+				target, ok = strings.CutPrefix(base, notePrefix)
+				if ok {
+					target, _ = url.PathUnescape(target)
+				}
+			}
+			if ok {
+				r := reified[linkKey{t.Subject.RawValue(), pred, obj}]
+				link := links.Link{ID: target, Type: relType, Label: r.label}
+				fragment, _ = url.PathUnescape(fragment)
+				switch block, isBlock := strings.CutPrefix(fragment, "^"); {
+				case isBlock:
+					link.Block = block
+				case r.anchor != "":
+					// The IRI only has the heading's anchor.
+					link.Anchor = r.anchor
+				default:
+					link.Anchor = fragment
+				}
+				note.Links = append(note.Links, link)
+			}
+		}
+	}
+
+	if note.Title == "" {
+		return markdown.Note{}, fmt.Errorf("note %s has no dcterms:title", id)
+	}
+	if note.Lang == p.lang {
+		// The projector's default was applied, not the note's own.
+		note.Lang = ""
+	}
+	note.Type, _ = p.vocab.NoteType(classes)
+	if note.Type == "" {
+		note.Type = "Note"
+	}
+	sort.Strings(note.Tags)
+	sort.Strings(note.Aliases)
+	sort.Slice(note.Links, func(i, j int) bool {
+		if note.Links[i].ID != note.Links[j].ID {
+			return note.Links[i].ID < note.Links[j].ID
+		}
+		if note.Links[i].Type != note.Links[j].Type {
+			return note.Links[i].Type < note.Links[j].Type
+		}
+		if note.Links[i].Label != note.Links[j].Label {
+			return note.Links[i].Label < note.Links[j].Label
+		}
+		return note.Links[i].Anchor+"^"+note.Links[i].Block < note.Links[j].Anchor+"^"+note.Links[j].Block
+	})
+	return note, nil
+}
+
+func tagName(uri string, labels map[string]string, tagPrefix string) (string, bool) {
+	if label, ok := labels[uri]; ok {
+		return label, true
+	}
+	tag, ok := strings.CutPrefix(uri, tagPrefix)
+	if !ok {
+		return "", false
+	}
+	tag, err := url.PathUnescape(tag)
+	return tag, err == nil
+}
@@ -0,0 +1,128 @@
+package rdfproj
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	rdf "github.com/deiu/rdf2go"
+)
+
+func TestTriplesToNotes_RoundTrip(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 12, 30, 0, 0, time.UTC)
+	want := []markdown.Note{
+		{
+			ID:       "child-20250101123000",
+			Title:    "Child",
+			Body:     "Body text\nwith lines",
+			Tags:     []string{"go", "rdf notes"},
+			Aliases:  []string{"Kid", "offspring"},
+			Type:     "Note",
+			Created:  ts,
+			Modified: ts.Add(time.Hour),
+			Links: []links.Link{
+				{ID: "parent-20250101000000", Type: "broader"},
+				{ID: "parent-20250101000000", Type: "custom"},
+			},
+		},
+		{ID: "parent-20250101000000", Title: "Parent", Type: "Concept", Created: ts, Modified: ts},
+	}
+
+	got, errs := TriplesToNotes(VaultToTriples(want, "http://example.org"), "http://example.org")
+	if len(errs) > 0 {
+		t.Fatalf("TriplesToNotes() errors = %v", errs)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TriplesToNotes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestTriplesToNotes_IgnoresForeignSubjects(t *testing.T) {
+	triples := []*rdf.Triple{
+		rdf.NewTriple(rdf.NewResource("http://other.org/x"), rdf.NewResource(dctermsTitle), rdf.NewLiteral("Not a note")),
+	}
+	got, errs := TriplesToNotes(triples, "http://example.org")
+	if len(got) != 0 || len(errs) != 0 {
+		t.Errorf("TriplesToNotes() = %v, %v, want nothing", got, errs)
+	}
+}
+
+func TestTriplesToNotes_ReportsBadNotes(t *testing.T) {
+	uri := rdf.NewResource("http://example.org/notes/a-20250101000000")
+	triples := []*rdf.Triple{
+		rdf.NewTriple(uri, rdf.NewResource(dctermsID), rdf.NewLiteral("a-20250101000000")),
+		rdf.NewTriple(uri, rdf.NewResource(dctermsTitle), rdf.NewLiteral("A")),
+		rdf.NewTriple(uri, rdf.NewResource(dctermsCreated), rdf.NewLiteral("yesterday")),
+	}
+	got, errs := TriplesToNotes(triples, "http://example.org")
+	if len(got) != 0 || len(errs) != 1 {
+		t.Errorf("TriplesToNotes() = %v, %v, want one error", got, errs)
+	}
+}
+
+func TestTriplesToNotes_Language(t *testing.T) {
+	p := New("http://example.org", nil).WithLanguage("en")
+	want := []markdown.Note{
+		{ID: "a", Title: "Hallo", Type: "Note", Lang: "de", Tags: []string{"gruss"}},
+		{ID: "b", Title: "Hello", Type: "Note", Tags: []string{"gruss"}},
+	}
+
+	triples := p.VaultToTriples(want)
+	got, errs := p.TriplesToNotes(triples)
+	if len(errs) > 0 {
+		t.Fatalf("TriplesToNotes() errors = %v", errs)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TriplesToNotes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestTriplesToNotes_LinkFragments(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Type: "Note", Links: []links.Link{
+		{ID: "b", Type: "linksTo", Anchor: "Design Notes"},
+		{ID: "b", Type: "linksTo", Label: "quote", Block: "q1"},
+	}}
+	triples := NoteToTriples(note, "http://example.org")
+
+	objects := make(map[string]bool)
+	for _, triple := range triples {
+		if triple.Predicate.RawValue() == weaveLinksTo || triple.Predicate.RawValue() == weaveTarget {
+			objects[triple.Object.RawValue()] = true
+		}
+	}
+	for _, iri := range []string{"http://example.org/notes/b#design-notes", "http://example.org/notes/b#%5Eq1"} {
+		if !objects[iri] {
+			t.Errorf("no link to %s in %v", iri, objects)
+		}
+	}
+
+	got, errs := TriplesToNotes(triples, "http://example.org")
+	if len(errs) > 0 {
+		t.Fatalf("TriplesToNotes() errors = %v", errs)
+	}
+	want := []links.Link{
+		{ID: "b", Type: "linksTo", Anchor: "Design Notes"},
+		{ID: "b", Type: "linksTo", Label: "quote", Block: "q1"},
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Links, want) {
+		t.Errorf("TriplesToNotes() links = %+v, want %+v", got, want)
+	}
+	if losses := Compare([]markdown.Note{note}, got); len(losses) > 0 {
+		t.Errorf("Compare() = %v", losses)
+	}
+
+	// Without its weave:Link node, a heading link falls back to the anchor
+	// in the IRI.
+	var bare []*rdf.Triple
+	for _, triple := range triples {
+		if _, blank := triple.Subject.(*rdf.BlankNode); !blank {
+			bare = append(bare, triple)
+		}
+	}
+	got, _ = TriplesToNotes(bare, "http://example.org")
+	if len(got) != 1 || len(got[0].Links) != 2 || got[0].Links[1].Anchor != "design-notes" {
+		t.Errorf("TriplesToNotes() without link nodes = %+v", got)
+	}
+}
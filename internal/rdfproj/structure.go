@@ -0,0 +1,112 @@
+package rdfproj
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	schemaHasPart     = "http://schema.org/hasPart"
+	schemaName        = "http://schema.org/name"
+	schemaPosition    = "http://schema.org/position"
+	schemaCitation    = "http://schema.org/citation"
+	schemaImage       = "http://schema.org/image"
+	schemaPageElement = "http://schema.org/WebPageElement"
+	dctermsReferences = "http://purl.org/dc/terms/references"
+	weaveAnchor       = weaveVocab + "anchor"
+	xsdInteger        = "http://www.w3.org/2001/XMLSchema#integer"
+)
+
+// WithStructure returns a copy of p that also projects the structure of
+// note bodies: headings as nested sections, and links and images to
+// absolute IRIs.
+func (p *Projector) WithStructure(on bool) *Projector {
+	c := *p
+	c.structure = on
+	return &c
+}
+
+// structureTriples describes the body of the note at noteURI. Sections are
+// typed schema:WebPageElement rather than with a weave class, so reading
+// the graph back never mistakes them for notes.
+func structureTriples(body, noteURI string, text func(string) rdf.Term) []*rdf.Triple {
+	s := markdown.ParseStructure(body)
+	var triples []*rdf.Triple
+
+	type parent struct {
+		uri      string
+		level    int
+		children int
+	}
+	stack := []*parent{{uri: noteURI}}
+	for _, h := range s.Headings {
+		for len(stack) > 1 && stack[len(stack)-1].level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		up := stack[len(stack)-1]
+		up.children++
+
+		sectionURI := noteURI + "#" + url.PathEscape(h.Anchor)
+		section := rdf.NewResource(sectionURI)
+		triples = append(triples,
+			rdf.NewTriple(rdf.NewResource(up.uri), rdf.NewResource(schemaHasPart), section),
+			rdf.NewTriple(section, rdf.NewResource(rdfType), rdf.NewResource(schemaPageElement)),
+			rdf.NewTriple(section, rdf.NewResource(schemaName), text(h.Text)),
+			rdf.NewTriple(section, rdf.NewResource(weaveAnchor), rdf.NewLiteral(h.Anchor)),
+			rdf.NewTriple(section, rdf.NewResource(schemaPosition),
+				rdf.NewLiteralWithDatatype(strconv.Itoa(up.children), rdf.NewResource(xsdInteger))),
+		)
+		stack = append(stack, &parent{uri: sectionURI, level: h.Level})
+	}
+
+	// Relative references resolve against the note IRI, as they would in
+	// the page the server renders there.
+	note := rdf.NewResource(noteURI)
+	noteBase, baseErr := url.Parse(noteURI)
+	seen := make(map[string]bool)
+	for _, ref := range s.References {
+		iri := escapeIRI(ref.URL)
+		u, err := url.Parse(iri)
+		if err != nil {
+			continue
+		}
+		if !u.IsAbs() {
+			if baseErr != nil {
+				continue
+			}
+			iri = noteBase.ResolveReference(u).String()
+		}
+		preds := []string{dctermsReferences, schemaCitation}
+		if ref.Image {
+			preds = []string{schemaImage}
+		}
+		for _, pred := range preds {
+			if seen[pred+" "+iri] {
+				continue
+			}
+			seen[pred+" "+iri] = true
+			triples = append(triples, rdf.NewTriple(note, rdf.NewResource(pred), rdf.NewResource(iri)))
+		}
+	}
+	return triples
+}
+
+// escapeIRI percent-encodes the characters Markdown link targets may hold
+// but IRIs may not, such as spaces, quotes and braces.
+func escapeIRI(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c == 0x7f || strings.IndexByte("<>\"{}|\\^`", c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package rdfproj
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestNoteToTriples_Structure(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Lang: "en", Body: "# Top\n\n## One\n\n" +
+		"[spec](https://example.org/spec) ![logo](https://example.org/logo.png) [local](b.md)\n\n" +
+		"## Two\n\n[again](https://example.org/spec)\n"}
+	base := "http://example.org/notes/a"
+
+	plain := New("http://example.org", nil).NoteToTriples(note)
+	triples := New("http://example.org", nil).WithStructure(true).NoteToTriples(note)
+
+	found := make(map[string]bool)
+	for _, triple := range triples {
+		found[triple.String()] = true
+	}
+	want := []string{
+		"<" + base + "> <" + schemaHasPart + "> <" + base + "#top> .",
+		"<" + base + "#top> <" + schemaHasPart + "> <" + base + "#one> .",
+		"<" + base + "#top> <" + schemaHasPart + "> <" + base + "#two> .",
+		"<" + base + "#two> <" + rdfType + "> <" + schemaPageElement + "> .",
+		"<" + base + "#two> <" + schemaName + "> \"Two\"@en .",
+		"<" + base + "#two> <" + weaveAnchor + "> \"two\" .",
+		"<" + base + "#two> <" + schemaPosition + "> \"2\"^^<" + xsdInteger + "> .",
+		"<" + base + "> <" + dctermsReferences + "> <https://example.org/spec> .",
+		"<" + base + "> <" + schemaCitation + "> <https://example.org/spec> .",
+		"<" + base + "> <" + schemaImage + "> <https://example.org/logo.png> .",
+		"<" + base + "> <" + dctermsReferences + "> <http://example.org/notes/b.md> .",
+	}
+	for _, w := range want {
+		if !found[w] {
+			t.Errorf("missing %s", w)
+		}
+	}
+	// 3 sections of 5 triples, two reference pairs (one deduplicated), one image.
+	if got, wantN := len(triples)-len(plain), 3*5+2*2+1; got != wantN {
+		t.Errorf("structure added %d triples, want %d", got, wantN)
+	}
+}
+
+func TestTriplesToNotes_IgnoresStructure(t *testing.T) {
+	p := New("http://example.org", nil).WithStructure(true)
+	note := markdown.Note{ID: "a", Title: "A", Type: "Note", Body: "# Top\n\nSee [x](https://example.org/x).\n"}
+
+	got, errs := p.TriplesToNotes(p.NoteToTriples(note))
+	if len(errs) != 0 {
+		t.Fatalf("TriplesToNotes() errors = %v", errs)
+	}
+	if losses := Compare([]markdown.Note{note}, got); len(losses) != 0 {
+		t.Errorf("round trip lost %v", losses)
+	}
+}
+
+func TestNoteToTriples_StructureRelativeRefs(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Body: "![diagram](<img/a b.png>) [up](../files/spec.pdf) [here](#top)"}
+	triples := New("http://example.org/kb", nil).WithStructure(true).NoteToTriples(note)
+
+	found := make(map[string]bool)
+	for _, triple := range triples {
+		found[triple.Predicate.RawValue()+" "+triple.Object.RawValue()] = true
+	}
+	for _, w := range []string{
+		schemaImage + " http://example.org/kb/notes/img/a%20b.png",
+		dctermsReferences + " http://example.org/kb/files/spec.pdf",
+		dctermsReferences + " http://example.org/kb/notes/a#top",
+	} {
+		if !found[w] {
+			t.Errorf("missing %s in %v", w, found)
+		}
+	}
+}
+
+func TestNoteToTriples_StructureEscapesIRIs(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Body: "[x](<https://example.com/a b>) " +
+		"[q](https://example.com/?q={a}|x) [s](https://example.com/\"s\"^\\x)"}
+	triples := New("http://example.org", nil).WithStructure(true).NoteToTriples(note)
+
+	found := make(map[string]bool)
+	for _, triple := range triples {
+		if triple.Predicate.RawValue() == dctermsReferences {
+			found[triple.Object.RawValue()] = true
+		}
+	}
+	for _, iri := range []string{"https://example.com/a%20b", "https://example.com/?q=%7Ba%7D%7Cx", "https://example.com/%22s%22%5E%5Cx"} {
+		if !found[iri] {
+			t.Errorf("missing reference %s in %v", iri, found)
+		}
+	}
+
+	for _, format := range []export.Format{export.Turtle, export.NTriples} {
+		var buf bytes.Buffer
+		if err := export.Write(&buf, triples, format); err != nil {
+			t.Fatalf("Write(%s) error = %v", format, err)
+		}
+		if _, err := export.Read(&buf, format); err != nil {
+			t.Errorf("Read(%s) error = %v", format, err)
+		}
+	}
+}
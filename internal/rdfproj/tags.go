@@ -0,0 +1,95 @@
+package rdfproj
+
+import (
+	"net/url"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	skosConceptScheme = "http://www.w3.org/2004/02/skos/core#ConceptScheme"
+	skosInScheme      = "http://www.w3.org/2004/02/skos/core#inScheme"
+	skosHasTopConcept = "http://www.w3.org/2004/02/skos/core#hasTopConcept"
+	skosTopConceptOf  = "http://www.w3.org/2004/02/skos/core#topConceptOf"
+	skosBroader       = "http://www.w3.org/2004/02/skos/core#broader"
+	skosNarrower      = "http://www.w3.org/2004/02/skos/core#narrower"
+	skosNotation      = "http://www.w3.org/2004/02/skos/core#notation"
+)
+
+// TagScheme returns the IRI of the concept scheme that holds the tags.
+func (p *Projector) TagScheme() string {
+	return p.baseURI + "/tags"
+}
+
+// tagTriples defines the concept for tag and every tag it is nested under:
+// lang/go/generics is narrower than lang/go, which is narrower than the
+// top concept lang. Concepts are labelled with their last segment, and
+// nested ones carry the full tag as skos:notation so it can be read back.
+func (p *Projector) tagTriples(tag string, text func(string) rdf.Term) []*rdf.Triple {
+	scheme := rdf.NewResource(p.TagScheme())
+	triples := []*rdf.Triple{
+		rdf.NewTriple(scheme, rdf.NewResource(rdfType), rdf.NewResource(skosConceptScheme)),
+	}
+
+	segments := tagSegments(tag)
+	var parent rdf.Term
+	for i, segment := range segments {
+		path := strings.Join(segments[:i+1], "/")
+		concept := rdf.NewResource(makeTagURI(p.baseURI, path))
+		triples = append(triples,
+			rdf.NewTriple(concept, rdf.NewResource(rdfType), rdf.NewResource(skosConcept)),
+			rdf.NewTriple(concept, rdf.NewResource(skosPrefLabel), text(segment)),
+			rdf.NewTriple(concept, rdf.NewResource(skosInScheme), scheme),
+		)
+		if parent == nil {
+			triples = append(triples,
+				rdf.NewTriple(concept, rdf.NewResource(skosTopConceptOf), scheme),
+				rdf.NewTriple(scheme, rdf.NewResource(skosHasTopConcept), concept),
+			)
+		} else {
+			triples = append(triples,
+				rdf.NewTriple(concept, rdf.NewResource(skosNotation), rdf.NewLiteral(path)),
+				rdf.NewTriple(concept, rdf.NewResource(skosBroader), parent),
+				rdf.NewTriple(parent, rdf.NewResource(skosNarrower), concept),
+			)
+		}
+		parent = concept
+	}
+	return triples
+}
+
+// tagSegments splits a tag on slashes, ignoring empty segments. A tag of
+// only slashes is kept whole.
+func tagSegments(tag string) []string {
+	var segments []string
+	for _, s := range strings.Split(tag, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) == 0 {
+		return []string{tag}
+	}
+	return segments
+}
+
+// makeTagURI escapes each segment of a nested tag, so the IRIs of the
+// concepts follow the hierarchy.
+func makeTagURI(baseURI, tag string) string {
+	segments := tagSegments(tag)
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return baseURI + "/tags/" + strings.Join(segments, "/")
+}
+
+// isTagDefinitionTriple reports whether triple describes a tag concept or
+// the scheme rather than a note, so it can be written once per vault.
+func isTagDefinitionTriple(triple *rdf.Triple, baseURI string) bool {
+	subject, ok := triple.Subject.(*rdf.Resource)
+	if !ok {
+		return false
+	}
+	return subject.URI == baseURI+"/tags" || strings.HasPrefix(subject.URI, baseURI+"/tags/")
+}
@@ -0,0 +1,59 @@
+package rdfproj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestNoteToTriples_NestedTags(t *testing.T) {
+	note := markdown.Note{ID: "a", Title: "A", Tags: []string{"lang/go/generics", "lang/rust", "my tag"}}
+	triples := New("http://example.org", nil).NoteToTriples(note)
+
+	found := make(map[string]int)
+	for _, triple := range triples {
+		found[triple.String()]++
+	}
+	tags := "http://example.org/tags"
+	want := []string{
+		"<http://example.org/notes/a> <" + dctermsSubject + "> <" + tags + "/lang/go/generics> .",
+		"<" + tags + "> <" + rdfType + "> <" + skosConceptScheme + "> .",
+		"<" + tags + "> <" + skosHasTopConcept + "> <" + tags + "/lang> .",
+		"<" + tags + "> <" + skosHasTopConcept + "> <" + tags + "/my%20tag> .",
+		"<" + tags + "/lang> <" + skosTopConceptOf + "> <" + tags + "> .",
+		"<" + tags + "/lang/go> <" + skosBroader + "> <" + tags + "/lang> .",
+		"<" + tags + "/lang> <" + skosNarrower + "> <" + tags + "/lang/go> .",
+		"<" + tags + "/lang/go/generics> <" + skosBroader + "> <" + tags + "/lang/go> .",
+		"<" + tags + "/lang/go/generics> <" + skosPrefLabel + "> \"generics\" .",
+		"<" + tags + "/lang/go/generics> <" + skosNotation + "> \"lang/go/generics\" .",
+		"<" + tags + "/lang/go/generics> <" + skosInScheme + "> <" + tags + "> .",
+		"<" + tags + "/lang/rust> <" + skosBroader + "> <" + tags + "/lang> .",
+	}
+	for _, w := range want {
+		if found[w] != 1 {
+			t.Errorf("found %s %d times, want once", w, found[w])
+		}
+	}
+	for line := range found {
+		if strings.Contains(line, "<"+tags+"/lang> <"+skosNotation+">") {
+			t.Errorf("top concept has a notation: %s", line)
+		}
+	}
+}
+
+func TestTriplesToNotes_NestedTags(t *testing.T) {
+	p := New("http://example.org", nil)
+	notes := []markdown.Note{
+		{ID: "a", Title: "A", Type: "Note", Tags: []string{"lang/go/generics", "lang/go"}},
+		{ID: "b", Title: "B", Type: "Note", Tags: []string{"lang"}},
+	}
+
+	got, errs := p.TriplesToNotes(p.VaultToTriples(notes))
+	if len(errs) != 0 {
+		t.Fatalf("TriplesToNotes() errors = %v", errs)
+	}
+	if losses := Compare(notes, got); len(losses) != 0 {
+		t.Errorf("round trip lost %v", losses)
+	}
+}
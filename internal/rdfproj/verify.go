@@ -0,0 +1,106 @@
+package rdfproj
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+// Loss is a note field whose value did not survive a round trip through
+// the projection.
+type Loss struct {
+	ID    string
+	Field string
+	Want  string
+	Got   string
+}
+
+func (l Loss) String() string {
+	return fmt.Sprintf("%s: %s: want %q, got %q", l.ID, l.Field, l.Want, l.Got)
+}
+
+// Compare diffs original notes against the notes recovered from their
+// projection, field by field. Tag and link order are not compared, since
+// RDF graphs are unordered.
+func Compare(original, recovered []markdown.Note) []Loss {
+	byID := make(map[string]markdown.Note, len(recovered))
+	for _, n := range recovered {
+		byID[n.ID] = n
+	}
+
+	var losses []Loss
+	for _, want := range original {
+		got, ok := byID[want.ID]
+		if !ok {
+			losses = append(losses, Loss{ID: want.ID, Field: "note", Want: want.Title, Got: ""})
+			continue
+		}
+		delete(byID, want.ID)
+
+		if want.Type == "" {
+			want.Type = "Note"
+		}
+		fields := []struct {
+			name      string
+			want, got string
+		}{
+			{"title", want.Title, got.Title},
+			{"type", want.Type, got.Type},
+			{"lang", want.Lang, got.Lang},
+			{"body", want.Body, got.Body},
+			{"created", formatTime(want.Created), formatTime(got.Created)},
+			{"modified", formatTime(want.Modified), formatTime(got.Modified)},
+			{"tags", strings.Join(sortedStrings(want.Tags), ", "), strings.Join(sortedStrings(got.Tags), ", ")},
+			{"aliases", strings.Join(sortedStrings(want.Aliases), ", "), strings.Join(sortedStrings(got.Aliases), ", ")},
+			{"links", formatLinks(want.Links), formatLinks(got.Links)},
+			{"extra", formatExtra(want.Extra), formatExtra(got.Extra)},
+		}
+		for _, f := range fields {
+			if f.want != f.got {
+				losses = append(losses, Loss{ID: want.ID, Field: f.name, Want: f.want, Got: f.got})
+			}
+		}
+	}
+
+	for _, n := range recovered {
+		if _, extra := byID[n.ID]; extra {
+			losses = append(losses, Loss{ID: n.ID, Field: "note", Want: "", Got: n.Title})
+		}
+	}
+	return losses
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func sortedStrings(tags []string) []string {
+	return slices.Sorted(slices.Values(tags))
+}
+
+// formatExtra lists frontmatter keys weave does not know. They are not
+// projected, so a note that has any loses them in the round trip.
+func formatExtra(extra map[string]any) string {
+	parts := make([]string, 0, len(extra))
+	for key, value := range extra {
+		parts = append(parts, fmt.Sprintf("%s: %v", key, value))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ", ")
+}
+
+func formatLinks(ls []links.Link) string {
+	parts := make([]string, 0, len(ls))
+	for _, l := range ls {
+		parts = append(parts, l.Type+" "+l.Ref()+" "+fmt.Sprintf("%q", l.Label))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ", ")
+}
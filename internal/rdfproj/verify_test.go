@@ -0,0 +1,77 @@
+package rdfproj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestCompare(t *testing.T) {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	original := []markdown.Note{
+		{ID: "a", Title: "A", Created: ts, Tags: []string{"x", "y"}, Links: []links.Link{{ID: "b", Type: "linksTo", Label: "B"}}},
+		{ID: "h", Title: "H", Links: []links.Link{{ID: "b", Type: "linksTo", Anchor: "Design"}}},
+		{ID: "gone", Title: "Gone"},
+		{ID: "flagged", Title: "Flagged", Extra: map[string]any{"private": true, "rank": 2}},
+	}
+	recovered := []markdown.Note{
+		{ID: "a", Title: "A", Type: "Note", Created: ts, Tags: []string{"y", "x"}, Links: []links.Link{{ID: "b", Type: "linksTo"}}},
+		{ID: "h", Title: "H", Type: "Note", Links: []links.Link{{ID: "b", Type: "linksTo", Anchor: "design"}}},
+		{ID: "extra", Title: "Extra"},
+		{ID: "flagged", Title: "Flagged", Type: "Note"},
+	}
+
+	losses := Compare(original, recovered)
+	want := []Loss{
+		{ID: "a", Field: "links", Want: `linksTo b "B"`, Got: `linksTo b ""`},
+		{ID: "h", Field: "links", Want: `linksTo b#Design ""`, Got: `linksTo b#design ""`},
+		{ID: "gone", Field: "note", Want: "Gone"},
+		{ID: "flagged", Field: "extra", Want: "private: true, rank: 2"},
+		{ID: "extra", Field: "note", Got: "Extra"},
+	}
+	if len(losses) != len(want) {
+		t.Fatalf("Compare() = %v, want %v", losses, want)
+	}
+	for i := range want {
+		if losses[i] != want[i] {
+			t.Errorf("Compare()[%d] = %v, want %v", i, losses[i], want[i])
+		}
+	}
+}
+
+func TestNoteToTriples_ReifiesLabelledLinks(t *testing.T) {
+	note := markdown.Note{
+		ID:    "a-20250101000000",
+		Title: "A",
+		Links: []links.Link{
+			{ID: "b-20250101000000", Type: "related", Label: "see B"},
+			{ID: "c-20250101000000", Type: "linksTo"},
+		},
+	}
+
+	var nodes, labels int
+	for _, triple := range NoteToTriples(note, "http://example.org") {
+		if triple.Object.RawValue() == weaveLink {
+			nodes++
+		}
+		if triple.Predicate.RawValue() == rdfsLabel {
+			labels++
+			if triple.Object.RawValue() != "see B" {
+				t.Errorf("label = %q, want %q", triple.Object.RawValue(), "see B")
+			}
+		}
+	}
+	if nodes != 1 || labels != 1 {
+		t.Errorf("got %d link nodes and %d labels, want 1 each", nodes, labels)
+	}
+
+	got, errs := TriplesToNotes(NoteToTriples(note, "http://example.org"), "http://example.org")
+	if len(errs) > 0 || len(got) != 1 {
+		t.Fatalf("TriplesToNotes() = %v, %v", got, errs)
+	}
+	if losses := Compare([]markdown.Note{note}, got); len(losses) > 0 {
+		t.Errorf("round trip lost %v", losses)
+	}
+}
@@ -0,0 +1,346 @@
+package rdfproj
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// VocabularySpec is the user-facing description of a vocabulary, as
+// written in the vault config. Terms may be full IRIs or prefixed names
+// using the declared or built-in prefixes.
+type VocabularySpec struct {
+	Prefixes  map[string]string   `yaml:"prefixes"`
+	Relations map[string]string   `yaml:"relations"`
+	Types     map[string][]string `yaml:"types"`
+	// Inverses pairs relation types whose links imply each other in the
+	// opposite direction; one entry covers both directions.
+	Inverses map[string]string `yaml:"inverses"`
+	// Symmetric lists relation types whose links hold in both directions.
+	Symmetric []string `yaml:"symmetric"`
+}
+
+// Vocabulary maps relationship types and note types to RDF terms. The
+// zero value is not usable; build one with NewVocabulary.
+type Vocabulary struct {
+	prefixes  map[string]string
+	relations map[string]string
+	byPred    map[string]string
+	types     map[string][]string
+	inverses  map[string]string
+	symmetric map[string]bool
+}
+
+var builtinPrefixes = map[string]string{
+	"rdf":     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":    "http://www.w3.org/2000/01/rdf-schema#",
+	"xsd":     "http://www.w3.org/2001/XMLSchema#",
+	"dcterms": "http://purl.org/dc/terms/",
+	"skos":    "http://www.w3.org/2004/02/skos/core#",
+	"schema":  "http://schema.org/",
+	"weave":   weaveVocab,
+}
+
+var builtinSpec = VocabularySpec{
+	Relations: map[string]string{
+		"linksTo":  "weave:linksTo",
+		"related":  "skos:related",
+		"broader":  "skos:broader",
+		"narrower": "skos:narrower",
+		"seeAlso":  "rdfs:seeAlso",
+	},
+	Types: map[string][]string{
+		"Note": {"weave:Note", "schema:Article"},
+	},
+	Inverses:  map[string]string{"broader": "narrower"},
+	Symmetric: []string{"related"},
+}
+
+var (
+	prefixNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	termNamePattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+)
+
+var defaultVocabulary = mustVocabulary(VocabularySpec{})
+
+// DefaultVocabulary returns the built-in vocabulary.
+func DefaultVocabulary() *Vocabulary {
+	return defaultVocabulary
+}
+
+func mustVocabulary(spec VocabularySpec) *Vocabulary {
+	v, err := NewVocabulary(spec)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// NewVocabulary layers spec over the built-in vocabulary and validates the
+// result: every term must expand to an absolute IRI, and the mapping must
+// stay reversible so imports recover the original types.
+func NewVocabulary(spec VocabularySpec) (*Vocabulary, error) {
+	v := &Vocabulary{
+		prefixes:  make(map[string]string),
+		relations: make(map[string]string),
+		byPred:    make(map[string]string),
+		types:     make(map[string][]string),
+		inverses:  make(map[string]string),
+		symmetric: make(map[string]bool),
+	}
+
+	for name, iri := range builtinPrefixes {
+		v.prefixes[name] = iri
+	}
+	for _, name := range sortedKeys(spec.Prefixes) {
+		iri := spec.Prefixes[name]
+		if !prefixNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("vocabulary: invalid prefix name %q", name)
+		}
+		if !isAbsoluteIRI(iri) {
+			return nil, fmt.Errorf("vocabulary: prefix %s: %q is not an absolute IRI", name, iri)
+		}
+		v.prefixes[name] = iri
+	}
+
+	relations := merged(builtinSpec.Relations, spec.Relations)
+	for _, name := range sortedKeys(relations) {
+		if !termNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("vocabulary: invalid relation name %q", name)
+		}
+		iri, err := v.Expand(relations[name])
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary: relation %s: %w", name, err)
+		}
+		if other, ok := v.byPred[iri]; ok {
+			return nil, fmt.Errorf("vocabulary: relations %s and %s both map to %s", other, name, iri)
+		}
+		v.relations[name] = iri
+		v.byPred[iri] = name
+	}
+
+	types := make(map[string][]string)
+	for name, classes := range builtinSpec.Types {
+		types[name] = classes
+	}
+	for name, classes := range spec.Types {
+		types[name] = classes
+	}
+	seenClasses := make(map[string]string)
+	for _, name := range sortedKeys(types) {
+		if !termNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("vocabulary: invalid type name %q", name)
+		}
+		if len(types[name]) == 0 {
+			return nil, fmt.Errorf("vocabulary: type %s has no classes", name)
+		}
+		var classes []string
+		for _, class := range types[name] {
+			iri, err := v.Expand(class)
+			if err != nil {
+				return nil, fmt.Errorf("vocabulary: type %s: %w", name, err)
+			}
+			classes = append(classes, iri)
+		}
+		key := strings.Join(slices.Sorted(slices.Values(classes)), " ")
+		if other, ok := seenClasses[key]; ok {
+			return nil, fmt.Errorf("vocabulary: types %s and %s have the same classes", other, name)
+		}
+		seenClasses[key] = name
+		v.types[name] = classes
+	}
+
+	inverses := merged(builtinSpec.Inverses, spec.Inverses)
+	for _, name := range sortedKeys(inverses) {
+		a, err := v.relationIRI(name)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary: inverse: %w", err)
+		}
+		b, err := v.relationIRI(inverses[name])
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary: inverse of %s: %w", name, err)
+		}
+		for _, pair := range [][2]string{{a, b}, {b, a}} {
+			if prev, ok := v.inverses[pair[0]]; ok && prev != pair[1] {
+				return nil, fmt.Errorf("vocabulary: %s has conflicting inverses %s and %s", v.byPred[pair[0]], v.byPred[prev], v.byPred[pair[1]])
+			}
+			v.inverses[pair[0]] = pair[1]
+		}
+	}
+
+	for _, name := range slices.Concat(builtinSpec.Symmetric, spec.Symmetric) {
+		iri, err := v.relationIRI(name)
+		if err != nil {
+			return nil, fmt.Errorf("vocabulary: symmetric: %w", err)
+		}
+		if inv, ok := v.inverses[iri]; ok && inv != iri {
+			return nil, fmt.Errorf("vocabulary: %s is symmetric but has inverse %s", name, v.byPred[inv])
+		}
+		v.symmetric[iri] = true
+	}
+
+	return v, nil
+}
+
+func (v *Vocabulary) relationIRI(name string) (string, error) {
+	iri, ok := v.relations[name]
+	if !ok {
+		return "", fmt.Errorf("unknown relation %q", name)
+	}
+	return iri, nil
+}
+
+// Expand resolves a prefixed name against the vocabulary's prefixes. Full
+// IRIs, optionally in angle brackets, are returned unchanged.
+func (v *Vocabulary) Expand(term string) (string, error) {
+	term = strings.TrimSpace(term)
+	if strings.HasPrefix(term, "<") && strings.HasSuffix(term, ">") {
+		term = term[1 : len(term)-1]
+	}
+	if prefix, local, ok := strings.Cut(term, ":"); ok {
+		if ns, known := v.prefixes[prefix]; known && !strings.HasPrefix(local, "//") {
+			return ns + local, nil
+		}
+	}
+	if !isAbsoluteIRI(term) {
+		return "", fmt.Errorf("%q is neither an absolute IRI nor a known prefixed name", term)
+	}
+	return term, nil
+}
+
+// Prefixes returns the declared and built-in prefixes.
+func (v *Vocabulary) Prefixes() map[string]string {
+	out := make(map[string]string, len(v.prefixes))
+	for name, iri := range v.prefixes {
+		out[name] = iri
+	}
+	return out
+}
+
+// Predicate returns the IRI for a relationship type. Unmapped types fall
+// back to the weave namespace.
+func (v *Vocabulary) Predicate(relType string) string {
+	if pred, ok := v.relations[relType]; ok {
+		return pred
+	}
+	return weaveVocab + relType
+}
+
+// RelationType reverses Predicate.
+func (v *Vocabulary) RelationType(predicate string) (string, bool) {
+	if relType, ok := v.byPred[predicate]; ok {
+		return relType, true
+	}
+	return strings.CutPrefix(predicate, weaveVocab)
+}
+
+// Relations returns the mapped relationship types and their predicates.
+func (v *Vocabulary) Relations() map[string]string {
+	out := make(map[string]string, len(v.relations))
+	for name, iri := range v.relations {
+		out[name] = iri
+	}
+	return out
+}
+
+// Classes returns the class IRIs for a note type. Unmapped types fall back
+// to the weave namespace.
+func (v *Vocabulary) Classes(noteType string) []string {
+	if classes, ok := v.types[noteType]; ok {
+		return classes
+	}
+	return []string{weaveVocab + noteType}
+}
+
+// Types returns the mapped note types and their classes.
+func (v *Vocabulary) Types() map[string][]string {
+	out := make(map[string][]string, len(v.types))
+	for name, classes := range v.types {
+		out[name] = slices.Clone(classes)
+	}
+	return out
+}
+
+// NoteType picks the note type whose classes are all among classes,
+// preferring the most specific mapping. Unmapped weave classes are
+// recognised by name.
+func (v *Vocabulary) NoteType(classes []string) (string, bool) {
+	best, bestLen := "", 0
+	for _, name := range sortedKeys(v.types) {
+		mapped := v.types[name]
+		if len(mapped) <= bestLen {
+			continue
+		}
+		matched := true
+		for _, class := range mapped {
+			if !slices.Contains(classes, class) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best, bestLen = name, len(mapped)
+		}
+	}
+	if best != "" {
+		return best, true
+	}
+	for _, class := range classes {
+		if name, ok := strings.CutPrefix(class, weaveVocab); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Inverse returns the predicate declared inverse to predicate.
+func (v *Vocabulary) Inverse(predicate string) (string, bool) {
+	inv, ok := v.inverses[predicate]
+	return inv, ok
+}
+
+// Symmetric reports whether predicate is declared symmetric.
+func (v *Vocabulary) Symmetric(predicate string) bool {
+	return v.symmetric[predicate]
+}
+
+// opaqueSchemes are the schemes accepted without "//". Anything else of
+// the form "x:y" is far more likely a prefixed name with a typo.
+var opaqueSchemes = map[string]bool{"urn": true, "tag": true, "mailto": true}
+
+func isAbsoluteIRI(s string) bool {
+	if strings.ContainsAny(s, " <>\"{}|\\^`") {
+		return false
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return false
+	}
+	if u.Opaque != "" {
+		return opaqueSchemes[strings.ToLower(u.Scheme)]
+	}
+	return u.Host != ""
+}
+
+func merged(base, over map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(over))
+	for k, val := range base {
+		out[k] = val
+	}
+	for k, val := range over {
+		out[k] = val
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
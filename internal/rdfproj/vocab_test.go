@@ -0,0 +1,126 @@
+package rdfproj
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+const (
+	cito = "http://purl.org/spar/cito/"
+	foaf = "http://xmlns.com/foaf/0.1/"
+)
+
+func customVocabulary(t *testing.T) *Vocabulary {
+	t.Helper()
+	v, err := NewVocabulary(VocabularySpec{
+		Prefixes:  map[string]string{"cito": cito, "foaf": foaf},
+		Relations: map[string]string{"cites": "cito:cites", "citedBy": "<" + cito + "isCitedBy>"},
+		Types:     map[string][]string{"Person": {"foaf:Person"}},
+		Inverses:  map[string]string{"cites": "citedBy"},
+	})
+	if err != nil {
+		t.Fatalf("NewVocabulary() error = %v", err)
+	}
+	return v
+}
+
+func TestVocabularyMappings(t *testing.T) {
+	v := customVocabulary(t)
+
+	if got := v.Predicate("cites"); got != cito+"cites" {
+		t.Errorf("Predicate(cites) = %q", got)
+	}
+	if got := v.Predicate("broader"); got != "http://www.w3.org/2004/02/skos/core#broader" {
+		t.Errorf("built-in Predicate(broader) = %q", got)
+	}
+	if got := v.Predicate("unknown"); got != weaveVocab+"unknown" {
+		t.Errorf("fallback Predicate(unknown) = %q", got)
+	}
+	if got, _ := v.RelationType(cito + "isCitedBy"); got != "citedBy" {
+		t.Errorf("RelationType(isCitedBy) = %q", got)
+	}
+	if got := v.Classes("Person"); !reflect.DeepEqual(got, []string{foaf + "Person"}) {
+		t.Errorf("Classes(Person) = %v", got)
+	}
+	if inv, _ := v.Inverse(cito + "isCitedBy"); inv != cito+"cites" {
+		t.Errorf("Inverse(isCitedBy) = %q, want declared pair in both directions", inv)
+	}
+	if !v.Symmetric("http://www.w3.org/2004/02/skos/core#related") {
+		t.Error("related is not symmetric by default")
+	}
+}
+
+func TestVocabularyNoteType(t *testing.T) {
+	v := customVocabulary(t)
+
+	tests := []struct {
+		classes []string
+		want    string
+	}{
+		{[]string{weaveVocab + "Note", "http://schema.org/Article"}, "Note"},
+		{[]string{foaf + "Person"}, "Person"},
+		{[]string{weaveVocab + "Idea"}, "Idea"},
+		{[]string{"http://schema.org/Article"}, ""},
+	}
+	for _, tt := range tests {
+		if got, _ := v.NoteType(tt.classes); got != tt.want {
+			t.Errorf("NoteType(%v) = %q, want %q", tt.classes, got, tt.want)
+		}
+	}
+}
+
+func TestNewVocabularyValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		spec VocabularySpec
+		want string
+	}{
+		{"relative prefix", VocabularySpec{Prefixes: map[string]string{"x": "not-an-iri"}}, "absolute IRI"},
+		{"unknown prefix", VocabularySpec{Relations: map[string]string{"cites": "cito:cites"}}, "known prefixed name"},
+		{"duplicate predicate", VocabularySpec{Relations: map[string]string{"up": "skos:broader"}}, "both map to"},
+		{"empty type", VocabularySpec{Types: map[string][]string{"Person": nil}}, "no classes"},
+		{"duplicate classes", VocabularySpec{Types: map[string][]string{"Article": {"schema:Article", "weave:Note"}}}, "same classes"},
+		{"unknown inverse", VocabularySpec{Inverses: map[string]string{"cites": "related"}}, "unknown relation"},
+		{"conflicting inverse", VocabularySpec{Inverses: map[string]string{"narrower": "seeAlso"}}, "conflicting inverses"},
+		{"symmetric with inverse", VocabularySpec{Symmetric: []string{"broader"}}, "symmetric but has inverse"},
+		{"opaque IRI", VocabularySpec{Relations: map[string]string{"x": "urn:x:y", "y": "foo:bar"}}, "known prefixed name"},
+		{"bad relation name", VocabularySpec{Relations: map[string]string{"has space": "weave:x"}}, "invalid relation name"},
+	}
+	for _, tt := range tests {
+		_, err := NewVocabulary(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: NewVocabulary() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestProjectorUsesVocabulary(t *testing.T) {
+	p := New("http://example.org", customVocabulary(t))
+	note := markdown.Note{
+		ID:    "ada-20250101000000",
+		Title: "Ada",
+		Type:  "Person",
+		Links: []links.Link{{ID: "paper-20250101000000", Type: "cites"}},
+	}
+
+	var sawClass, sawLink bool
+	for _, triple := range p.NoteToTriples(note) {
+		sawClass = sawClass || triple.Object.RawValue() == foaf+"Person"
+		sawLink = sawLink || triple.Predicate.RawValue() == cito+"cites"
+	}
+	if !sawClass || !sawLink {
+		t.Errorf("projection missing foaf:Person (%v) or cito:cites (%v)", sawClass, sawLink)
+	}
+
+	got, errs := p.TriplesToNotes(p.NoteToTriples(note))
+	if len(errs) > 0 || len(got) != 1 {
+		t.Fatalf("TriplesToNotes() = %v, %v", got, errs)
+	}
+	if losses := Compare([]markdown.Note{note}, got); len(losses) > 0 {
+		t.Errorf("round trip lost %v", losses)
+	}
+}
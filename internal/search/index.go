@@ -0,0 +1,72 @@
+package search
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+// Index keeps loaded notes in memory, keyed by file path, so searches can be
+// answered without rereading the vault and kept current one file at a time.
+// It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	entries map[string]notes.Entry
+}
+
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]notes.Entry)}
+}
+
+func (ix *Index) Set(entry notes.Entry) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.entries[entry.Path] = entry
+}
+
+func (ix *Index) Remove(path string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	delete(ix.entries, path)
+}
+
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+// Entries returns the indexed notes in path order.
+func (ix *Index) Entries() []notes.Entry {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	out := make([]notes.Entry, 0, len(ix.entries))
+	for _, e := range ix.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// Search scores the indexed notes the same way Search scores the vault.
+func (ix *Index) Search(query Query) []Result {
+	var results []Result
+
+	for _, entry := range ix.Entries() {
+		if !query.Filter.Match(entry) {
+			continue
+		}
+		score := scoreNote(entry.Note, query)
+		if score > 0 {
+			results = append(results, Result{
+				Note:  entry.Note,
+				Score: score,
+			})
+		}
+	}
+
+	sortResults(results)
+	return results
+}
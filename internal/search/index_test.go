@@ -0,0 +1,39 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+)
+
+func TestIndexSearch(t *testing.T) {
+	ix := NewIndex()
+	ix.Set(notes.Entry{Path: "/vault/2025/01/a.md", Note: markdown.Note{ID: "a", Title: "Test note"}})
+	ix.Set(notes.Entry{Path: "/vault/2025/02/b.md", Note: markdown.Note{ID: "b", Title: "Other", Tags: []string{"test"}}})
+
+	results := ix.Search(Query{Term: "test"})
+	if len(results) != 2 {
+		t.Fatalf("Index.Search() returned %d results, want 2", len(results))
+	}
+
+	results = ix.Search(Query{Term: "test", Filter: notes.Filter{Year: 2025, Month: 2}})
+	if len(results) != 1 || results[0].Note.ID != "b" {
+		t.Fatalf("Index.Search() with month filter = %v, want only b", results)
+	}
+}
+
+func TestIndexUpdateAndRemove(t *testing.T) {
+	ix := NewIndex()
+	ix.Set(notes.Entry{Path: "/vault/2025/01/a.md", Note: markdown.Note{ID: "a", Title: "Test note"}})
+	ix.Set(notes.Entry{Path: "/vault/2025/01/a.md", Note: markdown.Note{ID: "a", Title: "Renamed"}})
+
+	if results := ix.Search(Query{Term: "test"}); len(results) != 0 {
+		t.Errorf("Index.Search() after update returned %d results, want 0", len(results))
+	}
+
+	ix.Remove("/vault/2025/01/a.md")
+	if ix.Len() != 0 {
+		t.Errorf("Index.Len() after remove = %d, want 0", ix.Len())
+	}
+}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -11,6 +13,13 @@ import (
 
 type Query struct {
 	Term string
+	// Strict aborts the search on the first unreadable note instead of
+	// skipping it.
+	Strict bool
+	Filter notes.Filter
+	// Lang is the language of notes that do not set their own. It picks
+	// how text and the term are split into words.
+	Lang string
 }
 
 type Result struct {
@@ -18,48 +27,125 @@ type Result struct {
 	Score int
 }
 
-func Search(vaultPath string, query Query) ([]Result, error) {
-	allNotes, errs := notes.List(vaultPath)
-	if len(errs) > 0 {
-		return nil, fmt.Errorf("search failed with %d errors: %w", len(errs), errs[0])
-	}
+// Skipped describes a vault file that was left out of a search.
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+func Search(vaultPath string, query Query) ([]Result, []Skipped, error) {
+	return SearchContext(context.Background(), vaultPath, query)
+}
 
+// SearchContext streams the vault through the scorer so only matching notes
+// are kept in memory.
+func SearchContext(ctx context.Context, vaultPath string, query Query) ([]Result, []Skipped, error) {
 	var results []Result
-	term := strings.ToLower(query.Term)
+	var errs []error
 
-	for _, note := range allNotes {
-		score := scoreNote(note, term)
+	for entry, err := range notes.All(ctx, vaultPath, query.Filter) {
+		if err != nil {
+			if query.Strict {
+				return nil, nil, fmt.Errorf("search failed: %w", err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		score := scoreNote(entry.Note, query)
 		if score > 0 {
 			results = append(results, Result{
-				Note:  note,
+				Note:  entry.Note,
 				Score: score,
 			})
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("search failed: %w", err)
+	}
+
 	sortResults(results)
-	return results, nil
+	return results, toSkipped(errs), nil
 }
 
-func scoreNote(note markdown.Note, term string) int {
+// scoreNote counts the term's occurrences, with text and term tokenized in
+// the note's language. A term that tokenizing would change, such as "c++",
+// is matched as written instead, only case-folded. tag: qualifiers in the
+// term must all match the note's tags; a term of only qualifiers scores
+// every matching note 1.
+func scoreNote(note markdown.Note, query Query) int {
+	words, tags := parseTerm(query.Term)
+	for _, tag := range tags {
+		if !notes.HasTag(note.Tags, tag) {
+			return 0
+		}
+	}
+	if words == "" {
+		if len(tags) > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	lang := note.Lang
+	if lang == "" {
+		lang = query.Lang
+	}
+	prepare := func(text string) string { return normalize(text, lang) }
+	term := normalize(words, lang)
+	if folded := fold(words, lang); term != folded {
+		prepare = func(text string) string { return fold(text, lang) }
+		term = folded
+	}
+
 	score := 0
-	
-	score += strings.Count(strings.ToLower(note.Title), term)
-	score += strings.Count(strings.ToLower(note.Body), term)
-	
+	score += countMatches(prepare(note.Title), term)
+	score += countMatches(prepare(note.Body), term)
+
 	for _, tag := range note.Tags {
-		score += strings.Count(strings.ToLower(tag), term)
+		score += countMatches(prepare(tag), term)
+	}
+
+	for _, alias := range note.Aliases {
+		score += countMatches(prepare(alias), term)
 	}
-	
+
 	for _, link := range note.Links {
-		score += strings.Count(strings.ToLower(link.ID), term)
+		score += countMatches(prepare(link.ID), term)
 	}
-	
+
 	return score
 }
 
+// parseTerm splits tag:name qualifiers from the words of a search term.
+func parseTerm(term string) (string, []string) {
+	var words, tags []string
+	for _, field := range strings.Fields(term) {
+		if tag, ok := strings.CutPrefix(field, "tag:"); ok && tag != "" {
+			tags = append(tags, tag)
+			continue
+		}
+		words = append(words, field)
+	}
+	return strings.Join(words, " "), tags
+}
+
 func sortResults(results []Result) {
-	sort.Slice(results, func(i, j int) bool {
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 }
+
+func toSkipped(errs []error) []Skipped {
+	var skipped []Skipped
+	for _, err := range errs {
+		var loadErr *notes.LoadError
+		if errors.As(err, &loadErr) {
+			skipped = append(skipped, Skipped{Path: loadErr.Path, Reason: loadErr.Err.Error()})
+			continue
+		}
+		skipped = append(skipped, Skipped{Reason: err.Error()})
+	}
+	return skipped
+}
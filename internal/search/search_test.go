@@ -1,22 +1,25 @@
 package search
 
 import (
+	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/DeDude/weave2/internal/links"
 	"github.com/DeDude/weave2/internal/markdown"
 	"github.com/DeDude/weave2/internal/notes"
-	"github.com/DeDude/weave2/internal/links"
 )
 
 func TestSearchEmpty(t *testing.T) {
 	vaultPath := t.TempDir()
-	
-	results, err := Search(vaultPath, Query{Term: "test"})
+
+	results, _, err := Search(vaultPath, Query{Term: "test"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 0 {
 		t.Errorf("Search() returned %d results, want 0", len(results))
 	}
@@ -24,27 +27,27 @@ func TestSearchEmpty(t *testing.T) {
 
 func TestSearchTitle(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note := markdown.Note{
 		Title: "Test Note",
 		Body:  "Some content",
 	}
-	
+
 	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	_, err := notes.Create(vaultPath, note, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
-	results, err := Search(vaultPath, Query{Term: "test"})
+
+	results, _, err := Search(vaultPath, Query{Term: "test"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 1 {
 		t.Fatalf("Search() returned %d results, want 1", len(results))
 	}
-	
+
 	if results[0].Note.Title != "Test Note" {
 		t.Errorf("Result title = %q, want %q", results[0].Note.Title, "Test Note")
 	}
@@ -52,23 +55,23 @@ func TestSearchTitle(t *testing.T) {
 
 func TestSearchBody(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note := markdown.Note{
 		Title: "My Note",
 		Body:  "This contains the keyword test",
 	}
-	
+
 	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	_, err := notes.Create(vaultPath, note, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
-	results, err := Search(vaultPath, Query{Term: "keyword"})
+
+	results, _, err := Search(vaultPath, Query{Term: "keyword"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 1 {
 		t.Fatalf("Search() returned %d results, want 1", len(results))
 	}
@@ -76,24 +79,24 @@ func TestSearchBody(t *testing.T) {
 
 func TestSearchTags(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note := markdown.Note{
 		Title: "My Note",
 		Body:  "Content",
 		Tags:  []string{"golang", "testing"},
 	}
-	
+
 	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	_, err := notes.Create(vaultPath, note, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
-	results, err := Search(vaultPath, Query{Term: "golang"})
+
+	results, _, err := Search(vaultPath, Query{Term: "golang"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 1 {
 		t.Fatalf("Search() returned %d results, want 1", len(results))
 	}
@@ -101,23 +104,23 @@ func TestSearchTags(t *testing.T) {
 
 func TestSearchCaseInsensitive(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note := markdown.Note{
 		Title: "Test Note",
 		Body:  "Content",
 	}
-	
+
 	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	_, err := notes.Create(vaultPath, note, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
-	results, err := Search(vaultPath, Query{Term: "TEST"})
+
+	results, _, err := Search(vaultPath, Query{Term: "TEST"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 1 {
 		t.Fatalf("Search() returned %d results, want 1", len(results))
 	}
@@ -125,38 +128,38 @@ func TestSearchCaseInsensitive(t *testing.T) {
 
 func TestSearchScoring(t *testing.T) {
 	vaultPath := t.TempDir()
-	
+
 	note1 := markdown.Note{
 		Title: "Test",
 		Body:  "Content",
 	}
-	
+
 	note2 := markdown.Note{
 		Title: "Test test test",
 		Body:  "More test content",
 	}
-	
+
 	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 	_, err := notes.Create(vaultPath, note1, ts)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
+
 	ts2 := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
 	_, err = notes.Create(vaultPath, note2, ts2)
 	if err != nil {
 		t.Fatalf("Create() error = %v", err)
 	}
-	
-	results, err := Search(vaultPath, Query{Term: "test"})
+
+	results, _, err := Search(vaultPath, Query{Term: "test"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
-	
+
 	if len(results) != 2 {
 		t.Fatalf("Search() returned %d results, want 2", len(results))
 	}
-	
+
 	if results[0].Score <= results[1].Score {
 		t.Errorf("Results not sorted by score: [0].Score=%d, [1].Score=%d", results[0].Score, results[1].Score)
 	}
@@ -167,7 +170,7 @@ func TestSearchLinks(t *testing.T) {
 
 	note := markdown.Note{
 		Title: "My Note",
-		Body: "Content",
+		Body:  "Content",
 		Links: []links.Link{
 			{ID: "target-note=123", Type: "linksTo", Label: ""},
 		},
@@ -181,7 +184,36 @@ func TestSearchLinks(t *testing.T) {
 		t.Fatalf("Create() error = %v", err)
 	}
 
-	results, err := Search(vaultPath, Query{Term: "target"})
+	results, _, err := Search(vaultPath, Query{Term: "target"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("Search() returned %d results, want 1", len(results))
+	}
+}
+
+func TestSearchSkipsBadFiles(t *testing.T) {
+	vaultPath := t.TempDir()
+
+	note := markdown.Note{
+		Title: "Test Note",
+		Body:  "Content",
+	}
+
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := notes.Create(vaultPath, note, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	badFilePath := vaultPath + "/2025/01/bad-file.md"
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	results, skipped, err := Search(vaultPath, Query{Term: "test"})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -189,4 +221,101 @@ func TestSearchLinks(t *testing.T) {
 	if len(results) != 1 {
 		t.Fatalf("Search() returned %d results, want 1", len(results))
 	}
+
+	if len(skipped) != 1 {
+		t.Fatalf("Search() skipped %d files, want 1", len(skipped))
+	}
+
+	if skipped[0].Path != badFilePath {
+		t.Errorf("Skipped path = %q, want %q", skipped[0].Path, badFilePath)
+	}
+
+	if skipped[0].Reason == "" {
+		t.Error("Skipped reason is empty")
+	}
+}
+
+func TestSearchStrictFailsOnBadFile(t *testing.T) {
+	vaultPath := t.TempDir()
+
+	if err := os.MkdirAll(vaultPath+"/2025/01", 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+
+	badFilePath := vaultPath + "/2025/01/bad-file.md"
+	if err := os.WriteFile(badFilePath, []byte("not valid markdown"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	_, _, err := Search(vaultPath, Query{Term: "test", Strict: true})
+	if err == nil {
+		t.Fatal("Search() error = nil, want error in strict mode")
+	}
+}
+
+func TestSearchFilter(t *testing.T) {
+	vaultPath := t.TempDir()
+
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vaultPath, markdown.Note{Title: "Test Go", Tags: []string{"go"}}, ts); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ts2 := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := notes.Create(vaultPath, markdown.Note{Title: "Test Rdf", Tags: []string{"rdf"}}, ts2); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	results, _, err := Search(vaultPath, Query{Term: "test", Filter: notes.Filter{Tag: "rdf"}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Note.Title != "Test Rdf" {
+		t.Fatalf("Search() with tag filter = %v, want only Test Rdf", results)
+	}
+
+	results, _, err = Search(vaultPath, Query{Term: "test", Filter: notes.Filter{Year: 2025, Month: 1}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Note.Title != "Test Go" {
+		t.Fatalf("Search() with month filter = %v, want only Test Go", results)
+	}
+}
+
+func TestSearchTagQualifier(t *testing.T) {
+	vaultPath := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, note := range []markdown.Note{
+		{Title: "Generics", Body: "type parameters", Tags: []string{"lang/go/generics"}},
+		{Title: "Channels", Body: "goroutines", Tags: []string{"lang/go"}},
+		{Title: "Traits", Body: "type classes", Tags: []string{"lang/rust"}},
+	} {
+		if _, err := notes.Create(vaultPath, note, ts); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	titles := func(term string) []string {
+		t.Helper()
+		results, _, err := Search(vaultPath, Query{Term: term})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Note.Title)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	if got := titles("tag:lang/go"); strings.Join(got, ",") != "Channels,Generics" {
+		t.Errorf("tag:lang/go = %v, want Channels and Generics", got)
+	}
+	if got := titles("type tag:lang/go"); strings.Join(got, ",") != "Generics" {
+		t.Errorf("type tag:lang/go = %v, want Generics", got)
+	}
+	if got := titles("tag:lang"); len(got) != 3 {
+		t.Errorf("tag:lang = %v, want all three", got)
+	}
 }
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scripts written without spaces between words. Each character is its own
+// token, so a query matches a run of them.
+var unspaced = []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar}
+
+// lowerer returns the lower-casing rules of lang, a BCP 47 tag.
+func lowerer(lang string) func(rune) rune {
+	switch primary, _, _ := strings.Cut(strings.ToLower(lang), "-"); primary {
+	case "tr", "az":
+		// Dotted and dotless i are different letters.
+		return unicode.TurkishCase.ToLower
+	case "el":
+		return func(r rune) rune {
+			if r = unicode.ToLower(r); r == 'ς' {
+				return 'σ'
+			}
+			return r
+		}
+	}
+	return unicode.ToLower
+}
+
+// fold lower-cases text by the rules of lang and leaves everything else,
+// punctuation included, as it is.
+func fold(text, lang string) string {
+	return strings.Map(lowerer(lang), text)
+}
+
+// tokenize splits text into words and lower-cases them by the rules of
+// lang, a BCP 47 tag.
+func tokenize(text, lang string) []string {
+	lower := lowerer(lang)
+
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unspaced...):
+			flush()
+			tokens = append(tokens, string(lower(r)))
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			word.WriteRune(lower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// normalize joins the tokens of text with single spaces, so text and a
+// term normalized the same way can be compared as plain strings.
+func normalize(text, lang string) string {
+	return strings.Join(tokenize(text, lang), " ")
+}
+
+// countMatches counts the occurrences of term in text, both prepared the
+// same way. Matching is by substring, so a term can match inside a word.
+func countMatches(text, term string) int {
+	if term == "" {
+		return 0
+	}
+	return strings.Count(text, term)
+}
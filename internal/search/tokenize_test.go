@@ -0,0 +1,57 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text, lang string
+		want       []string
+	}{
+		{"Hello, World! (v2)", "", []string{"hello", "world", "v2"}},
+		{"İstanbul ISPARTA", "tr", []string{"istanbul", "ısparta"}},
+		{"ISPARTA", "en", []string{"isparta"}},
+		{"ΟΔΟΣ", "el", []string{"οδοσ"}},
+		{"東京タワー", "ja", []string{"東", "京", "タ", "ワ", "ー"}},
+		{"café naïve", "fr", []string{"café", "naïve"}},
+	}
+	for _, tt := range tests {
+		if got := tokenize(tt.text, tt.lang); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestScoreNoteLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		note  markdown.Note
+		query Query
+		want  int
+	}{
+		{"inside words", markdown.Note{Body: "concatenate cat catalog"}, Query{Term: "at"}, 4},
+		{"end of a word", markdown.Note{Title: "Graph"}, Query{Term: "raph"}, 1},
+		{"phrase across punctuation", markdown.Note{Body: "red fox, red hen"}, Query{Term: "fox red"}, 1},
+		{"partial phrase", markdown.Note{Body: "red fox, red hen"}, Query{Term: "red f"}, 1},
+		{"turkish from note", markdown.Note{Lang: "tr", Body: "ISPARTA"}, Query{Term: "ısparta"}, 1},
+		{"turkish from default", markdown.Note{Body: "ISPARTA"}, Query{Term: "ısparta", Lang: "tr"}, 1},
+		{"note overrides default", markdown.Note{Lang: "en", Body: "ISPARTA"}, Query{Term: "ısparta", Lang: "tr"}, 0},
+		{"unspaced script", markdown.Note{Lang: "ja", Body: "東京タワーに行った"}, Query{Term: "京タワー"}, 1},
+		{"unspaced script in any language", markdown.Note{Body: "東京タワーに行った"}, Query{Term: "京タ"}, 1},
+		{"punctuated term", markdown.Note{Body: "C++ and c, not C#"}, Query{Term: "c++"}, 1},
+		{"punctuation is not dropped", markdown.Note{Title: "Bob", Body: "a b c"}, Query{Term: "b+"}, 0},
+		{"punctuated term folds case", markdown.Note{Lang: "tr", Body: "İ.O."}, Query{Term: "i.o."}, 1},
+		{"punctuated term in tags", markdown.Note{Tags: []string{"lang/c++"}}, Query{Term: "/c++"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scoreNote(tt.note, tt.query); got != tt.want {
+				t.Errorf("scoreNote() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "embed"
+
+	"github.com/DeDude/weave2/internal/graph"
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/search"
+	"github.com/DeDude/weave2/internal/watch"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type linkJSON struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label,omitempty"`
+	// Anchor and Block point the link at a heading or ^block in the target.
+	Anchor string `json:"anchor,omitempty"`
+	Block  string `json:"block,omitempty"`
+}
+
+type noteJSON struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Type     string     `json:"type"`
+	Lang     string     `json:"lang,omitempty"`
+	Tags     []string   `json:"tags"`
+	Aliases  []string   `json:"aliases,omitempty"`
+	Body     string     `json:"body"`
+	Created  time.Time  `json:"created"`
+	Modified time.Time  `json:"modified"`
+	Links    []linkJSON `json:"links"`
+}
+
+// noteInput is the writable subset of a note accepted by POST and PUT.
+type noteInput struct {
+	Title   string     `json:"title"`
+	Type    string     `json:"type"`
+	Lang    string     `json:"lang"`
+	Tags    []string   `json:"tags"`
+	Aliases []string   `json:"aliases"`
+	Body    string     `json:"body"`
+	Links   []linkJSON `json:"links"`
+}
+
+type edgeJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+func toNoteJSON(n markdown.Note) noteJSON {
+	out := noteJSON{
+		ID:       n.ID,
+		Title:    n.Title,
+		Type:     n.Type,
+		Lang:     n.Lang,
+		Tags:     n.Tags,
+		Aliases:  n.Aliases,
+		Body:     n.Body,
+		Created:  n.Created,
+		Modified: n.Modified,
+		Links:    []linkJSON{},
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	for _, l := range n.Links {
+		out.Links = append(out.Links, linkJSON{ID: l.ID, Type: l.Type, Label: l.Label, Anchor: l.Anchor, Block: l.Block})
+	}
+	return out
+}
+
+func (in noteInput) toNote() markdown.Note {
+	n := markdown.Note{
+		Title:   in.Title,
+		Type:    in.Type,
+		Lang:    in.Lang,
+		Tags:    in.Tags,
+		Aliases: in.Aliases,
+		Body:    in.Body,
+	}
+	for _, l := range in.Links {
+		if l.Type == "" {
+			l.Type = links.DefaultLinkType
+		}
+		n.Links = append(n.Links, links.Link{ID: l.ID, Type: l.Type, Label: l.Label, Anchor: l.Anchor, Block: l.Block})
+	}
+	return n
+}
+
+// etag identifies a note revision by its modification time.
+func etag(n markdown.Note) string {
+	return `"` + strconv.FormatInt(n.Modified.UnixNano(), 36) + `"`
+}
+
+func apiError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/notes", s.apiListNotes)
+	mux.HandleFunc("POST /api/notes", s.apiCreateNote)
+	mux.HandleFunc("GET /api/notes/{id}", s.apiGetNote)
+	mux.HandleFunc("PUT /api/notes/{id}", s.apiUpdateNote)
+	mux.HandleFunc("DELETE /api/notes/{id}", s.apiDeleteNote)
+	mux.HandleFunc("GET /api/notes/{id}/neighbors", s.apiNeighbors)
+	mux.HandleFunc("GET /api/notes/{id}/backlinks", s.apiBacklinks)
+	mux.HandleFunc("GET /api/search", s.apiSearch)
+	mux.HandleFunc("GET /api/openapi.yaml", s.apiSpecYAML)
+	mux.HandleFunc("GET /api/openapi.json", s.apiSpecJSON)
+}
+
+func parseFilter(r *http.Request) (notes.Filter, error) {
+	q := r.URL.Query()
+	f := notes.Filter{Type: q.Get("type"), Tag: q.Get("tag")}
+	var err error
+	if v := q.Get("year"); v != "" {
+		if f.Year, err = strconv.Atoi(v); err != nil {
+			return f, fmt.Errorf("invalid year %q", v)
+		}
+	}
+	if v := q.Get("month"); v != "" {
+		if f.Month, err = strconv.Atoi(v); err != nil {
+			return f, fmt.Errorf("invalid month %q", v)
+		}
+	}
+	return f, nil
+}
+
+func parseIntParam(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+func (s *Server) apiListNotes(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	limit, err := parseIntParam(r, "limit", defaultPageSize)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	offset, err := parseIntParam(r, "offset", 0)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	var matched []noteJSON
+	for _, e := range s.indexer.Search.Entries() {
+		if filter.Match(e) {
+			matched = append(matched, toNoteJSON(e.Note))
+		}
+	}
+
+	page := []noteJSON{}
+	if offset < len(matched) {
+		page = matched[offset:min(offset+limit, len(matched))]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"notes":  page,
+		"total":  len(matched),
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+func (s *Server) apiGetNote(w http.ResponseWriter, r *http.Request) {
+	note, ok := s.currentView().notes[r.PathValue("id")]
+	if !ok {
+		apiError(w, http.StatusNotFound, "note %s not found", r.PathValue("id"))
+		return
+	}
+
+	tag := etag(note)
+	w.Header().Set("ETag", tag)
+	if r.Header.Get("If-None-Match") == tag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeJSON(w, http.StatusOK, toNoteJSON(note))
+}
+
+func decodeInput(w http.ResponseWriter, r *http.Request) (noteInput, bool) {
+	var in noteInput
+	// Browsers send text/plain and form posts cross-site without a
+	// preflight; requiring JSON keeps other pages from writing notes.
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		apiError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return in, false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		apiError(w, http.StatusBadRequest, "invalid note: %v", err)
+		return in, false
+	}
+	if strings.TrimSpace(in.Title) == "" {
+		apiError(w, http.StatusBadRequest, "invalid note: title is required")
+		return in, false
+	}
+	if in.Lang != "" && !markdown.ValidLang(in.Lang) {
+		apiError(w, http.StatusBadRequest, "invalid note: invalid lang %q", in.Lang)
+		return in, false
+	}
+	return in, true
+}
+
+func (s *Server) apiCreateNote(w http.ResponseWriter, r *http.Request) {
+	in, ok := decodeInput(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	now := s.now()
+	id := notes.GenerateID(in.Title, now)
+	path, err := notes.ResolvePath(s.vaultPath, id)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	if _, err := os.Stat(path); err == nil {
+		apiError(w, http.StatusConflict, "note %s already exists", id)
+		return
+	}
+
+	if _, err := notes.Create(s.vaultPath, in.toNote(), now); err != nil {
+		apiError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	s.Refresh([]watch.Event{{Op: watch.Created, Path: path}})
+
+	note := s.currentView().notes[id]
+	w.Header().Set("Location", "/api/notes/"+id)
+	w.Header().Set("ETag", etag(note))
+	writeJSON(w, http.StatusCreated, toNoteJSON(note))
+}
+
+// readForWrite loads the on-disk revision of a note about to be changed;
+// the index may lag behind edits made outside the API.
+func (s *Server) readForWrite(w http.ResponseWriter, id string) (markdown.Note, string, bool) {
+	// The ID comes from the URL and becomes a file path, so anything but
+	// a well-formed note ID could reach outside the vault.
+	if !notes.ValidID(id) {
+		apiError(w, http.StatusNotFound, "note %s not found", id)
+		return markdown.Note{}, "", false
+	}
+	path, err := notes.ResolvePath(s.vaultPath, id)
+	if err != nil {
+		apiError(w, http.StatusNotFound, "note %s not found", id)
+		return markdown.Note{}, "", false
+	}
+	note, err := notes.Read(s.vaultPath, id)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			apiError(w, http.StatusNotFound, "note %s not found", id)
+		} else {
+			apiError(w, http.StatusInternalServerError, "%v", err)
+		}
+		return markdown.Note{}, "", false
+	}
+	return note, path, true
+}
+
+// checkPrecondition enforces optimistic concurrency: writes to an existing
+// note must carry the ETag of the revision they were based on.
+func checkPrecondition(w http.ResponseWriter, r *http.Request, current markdown.Note) bool {
+	match := r.Header.Get("If-Match")
+	if match == "" {
+		apiError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return false
+	}
+	if match != "*" && match != etag(current) {
+		w.Header().Set("ETag", etag(current))
+		apiError(w, http.StatusPreconditionFailed, "note %s was modified since it was read", current.ID)
+		return false
+	}
+	return true
+}
+
+func (s *Server) apiUpdateNote(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	in, ok := decodeInput(w, r)
+	if !ok {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	current, path, ok := s.readForWrite(w, id)
+	if !ok || !checkPrecondition(w, r, current) {
+		return
+	}
+
+	note := in.toNote()
+	if note.Type == "" {
+		note.Type = current.Type
+	}
+	note.Extra = current.Extra
+	if err := notes.Update(s.vaultPath, id, note, s.now()); err != nil {
+		apiError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	s.Refresh([]watch.Event{{Op: watch.Modified, Path: path}})
+
+	updated := s.currentView().notes[id]
+	w.Header().Set("ETag", etag(updated))
+	writeJSON(w, http.StatusOK, toNoteJSON(updated))
+}
+
+func (s *Server) apiDeleteNote(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	current, path, ok := s.readForWrite(w, id)
+	if !ok || !checkPrecondition(w, r, current) {
+		return
+	}
+
+	if err := notes.Delete(s.vaultPath, id); err != nil {
+		apiError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	s.Refresh([]watch.Event{{Op: watch.Deleted, Path: path}})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) toEdgesJSON(edges []graph.Edge) []edgeJSON {
+	out := make([]edgeJSON, 0, len(edges))
+	for _, e := range edges {
+		out = append(out, edgeJSON{From: e.From, To: e.To, Type: s.relationName(e.Predicate)})
+	}
+	return out
+}
+
+func (s *Server) apiNeighbors(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !s.indexer.Graph.Has(id) {
+		apiError(w, http.StatusNotFound, "note %s not found", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"links": s.toEdgesJSON(s.indexer.Graph.Neighbors(id))})
+}
+
+func (s *Server) apiBacklinks(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !s.indexer.Graph.Has(id) {
+		apiError(w, http.StatusNotFound, "note %s not found", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"links": s.toEdgesJSON(s.indexer.Graph.Backlinks(id))})
+}
+
+func (s *Server) apiSearch(w http.ResponseWriter, r *http.Request) {
+	term := r.URL.Query().Get("q")
+	if term == "" {
+		apiError(w, http.StatusBadRequest, "missing q parameter")
+		return
+	}
+	filter, err := parseFilter(r)
+	if err != nil {
+		apiError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	type resultJSON struct {
+		Note  noteJSON `json:"note"`
+		Score int      `json:"score"`
+	}
+	results := []resultJSON{}
+	for _, res := range s.indexer.Search.Search(search.Query{Term: term, Filter: filter, Lang: s.proj.Language()}) {
+		results = append(results, resultJSON{Note: toNoteJSON(res.Note), Score: res.Score})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+func (s *Server) apiSpecYAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Write(openAPISpec)
+}
+
+func (s *Server) apiSpecJSON(w http.ResponseWriter, r *http.Request) {
+	var spec any
+	if err := yaml.Unmarshal(openAPISpec, &spec); err != nil {
+		apiError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, spec)
+}
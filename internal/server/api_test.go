@@ -0,0 +1,240 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decode(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+}
+
+// jsonRequest builds a request carrying a JSON body, as API clients send.
+func jsonRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAPIDisabledByDefault(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	resp := do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes", nil))
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestAPIListNotes(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+
+	resp := do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes?limit=1&offset=1", nil))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var page struct {
+		Notes []noteJSON
+		Total int
+	}
+	decode(t, resp, &page)
+	if page.Total != 2 || len(page.Notes) != 1 || page.Notes[0].Title != "Parent" {
+		t.Errorf("page = %+v, want Parent of 2", page)
+	}
+
+	resp = do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes?type=Concept", nil))
+	decode(t, resp, &page)
+	if page.Total != 0 || page.Notes == nil {
+		t.Errorf("filtered page = %+v, want empty list", page)
+	}
+
+	resp = do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes?limit=x", nil))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bad limit status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestAPICreateReadUpdateDelete(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+	s.now = func() time.Time { return testTime.Add(2 * time.Hour) }
+	h := s.Handler()
+
+	req := jsonRequest(http.MethodPost, "/api/notes", `{"title":"Fresh","tags":["go"]}`)
+	resp := do(t, h, req)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create status = %d, body %s", resp.StatusCode, readBody(t, resp))
+	}
+	var created noteJSON
+	decode(t, resp, &created)
+	loc := resp.Header.Get("Location")
+	if loc != "/api/notes/"+created.ID || created.Type != "Note" {
+		t.Fatalf("created = %+v at %q", created, loc)
+	}
+
+	resp = do(t, h, jsonRequest(http.MethodPost, "/api/notes", `{"title":"Fresh"}`))
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate create status = %d, want 409", resp.StatusCode)
+	}
+
+	resp = do(t, h, httptest.NewRequest(http.MethodGet, loc, nil))
+	tag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || tag == "" {
+		t.Fatalf("get status = %d, ETag %q", resp.StatusCode, tag)
+	}
+
+	put := func(match string) *http.Response {
+		req := jsonRequest(http.MethodPut, loc, `{"title":"Fresh","body":"edited"}`)
+		if match != "" {
+			req.Header.Set("If-Match", match)
+		}
+		return do(t, h, req)
+	}
+	if resp := put(""); resp.StatusCode != http.StatusPreconditionRequired {
+		t.Errorf("put without If-Match status = %d, want 428", resp.StatusCode)
+	}
+
+	s.now = func() time.Time { return testTime.Add(3 * time.Hour) }
+	resp = put(tag)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("put status = %d, body %s", resp.StatusCode, readBody(t, resp))
+	}
+	var updated noteJSON
+	decode(t, resp, &updated)
+	if updated.Body != "edited" || resp.Header.Get("ETag") == tag {
+		t.Errorf("updated = %+v, ETag %q", updated, resp.Header.Get("ETag"))
+	}
+
+	if resp := put(tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale put status = %d, want 412", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, loc, nil)
+	req.Header.Set("If-Match", "*")
+	if resp := do(t, h, req); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status = %d, want 204", resp.StatusCode)
+	}
+	if resp := do(t, h, httptest.NewRequest(http.MethodGet, loc, nil)); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("get after delete status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestAPICreateRejectsInvalid(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+
+	for _, body := range []string{`{}`, `{"title":"x","extra":1}`, `not json`} {
+		resp := do(t, s.Handler(), jsonRequest(http.MethodPost, "/api/notes", body))
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want 400", body, resp.StatusCode)
+		}
+	}
+}
+
+func TestAPIRequiresJSON(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/notes", strings.NewReader(`{"title":"Forged"}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("Content-Type %q status = %d, want 415", contentType, resp.StatusCode)
+		}
+	}
+
+	req := jsonRequest(http.MethodPost, "/api/notes", `{"title":"Charset"}`)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusCreated {
+		t.Errorf("JSON with charset status = %d, want 201", resp.StatusCode)
+	}
+}
+
+func TestAPIRejectsPathTraversal(t *testing.T) {
+	s, vault := newTestServer(t, Options{API: true})
+	victim := filepath.Join(vault, "outside", "victim-20250101000000.md")
+	if err := os.MkdirAll(filepath.Dir(victim), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(victim, []byte("---\nid: victim-20250101000000\ntitle: Victim\n---\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loc := "/api/notes/..%2F..%2Foutside%2Fvictim-20250101000000"
+	for _, method := range []string{http.MethodDelete, http.MethodPut} {
+		req := jsonRequest(method, loc, `{"title":"Owned"}`)
+		req.Header.Set("If-Match", "*")
+		if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404", method, resp.StatusCode)
+		}
+	}
+	if data, err := os.ReadFile(victim); err != nil || !strings.Contains(string(data), "title: Victim") {
+		t.Errorf("file outside the notes tree changed: %q, %v", data, err)
+	}
+}
+
+func TestAPIGraph(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+	view := s.currentView()
+	parent, child := view.sorted[1].ID, view.sorted[0].ID
+
+	var out struct{ Links []edgeJSON }
+	resp := do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes/"+parent+"/backlinks", nil))
+	decode(t, resp, &out)
+	if len(out.Links) != 1 || out.Links[0].From != child || out.Links[0].Type != "broader" {
+		t.Errorf("backlinks = %+v", out.Links)
+	}
+
+	resp = do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes/"+child+"/neighbors", nil))
+	decode(t, resp, &out)
+	if len(out.Links) == 0 || out.Links[0].To != parent {
+		t.Errorf("neighbors = %+v", out.Links)
+	}
+
+	resp = do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/notes/missing/neighbors", nil))
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestAPISearch(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+
+	var out struct {
+		Results []struct {
+			Note  noteJSON
+			Score int
+		}
+	}
+	resp := do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/search?q=child", nil))
+	decode(t, resp, &out)
+	if len(out.Results) != 1 || out.Results[0].Note.Title != "Child" {
+		t.Errorf("results = %+v", out.Results)
+	}
+
+	resp = do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/search", nil))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing q status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestAPISpec(t *testing.T) {
+	s, _ := newTestServer(t, Options{API: true})
+
+	resp := do(t, s.Handler(), httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	var spec struct {
+		OpenAPI string
+		Paths   map[string]any
+	}
+	decode(t, resp, &spec)
+	if spec.OpenAPI == "" || spec.Paths["/api/notes/{id}"] == nil {
+		t.Errorf("spec = %+v", spec)
+	}
+}
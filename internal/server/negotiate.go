@@ -0,0 +1,70 @@
+package server
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate picks the offer that best matches an Accept header. Offers are
+// in order of server preference, which also breaks ties. An empty header
+// accepts the first offer; "" is returned when nothing is acceptable.
+func negotiate(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		// The most specific matching range decides the offer's quality.
+		q, spec := 0.0, -1
+		for _, r := range ranges {
+			rs := specificity(r.mediaType, offer)
+			if rs > spec || (rs == spec && r.q > q) {
+				q, spec = r.q, rs
+			}
+		}
+		if spec >= 0 && q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// specificity reports how exactly a media range matches an offer: 2 for an
+// exact match, 1 for type/*, 0 for */*, and -1 for no match.
+func specificity(mediaRange, offer string) int {
+	if mediaRange == offer {
+		return 2
+	}
+	if mediaRange == "*/*" {
+		return 0
+	}
+	if strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaRange, "*")) {
+		return 1
+	}
+	return -1
+}
@@ -0,0 +1,27 @@
+package server
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/sparql-results+json", "text/csv", "text/plain"}
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/sparql-results+json"},
+		{"text/csv", "text/csv"},
+		{"text/*", "text/csv"},
+		{"text/plain;q=0.9, text/csv;q=0.5", "text/plain"},
+		{"*/*", "application/sparql-results+json"},
+		{"text/*;q=0.5, */*;q=0.1", "text/csv"},
+		{"text/csv;q=0, */*", "application/sparql-results+json"},
+		{"image/png", ""},
+	}
+
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, offers); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"github.com/DeDude/weave2/internal/sparql"
+	"github.com/DeDude/weave2/internal/watch"
+	rdf "github.com/deiu/rdf2go"
+)
+
+type Options struct {
+	// SPARQL mounts the SPARQL 1.1 Protocol endpoint at /sparql.
+	SPARQL bool
+	// API mounts the read/write JSON API under /api.
+	API bool
+}
+
+// Server serves the vault over HTTP. It keeps an index of the vault in
+// memory and rebuilds its derived views when Refresh reports changes.
+type Server struct {
+	vaultPath string
+	proj      *rdfproj.Projector
+	opts      Options
+	indexer   *watch.Indexer
+	now       func() time.Time
+
+	// writeMu serializes API writes so precondition checks and the writes
+	// they guard cannot interleave.
+	writeMu sync.Mutex
+
+	mu    sync.RWMutex
+	store *sparql.Store
+	view  *view
+}
+
+// view is an immutable snapshot of the loaded notes for page rendering.
+type view struct {
+	notes    map[string]markdown.Note
+	sorted   []markdown.Note
+	tags     map[string][]string
+	resolver *links.Resolver
+	// proj projects notes against this snapshot of the vault.
+	proj *rdfproj.Projector
+}
+
+// New loads the vault. Files that fail to load are returned and skipped.
+func New(ctx context.Context, vaultPath string, proj *rdfproj.Projector, opts Options) (*Server, []error) {
+	ix, errs := watch.NewIndexer(ctx, vaultPath, proj)
+	s := &Server{
+		vaultPath: vaultPath,
+		proj:      proj,
+		opts:      opts,
+		indexer:   ix,
+		now:       time.Now,
+	}
+	s.rebuild()
+	return s, errs
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /notes/{id}", s.handleNote)
+	if base := s.basePath(); base != "" {
+		// Note IRIs dereference where the base URI puts them.
+		mux.HandleFunc("GET "+base+"/notes/{id}", s.handleNote)
+	}
+	mux.HandleFunc("GET /tags", s.handleTags)
+	mux.HandleFunc("GET /tags/{tag...}", s.handleTag)
+	mux.HandleFunc("GET /graph", s.handleGraphPage)
+	mux.HandleFunc("GET /api/graph", s.handleGraphJSON)
+	mux.Handle("GET /static/", staticHandler())
+	if s.opts.SPARQL {
+		mux.HandleFunc("/sparql", s.handleSPARQL)
+	}
+	if s.opts.API {
+		s.registerAPI(mux)
+	}
+	return mux
+}
+
+// Refresh applies watcher events so responses reflect the current vault.
+func (s *Server) Refresh(events []watch.Event) []error {
+	errs := s.indexer.Apply(events)
+	s.rebuild()
+	return errs
+}
+
+// Watch refreshes the server from a vault watcher until ctx is cancelled.
+// onError receives files that could not be reloaded and watch errors the
+// watcher recovered from.
+func (s *Server) Watch(ctx context.Context, opts watch.Options, onError func(error)) error {
+	if opts.OnError == nil {
+		opts.OnError = onError
+	}
+	w, err := watch.New(s.vaultPath, opts)
+	if err != nil {
+		return err
+	}
+	return w.Run(ctx, func(events []watch.Event) {
+		for _, err := range s.Refresh(events) {
+			onError(err)
+		}
+	})
+}
+
+func (s *Server) rebuild() {
+	store := sparql.NewStore()
+	entries := s.indexer.Search.Entries()
+
+	v := &view{
+		notes: make(map[string]markdown.Note, len(entries)),
+		tags:  make(map[string][]string),
+	}
+	for _, e := range entries {
+		v.notes[e.Note.ID] = e.Note
+		v.sorted = append(v.sorted, e.Note)
+	}
+	sort.Slice(v.sorted, func(i, j int) bool {
+		if !v.sorted[i].Created.Equal(v.sorted[j].Created) {
+			return v.sorted[i].Created.After(v.sorted[j].Created)
+		}
+		return v.sorted[i].ID < v.sorted[j].ID
+	})
+	targets := make([]links.Target, 0, len(v.sorted))
+	for _, n := range v.sorted {
+		for _, tag := range n.Tags {
+			v.tags[tag] = append(v.tags[tag], n.ID)
+		}
+		targets = append(targets, links.Target{ID: n.ID, Title: n.Title, Aliases: n.Aliases})
+	}
+	v.resolver = links.NewResolver(targets)
+	v.proj = s.proj.WithVault(v.sorted)
+
+	v.proj.EachVaultTriple(func(yield func(markdown.Note) bool) {
+		for _, e := range entries {
+			if !yield(e.Note) {
+				return
+			}
+		}
+	}, func(t *rdf.Triple) bool {
+		store.Add(t)
+		return true
+	})
+
+	s.mu.Lock()
+	s.store = store
+	s.view = v
+	s.mu.Unlock()
+}
+
+func (s *Server) currentView() *view {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.view
+}
+
+func (s *Server) handleNote(w http.ResponseWriter, r *http.Request) {
+	note, ok := s.currentView().notes[r.PathValue("id")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+	mediaType := negotiate(r.Header.Get("Accept"), noteOffers)
+	switch mediaType {
+	case "":
+		http.Error(w, "not acceptable", http.StatusNotAcceptable)
+	case mimeHTML:
+		s.handleNotePage(w, r, note)
+	default:
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		export.Write(w, s.noteTriples(note), graphFormats[mediaType])
+	}
+}
+
+// noteOffers lists the representations of a note URI, HTML first so that
+// browsers sending */* get the page.
+var noteOffers = []string{
+	mimeHTML,
+	export.Turtle.MIMEType(),
+	export.JSONLD.MIMEType(),
+	export.NTriples.MIMEType(),
+}
+
+// basePath is the path of the base URI, without a trailing slash. Paths
+// the mux cannot take as a literal pattern are left out.
+func (s *Server) basePath() string {
+	u, err := url.Parse(s.proj.BaseURI())
+	if err != nil || strings.ContainsAny(u.Path, "{}") {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// noteTriples describes a note as Linked Data: its own projection plus the
+// links pointing at it, which live in other notes' files. Incoming links
+// are taken from the linking notes' projections, so links to a heading or
+// block keep the fragment IRI the export has.
+func (s *Server) noteTriples(note markdown.Note) []*rdf.Triple {
+	v := s.currentView()
+	triples := v.proj.NoteToTriples(note)
+	noteURI := s.proj.NoteURI(note.ID)
+	seen := make(map[string]bool)
+	for _, e := range s.indexer.Graph.Backlinks(note.ID) {
+		from, ok := v.notes[e.From]
+		if !ok || seen[e.From] {
+			continue
+		}
+		seen[e.From] = true
+		fromURI := s.proj.NoteURI(e.From)
+		for _, t := range v.proj.NoteToTriples(from) {
+			object := t.Object.RawValue()
+			if t.Subject.RawValue() != fromURI || object != noteURI && !strings.HasPrefix(object, noteURI+"#") {
+				continue
+			}
+			if _, ok := s.proj.Vocabulary().RelationType(t.Predicate.RawValue()); ok {
+				triples = append(triples, t)
+			}
+		}
+	}
+	return triples
+}
+
+func (s *Server) currentStore() *sparql.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.store
+}
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"github.com/DeDude/weave2/internal/watch"
+)
+
+const baseURI = "http://example.org"
+
+var testTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T, opts Options) (*Server, string) {
+	t.Helper()
+	vault := t.TempDir()
+
+	parentID, err := notes.Create(vault, markdown.Note{Title: "Parent", Tags: []string{"rdf"}, Aliases: []string{"the root"}}, testTime)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	_, err = notes.Create(vault, markdown.Note{
+		Title: "Child",
+		Body:  "See [[" + parentID + "]] and [[the root|its parent]].",
+		Tags:  []string{"rdf"},
+		Links: []links.Link{{ID: parentID, Type: "broader"}},
+	}, testTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	s, errs := New(context.Background(), vault, rdfproj.New(baseURI, nil), opts)
+	if len(errs) > 0 {
+		t.Fatalf("New() errors = %v", errs)
+	}
+	return s, vault
+}
+
+func do(t *testing.T, h http.Handler, req *http.Request) *http.Response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	return string(body)
+}
+
+const titleQuery = `SELECT ?title WHERE { ?n <http://purl.org/dc/terms/title> ?title } ORDER BY ?title`
+
+func TestSPARQLGetJSON(t *testing.T) {
+	s, _ := newTestServer(t, Options{SPARQL: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape(titleQuery), nil)
+	req.Header.Set("Accept", "application/sparql-results+json")
+	resp := do(t, s.Handler(), req)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/sparql-results+json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var decoded struct {
+		Results struct {
+			Bindings []map[string]map[string]string `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(decoded.Results.Bindings) != 2 || decoded.Results.Bindings[0]["title"]["value"] != "Child" {
+		t.Errorf("bindings = %v", decoded.Results.Bindings)
+	}
+}
+
+func TestSPARQLPostBodyCSV(t *testing.T) {
+	s, _ := newTestServer(t, Options{SPARQL: true})
+
+	req := httptest.NewRequest(http.MethodPost, "/sparql", strings.NewReader(titleQuery))
+	req.Header.Set("Content-Type", "application/sparql-query")
+	req.Header.Set("Accept", "text/csv")
+	resp := do(t, s.Handler(), req)
+
+	if got, want := readBody(t, resp), "title\r\nChild\r\nParent\r\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestSPARQLPostForm(t *testing.T) {
+	s, _ := newTestServer(t, Options{SPARQL: true})
+
+	form := url.Values{"query": {`ASK { ?n <http://purl.org/dc/terms/title> "Child" }`}}
+	req := httptest.NewRequest(http.MethodPost, "/sparql", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp := do(t, s.Handler(), req)
+
+	if body := readBody(t, resp); !strings.Contains(body, `"boolean": true`) {
+		t.Errorf("body = %s, want boolean true", body)
+	}
+}
+
+func TestSPARQLConstructTurtle(t *testing.T) {
+	s, _ := newTestServer(t, Options{SPARQL: true})
+
+	q := `CONSTRUCT { ?p <http://www.w3.org/2004/02/skos/core#narrower> ?c } WHERE { ?c <http://www.w3.org/2004/02/skos/core#broader> ?p }`
+	req := httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape(q), nil)
+	req.Header.Set("Accept", "text/turtle")
+	resp := do(t, s.Handler(), req)
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/turtle") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if body := readBody(t, resp); !strings.Contains(body, "skos/core#narrower") {
+		t.Errorf("body = %s, want narrower triple", body)
+	}
+}
+
+func TestSPARQLBadQuery(t *testing.T) {
+	s, _ := newTestServer(t, Options{SPARQL: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape("SELECT WHERE {"), nil)
+	if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/sparql", nil)
+	if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("missing query status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestSPARQLDisabled(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	req := httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape(titleQuery), nil)
+	if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	s, vault := newTestServer(t, Options{SPARQL: true})
+
+	id, err := notes.Create(vault, markdown.Note{Title: "Added Later"}, testTime.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	path, _ := notes.ResolvePath(vault, id)
+	if errs := s.Refresh([]watch.Event{{Op: watch.Created, Path: path}}); len(errs) > 0 {
+		t.Fatalf("Refresh() errors = %v", errs)
+	}
+
+	q := `ASK { ?n <http://purl.org/dc/terms/title> "Added Later" }`
+	req := httptest.NewRequest(http.MethodGet, "/sparql?query="+url.QueryEscape(q), nil)
+	if body := readBody(t, do(t, s.Handler(), req)); !strings.Contains(body, `"boolean": true`) {
+		t.Errorf("body after refresh = %s, want boolean true", body)
+	}
+}
+
+func TestNoteLinkedData(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+	view := s.currentView()
+	parent, child := view.sorted[1].ID, view.sorted[0].ID
+
+	req := httptest.NewRequest(http.MethodGet, "/notes/"+parent, nil)
+	req.Header.Set("Accept", "application/n-triples")
+	resp := do(t, s.Handler(), req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/n-triples") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := readBody(t, resp)
+	parentURI := "<" + baseURI + "/notes/" + parent + ">"
+	incoming := "<" + baseURI + "/notes/" + child + "> <http://www.w3.org/2004/02/skos/core#broader> " + parentURI + " ."
+	if !strings.Contains(body, parentURI+" <http://purl.org/dc/terms/title> \"Parent\" .") {
+		t.Errorf("missing title triple in:\n%s", body)
+	}
+	if !strings.Contains(body, incoming) {
+		t.Errorf("missing incoming link %s in:\n%s", incoming, body)
+	}
+	if strings.Contains(body, "\"Child\"") {
+		t.Errorf("other notes' triples leaked into:\n%s", body)
+	}
+}
+
+func TestNoteLinkedDataUnderBasePath(t *testing.T) {
+	vault := t.TempDir()
+	target, err := notes.Create(vault, markdown.Note{Title: "Target", Body: "## Design"}, testTime)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	source, err := notes.Create(vault, markdown.Note{
+		Title: "Source",
+		Links: []links.Link{{ID: target, Type: "related", Anchor: "Design"}},
+	}, testTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	const base = "http://example.org/kb"
+	s, errs := New(context.Background(), vault, rdfproj.New(base, nil), Options{})
+	if len(errs) > 0 {
+		t.Fatalf("New() errors = %v", errs)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/kb/notes/"+target, nil)
+	req.Header.Set("Accept", "application/n-triples")
+	resp := do(t, s.Handler(), req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	body := readBody(t, resp)
+	incoming := "<" + base + "/notes/" + source + "> <http://www.w3.org/2004/02/skos/core#related> <" + base + "/notes/" + target + "#design> ."
+	if !strings.Contains(body, incoming) {
+		t.Errorf("missing incoming link %s in:\n%s", incoming, body)
+	}
+
+	if resp, _ := get(t, s.Handler(), "/notes/"+target); resp.StatusCode != http.StatusOK {
+		t.Errorf("UI route status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestNoteLinkedDataResolvesLinks(t *testing.T) {
+	s, vault := newTestServer(t, Options{})
+	parent := s.currentView().sorted[1].ID
+	id, err := notes.Create(vault, markdown.Note{
+		Title: "Sibling",
+		Links: []links.Link{{ID: "the root", Type: "related"}},
+	}, testTime.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	path, _ := notes.ResolvePath(vault, id)
+	if errs := s.Refresh([]watch.Event{{Op: watch.Created, Path: path}}); len(errs) > 0 {
+		t.Fatalf("Refresh() errors = %v", errs)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/notes/"+parent, nil)
+	req.Header.Set("Accept", "application/n-triples")
+	body := readBody(t, do(t, s.Handler(), req))
+	incoming := "<" + baseURI + "/notes/" + id + "> <http://www.w3.org/2004/02/skos/core#related> <" + baseURI + "/notes/" + parent + "> ."
+	if !strings.Contains(body, incoming) {
+		t.Errorf("missing incoming link %s in:\n%s", incoming, body)
+	}
+}
+
+func TestNoteNegotiation(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+	id := s.currentView().sorted[0].ID
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"text/html,application/xhtml+xml,*/*;q=0.8", "text/html"},
+		{"*/*", "text/html"},
+		{"text/turtle", "text/turtle"},
+		{"application/ld+json", "application/ld+json"},
+		{"text/html;q=0.5, text/turtle", "text/turtle"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/notes/"+id, nil)
+		req.Header.Set("Accept", tt.accept)
+		resp := do(t, s.Handler(), req)
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, tt.want) {
+			t.Errorf("Accept %q: Content-Type = %q, want %s", tt.accept, ct, tt.want)
+		}
+		if resp.Header.Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: Vary = %q", tt.accept, resp.Header.Get("Vary"))
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/notes/"+id, nil)
+	req.Header.Set("Accept", "image/png")
+	if resp := do(t, s.Handler(), req); resp.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("status = %d, want 406", resp.StatusCode)
+	}
+}
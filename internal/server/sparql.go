@@ -0,0 +1,114 @@
+package server
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/sparql"
+)
+
+const (
+	mimeSPARQLJSON  = "application/sparql-results+json"
+	mimeSPARQLQuery = "application/sparql-query"
+	mimeJSON        = "application/json"
+	mimeCSV         = "text/csv"
+	mimeText        = "text/plain"
+	mimeFormURL     = "application/x-www-form-urlencoded"
+	mimeHTML        = "text/html"
+)
+
+var resultOffers = []string{mimeSPARQLJSON, mimeJSON, mimeCSV, mimeText}
+
+var resultFormats = map[string]sparql.ResultFormat{
+	mimeSPARQLJSON: sparql.JSON,
+	mimeJSON:       sparql.JSON,
+	mimeCSV:        sparql.CSV,
+	mimeText:       sparql.Table,
+}
+
+var graphOffers = []string{
+	export.Turtle.MIMEType(),
+	export.NTriples.MIMEType(),
+	export.JSONLD.MIMEType(),
+	mimeText,
+}
+
+var graphFormats = map[string]export.Format{
+	export.Turtle.MIMEType():   export.Turtle,
+	export.NTriples.MIMEType(): export.NTriples,
+	export.JSONLD.MIMEType():   export.JSONLD,
+	mimeText:                   export.NTriples,
+}
+
+// handleSPARQL implements the query operation of the SPARQL 1.1 Protocol:
+// GET with a query parameter, POST with a form, or POST with the query as
+// the request body.
+func (s *Server) handleSPARQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var src string
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case http.MethodGet:
+		src = r.URL.Query().Get("query")
+	case http.MethodPost:
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case mimeSPARQLQuery:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+			if err != nil {
+				http.Error(w, "read query: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			src = string(body)
+		case mimeFormURL:
+			src = r.PostFormValue("query")
+		default:
+			http.Error(w, "unsupported content type "+mediaType, http.StatusUnsupportedMediaType)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if src == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	query, err := sparql.Parse(src)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := query.Eval(s.currentStore())
+	w.Header().Add("Vary", "Accept")
+
+	if res.Form == sparql.Construct {
+		mediaType := negotiate(r.Header.Get("Accept"), graphOffers)
+		if mediaType == "" {
+			http.Error(w, "not acceptable", http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+		export.Write(w, res.Triples, graphFormats[mediaType])
+		return
+	}
+
+	mediaType := negotiate(r.Header.Get("Accept"), resultOffers)
+	if mediaType == "" {
+		http.Error(w, "not acceptable", http.StatusNotAcceptable)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	sparql.WriteResults(w, res, resultFormats[mediaType])
+}
@@ -0,0 +1,157 @@
+// Force-directed view of the note graph served by /api/graph.
+(function () {
+  "use strict";
+
+  const canvas = document.getElementById("graph");
+  const ctx = canvas.getContext("2d");
+  let nodes = [];
+  let links = [];
+  let dragging = null;
+  let hover = null;
+  let moved = false;
+
+  function resize() {
+    const ratio = window.devicePixelRatio || 1;
+    canvas.width = canvas.clientWidth * ratio;
+    canvas.height = canvas.clientWidth * 0.6 * ratio;
+    canvas.style.height = canvas.clientWidth * 0.6 + "px";
+    ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+  }
+
+  function size() {
+    return { w: canvas.clientWidth, h: canvas.clientWidth * 0.6 };
+  }
+
+  function step() {
+    const { w, h } = size();
+    const k = Math.sqrt((w * h) / Math.max(nodes.length, 1)) * 0.5;
+
+    for (const a of nodes) {
+      a.fx = (w / 2 - a.x) * 0.01;
+      a.fy = (h / 2 - a.y) * 0.01;
+    }
+    for (let i = 0; i < nodes.length; i++) {
+      for (let j = i + 1; j < nodes.length; j++) {
+        const a = nodes[i];
+        const b = nodes[j];
+        let dx = a.x - b.x;
+        let dy = a.y - b.y;
+        const d2 = Math.max(dx * dx + dy * dy, 1);
+        const f = (k * k) / d2;
+        dx *= f / Math.sqrt(d2);
+        dy *= f / Math.sqrt(d2);
+        a.fx += dx;
+        a.fy += dy;
+        b.fx -= dx;
+        b.fy -= dy;
+      }
+    }
+    for (const l of links) {
+      const dx = l.target.x - l.source.x;
+      const dy = l.target.y - l.source.y;
+      const d = Math.max(Math.sqrt(dx * dx + dy * dy), 1);
+      const f = (d - k) * 0.05;
+      l.source.fx += (dx / d) * f;
+      l.source.fy += (dy / d) * f;
+      l.target.fx -= (dx / d) * f;
+      l.target.fy -= (dy / d) * f;
+    }
+    for (const n of nodes) {
+      if (n === dragging) continue;
+      n.vx = (n.vx + n.fx) * 0.6;
+      n.vy = (n.vy + n.fy) * 0.6;
+      n.x = Math.min(Math.max(n.x + n.vx, 10), w - 10);
+      n.y = Math.min(Math.max(n.y + n.vy, 10), h - 10);
+    }
+  }
+
+  function draw() {
+    const { w, h } = size();
+    ctx.clearRect(0, 0, w, h);
+    ctx.strokeStyle = "#bbb";
+    for (const l of links) {
+      ctx.beginPath();
+      ctx.moveTo(l.source.x, l.source.y);
+      ctx.lineTo(l.target.x, l.target.y);
+      ctx.stroke();
+    }
+    for (const n of nodes) {
+      ctx.beginPath();
+      ctx.fillStyle = n === hover ? "#5a3ea6" : "#2456a6";
+      ctx.arc(n.x, n.y, 5 + Math.min(n.degree, 10), 0, Math.PI * 2);
+      ctx.fill();
+    }
+    ctx.fillStyle = "#222";
+    ctx.font = "12px system-ui, sans-serif";
+    for (const n of nodes) {
+      if (nodes.length < 60 || n === hover) {
+        ctx.fillText(n.title, n.x + 8, n.y - 8);
+      }
+    }
+  }
+
+  function tick() {
+    step();
+    draw();
+    requestAnimationFrame(tick);
+  }
+
+  function nodeAt(x, y) {
+    for (const n of nodes) {
+      const r = 5 + Math.min(n.degree, 10);
+      if ((n.x - x) ** 2 + (n.y - y) ** 2 <= r * r) return n;
+    }
+    return null;
+  }
+
+  function pos(ev) {
+    const rect = canvas.getBoundingClientRect();
+    return { x: ev.clientX - rect.left, y: ev.clientY - rect.top };
+  }
+
+  canvas.addEventListener("mousedown", (ev) => {
+    const p = pos(ev);
+    dragging = nodeAt(p.x, p.y);
+    moved = false;
+  });
+  canvas.addEventListener("mousemove", (ev) => {
+    const p = pos(ev);
+    if (dragging) {
+      dragging.x = p.x;
+      dragging.y = p.y;
+      moved = true;
+    }
+    hover = nodeAt(p.x, p.y);
+    canvas.style.cursor = hover ? "pointer" : "default";
+  });
+  canvas.addEventListener("mouseup", () => {
+    if (dragging && !moved) {
+      window.location.href = "/notes/" + encodeURIComponent(dragging.id);
+    }
+    dragging = null;
+  });
+
+  fetch("/api/graph")
+    .then((r) => r.json())
+    .then((data) => {
+      resize();
+      const { w, h } = size();
+      const byID = new Map();
+      nodes = data.nodes.map((n) => {
+        const node = { ...n, x: Math.random() * w, y: Math.random() * h, vx: 0, vy: 0, degree: 0 };
+        byID.set(n.id, node);
+        return node;
+      });
+      links = data.links
+        .filter((l) => byID.has(l.source) && byID.has(l.target))
+        .map((l) => {
+          const link = { source: byID.get(l.source), target: byID.get(l.target), type: l.type };
+          link.source.degree++;
+          link.target.degree++;
+          return link;
+        });
+      tick();
+    });
+
+  window.addEventListener("resize", resize);
+})();
@@ -0,0 +1,315 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+var pages = parsePages("index", "note", "tags", "tag", "graph")
+
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+		parser.WithASTTransformers(util.Prioritized(blockIDs{}, 100)),
+	),
+)
+
+// renderMarkdown converts a note body to HTML. Headings get the anchors
+// links and RDF section IRIs use, and blocks marked ^id get id="^id".
+func renderMarkdown(body string, w io.Writer) error {
+	ctx := parser.NewContext(parser.WithIDs(headingIDs{make(markdown.Anchors)}))
+	return md.Convert([]byte(body), w, parser.WithContext(ctx))
+}
+
+// headingIDs gives headings the anchors markdown.ParseStructure does.
+type headingIDs struct {
+	anchors markdown.Anchors
+}
+
+func (ids headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	return []byte(ids.anchors.Next(string(value)))
+}
+
+func (ids headingIDs) Put(value []byte) {
+	ids.anchors[string(value)]++
+}
+
+// blockIDs moves a trailing ^block-id marker from a paragraph or list
+// item into its id attribute.
+type blockIDs struct{}
+
+func (blockIDs) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	source := reader.Source()
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if _, ok := n.(*ast.Paragraph); !ok {
+			if _, ok := n.(*ast.TextBlock); !ok {
+				return ast.WalkContinue, nil
+			}
+		}
+		last, ok := n.LastChild().(*ast.Text)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		before, id, found := markdown.CutBlockID(string(last.Segment.Value(source)))
+		if !found {
+			return ast.WalkContinue, nil
+		}
+		last.Segment = last.Segment.WithStop(last.Segment.Start + len(before))
+		target := n
+		if _, ok := n.(*ast.TextBlock); ok && n.Parent() != nil {
+			// Tight list items render their text without a wrapper.
+			target = n.Parent()
+		}
+		target.SetAttributeString("id", []byte("^"+id))
+		return ast.WalkSkipChildren, nil
+	})
+}
+
+func parsePages(names ...string) map[string]*template.Template {
+	funcs := template.FuncMap{
+		"noteURL": noteURL,
+		"tagURL":  tagURL,
+	}
+	layout := template.Must(template.New("").Funcs(funcs).ParseFS(templateFS, "templates/layout.html"))
+
+	out := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		t := template.Must(layout.Clone())
+		out[name] = template.Must(t.ParseFS(templateFS, "templates/"+name+".html"))
+	}
+	return out
+}
+
+func noteURL(id string) string {
+	return "/notes/" + url.PathEscape(id)
+}
+
+func tagURL(tag string) string {
+	return "/tags/" + url.PathEscape(tag)
+}
+
+func staticHandler() http.Handler {
+	sub, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/static/", http.FileServer(http.FS(sub)))
+}
+
+func render(w http.ResponseWriter, status int, page string, data any) {
+	var buf bytes.Buffer
+	if err := pages[page].ExecuteTemplate(&buf, "layout", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	v := s.currentView()
+	render(w, http.StatusOK, "index", struct{ Notes []markdown.Note }{v.sorted})
+}
+
+type linkItem struct {
+	ID      string
+	Title   string
+	Type    string
+	Missing bool
+}
+
+func (s *Server) handleNotePage(w http.ResponseWriter, r *http.Request, note markdown.Note) {
+	v := s.currentView()
+
+	var body bytes.Buffer
+	if err := renderMarkdown(s.resolveWikilinks(v, note.Body), &body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Note      markdown.Note
+		Body      template.HTML
+		Links     []linkItem
+		Backlinks []linkItem
+	}{
+		Note: note,
+		Body: template.HTML(body.String()),
+	}
+	for _, e := range s.indexer.Graph.Neighbors(note.ID) {
+		data.Links = append(data.Links, v.linkItem(e.To, s.relationName(e.Predicate)))
+	}
+	for _, e := range s.indexer.Graph.Backlinks(note.ID) {
+		data.Backlinks = append(data.Backlinks, v.linkItem(e.From, s.relationName(e.Predicate)))
+	}
+
+	render(w, http.StatusOK, "note", data)
+}
+
+// resolveWikilinks turns [[links]] into Markdown links to note pages,
+// labelled with the link label or the target's title. Links may name a
+// note by ID prefix, title or alias; links to a heading or block jump to
+// it.
+func (s *Server) resolveWikilinks(v *view, body string) string {
+	return links.ReplaceLinks(body, func(l links.Link) string {
+		if resolved, err := v.resolver.ResolveLink(l); err == nil {
+			l = resolved
+		}
+		text := l.Label
+		if text == "" {
+			if target, ok := v.notes[l.ID]; ok {
+				text = target.Title
+			} else {
+				text = l.ID
+			}
+		}
+		href := noteURL(l.ID)
+		switch {
+		case l.Block != "":
+			href += "#" + url.PathEscape("^"+l.Block)
+		case l.Anchor != "":
+			href += "#" + url.PathEscape(markdown.Slug(l.Anchor))
+		}
+		return "[" + escapeMarkdown(text) + "](" + href + ")"
+	})
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`, "`", "\\`", `<`, `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+type tagCount struct {
+	Name  string
+	Count int
+}
+
+func (s *Server) handleTags(w http.ResponseWriter, r *http.Request) {
+	v := s.currentView()
+	tags := make([]tagCount, 0, len(v.tags))
+	for name, ids := range v.tags {
+		tags = append(tags, tagCount{Name: name, Count: len(ids)})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	render(w, http.StatusOK, "tags", struct{ Tags []tagCount }{tags})
+}
+
+func (s *Server) handleTag(w http.ResponseWriter, r *http.Request) {
+	v := s.currentView()
+	tag := r.PathValue("tag")
+	ids, ok := v.tags[tag]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var tagged []markdown.Note
+	for _, id := range ids {
+		tagged = append(tagged, v.notes[id])
+	}
+	render(w, http.StatusOK, "tag", struct {
+		Tag   string
+		Notes []markdown.Note
+	}{tag, tagged})
+}
+
+func (s *Server) handleGraphPage(w http.ResponseWriter, r *http.Request) {
+	render(w, http.StatusOK, "graph", nil)
+}
+
+type graphNode struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Type  string   `json:"type"`
+	Tags  []string `json:"tags"`
+}
+
+type graphLink struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// handleGraphJSON serves the note graph for the graph view.
+func (s *Server) handleGraphJSON(w http.ResponseWriter, r *http.Request) {
+	v := s.currentView()
+	out := struct {
+		Nodes []graphNode `json:"nodes"`
+		Links []graphLink `json:"links"`
+	}{Nodes: []graphNode{}, Links: []graphLink{}}
+
+	for _, n := range v.sorted {
+		tags := n.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out.Nodes = append(out.Nodes, graphNode{ID: n.ID, Title: n.Title, Type: n.Type, Tags: tags})
+	}
+	for _, e := range s.indexer.Graph.Edges() {
+		out.Links = append(out.Links, graphLink{Source: e.From, Target: e.To, Type: s.relationName(e.Predicate)})
+	}
+
+	writeJSON(w, http.StatusOK, out)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func (v *view) linkItem(id, relType string) linkItem {
+	item := linkItem{ID: id, Title: id, Type: relType}
+	if n, ok := v.notes[id]; ok {
+		item.Title = n.Title
+	} else {
+		item.Missing = true
+	}
+	return item
+}
+
+// relationName maps a predicate IRI back to its relationship type, or
+// shortens it to its local name when the vocabulary does not know it.
+func (s *Server) relationName(predicate string) string {
+	if relType, ok := s.proj.Vocabulary().RelationType(predicate); ok {
+		return relType
+	}
+	if i := strings.LastIndexAny(predicate, "#/"); i >= 0 {
+		return predicate[i+1:]
+	}
+	return predicate
+}
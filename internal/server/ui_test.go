@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DeDude/weave2/internal/markdown"
+)
+
+func get(t *testing.T, h http.Handler, path string) (*http.Response, string) {
+	t.Helper()
+	resp := do(t, h, httptest.NewRequest(http.MethodGet, path, nil))
+	return resp, readBody(t, resp)
+}
+
+func TestIndexPage(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	resp, body := get(t, s.Handler(), "/")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if !strings.Contains(body, `href="/notes/child-20250101010000"`) || !strings.Contains(body, `href="/notes/parent-20250101000000"`) {
+		t.Errorf("index does not link both notes:\n%s", body)
+	}
+}
+
+func TestNotePage(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	_, body := get(t, s.Handler(), "/notes/child-20250101010000")
+	if !strings.Contains(body, `<a href="/notes/parent-20250101000000">Parent</a>`) {
+		t.Errorf("wikilink not resolved to note page:\n%s", body)
+	}
+	if !strings.Contains(body, `<a href="/notes/parent-20250101000000">its parent</a>`) {
+		t.Errorf("wikilink by alias not resolved to note page:\n%s", body)
+	}
+
+	_, body = get(t, s.Handler(), "/notes/parent-20250101000000")
+	backlinks := body[strings.Index(body, "Backlinks"):]
+	if !strings.Contains(backlinks, `href="/notes/child-20250101010000"`) {
+		t.Errorf("backlinks panel missing child:\n%s", backlinks)
+	}
+}
+
+func TestRenderMarkdownAnchors(t *testing.T) {
+	body := "## Über uns\n\n## Über uns\n\nA claim. ^claim-1\n\n- item ^li\n- other\n"
+	var out bytes.Buffer
+	if err := renderMarkdown(body, &out); err != nil {
+		t.Fatalf("renderMarkdown() error = %v", err)
+	}
+	html := out.String()
+
+	for _, h := range markdown.ParseStructure(body).Headings {
+		if !strings.Contains(html, `id="`+h.Anchor+`"`) {
+			t.Errorf("no heading with id %q:\n%s", h.Anchor, html)
+		}
+	}
+	for _, want := range []string{`<p id="^claim-1">A claim.</p>`, `<li id="^li">item</li>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("missing %s:\n%s", want, html)
+		}
+	}
+}
+
+func TestNotePageMissing(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	if resp, _ := get(t, s.Handler(), "/notes/nope-20250101000000"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestTagPages(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	_, body := get(t, s.Handler(), "/tags")
+	if !strings.Contains(body, `href="/tags/rdf"`) {
+		t.Errorf("tags page missing rdf:\n%s", body)
+	}
+
+	_, body = get(t, s.Handler(), "/tags/rdf")
+	if !strings.Contains(body, "Child") || !strings.Contains(body, "Parent") {
+		t.Errorf("tag page missing notes:\n%s", body)
+	}
+
+	if resp, _ := get(t, s.Handler(), "/tags/unknown"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown tag status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestGraphJSON(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	resp, body := get(t, s.Handler(), "/api/graph")
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var decoded struct {
+		Nodes []graphNode `json:"nodes"`
+		Links []graphLink `json:"links"`
+	}
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(decoded.Nodes) != 2 {
+		t.Errorf("got %d nodes, want 2", len(decoded.Nodes))
+	}
+	want := graphLink{Source: "child-20250101010000", Target: "parent-20250101000000", Type: "broader"}
+	if len(decoded.Links) != 1 || decoded.Links[0] != want {
+		t.Errorf("links = %v, want [%v]", decoded.Links, want)
+	}
+}
+
+func TestStaticAssets(t *testing.T) {
+	s, _ := newTestServer(t, Options{})
+
+	for _, path := range []string{"/static/style.css", "/static/graph.js", "/graph"} {
+		if resp, _ := get(t, s.Handler(), path); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d, want 200", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+// Package shacl validates RDF graphs against a subset of SHACL Core:
+// sh:targetClass and sh:targetNode, property shapes with IRI paths, and the
+// minCount, maxCount, datatype, class, pattern, and in constraints.
+package shacl
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	sh             = "http://www.w3.org/ns/shacl#"
+	rdfType        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	rdfFirst       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	rdfRest        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	rdfNil         = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	rdfLangString  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	rdfsSubClassOf = "http://www.w3.org/2000/01/rdf-schema#subClassOf"
+	xsd            = "http://www.w3.org/2001/XMLSchema#"
+	xsdString      = xsd + "string"
+)
+
+// Predicates a shape may use. Anything else in the sh: namespace is
+// rejected rather than silently ignored.
+var shapePredicates = map[string]bool{
+	"targetClass": true, "targetNode": true, "property": true, "path": true,
+	"minCount": true, "maxCount": true, "datatype": true, "class": true,
+	"pattern": true, "flags": true, "in": true, "severity": true,
+	"message": true, "deactivated": true,
+	"name": true, "description": true, "order": true, "group": true,
+}
+
+// Shape is a node shape, or a property shape when Path is set.
+type Shape struct {
+	ID          string
+	TargetClass []string
+	TargetNode  []rdf.Term
+	Path        string
+	MinCount    int // -1 when unset
+	MaxCount    int // -1 when unset
+	Datatype    string
+	Class       []string
+	Pattern     *regexp.Regexp
+	In          []rdf.Term
+	Properties  []*Shape
+	Severity    string
+	Message     string
+	Deactivated bool
+
+	patternValue string
+}
+
+// Result is one validation failure.
+type Result struct {
+	Focus     rdf.Term
+	Path      string
+	Value     rdf.Term
+	Component string
+	Severity  string
+	Message   string
+}
+
+func (r Result) String() string {
+	s := r.Focus.String()
+	if r.Path != "" {
+		s += " <" + r.Path + ">"
+	}
+	return s + ": " + r.Message
+}
+
+// Parse reads the shapes in a shapes graph. Shapes with a target are
+// returned; shapes reached through sh:property are nested in them.
+func Parse(triples []*rdf.Triple) ([]*Shape, error) {
+	g := index(triples)
+	p := &parser{g: g, shapes: make(map[string]*Shape), visiting: make(map[string]bool)}
+
+	var roots []string
+	for subject, props := range g.props {
+		if len(props[sh+"targetClass"]) > 0 || len(props[sh+"targetNode"]) > 0 {
+			roots = append(roots, subject)
+		}
+	}
+	sort.Strings(roots)
+
+	var shapes []*Shape
+	for _, subject := range roots {
+		shape, err := p.shape(subject)
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+type parser struct {
+	g        *graph
+	shapes   map[string]*Shape
+	visiting map[string]bool
+}
+
+func (p *parser) shape(subject string) (*Shape, error) {
+	if s, ok := p.shapes[subject]; ok {
+		return s, nil
+	}
+	if p.visiting[subject] {
+		return nil, fmt.Errorf("shape %s refers to itself", subject)
+	}
+	p.visiting[subject] = true
+	defer delete(p.visiting, subject)
+
+	props := p.g.props[subject]
+	s := &Shape{ID: subject, MinCount: -1, MaxCount: -1, Severity: "Violation"}
+	fail := func(format string, args ...any) (*Shape, error) {
+		return nil, fmt.Errorf("shape %s: %s", subject, fmt.Sprintf(format, args...))
+	}
+
+	preds := make([]string, 0, len(props))
+	for pred := range props {
+		preds = append(preds, pred)
+	}
+	sort.Strings(preds)
+	for _, pred := range preds {
+		name, ok := strings.CutPrefix(pred, sh)
+		if !ok {
+			continue
+		}
+		if !shapePredicates[name] {
+			return fail("sh:%s is not supported", name)
+		}
+		if len(props[pred]) > 1 && name != "targetClass" && name != "targetNode" && name != "property" && name != "class" && name != "message" {
+			return fail("more than one sh:%s", name)
+		}
+	}
+
+	for _, o := range props[sh+"targetClass"] {
+		s.TargetClass = append(s.TargetClass, o.RawValue())
+	}
+	s.TargetNode = props[sh+"targetNode"]
+	for _, o := range props[sh+"class"] {
+		s.Class = append(s.Class, o.RawValue())
+	}
+
+	if o := first(props[sh+"path"]); o != nil {
+		r, ok := o.(*rdf.Resource)
+		if !ok {
+			return fail("only IRI paths are supported")
+		}
+		s.Path = r.URI
+	}
+	for _, c := range []struct {
+		name string
+		dst  *int
+	}{{"minCount", &s.MinCount}, {"maxCount", &s.MaxCount}} {
+		if o := first(props[sh+c.name]); o != nil {
+			n, err := strconv.Atoi(o.RawValue())
+			if err != nil || n < 0 {
+				return fail("sh:%s %s is not a non-negative integer", c.name, o)
+			}
+			*c.dst = n
+		}
+	}
+	if (s.MinCount >= 0 || s.MaxCount >= 0) && s.Path == "" {
+		return fail("sh:minCount and sh:maxCount need sh:path")
+	}
+	if o := first(props[sh+"datatype"]); o != nil {
+		s.Datatype = o.RawValue()
+	}
+	if o := first(props[sh+"pattern"]); o != nil {
+		expr := o.RawValue()
+		if f := first(props[sh+"flags"]); f != nil {
+			flags := f.RawValue()
+			if strings.Trim(flags, "ims") != "" {
+				return fail("sh:flags %q: only i, m, and s are supported", flags)
+			}
+			if flags != "" {
+				expr = "(?" + flags + ")" + expr
+			}
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fail("sh:pattern: %v", err)
+		}
+		s.Pattern = re
+		s.patternValue = o.RawValue()
+	}
+	if o := first(props[sh+"in"]); o != nil {
+		list, err := p.g.list(o)
+		if err != nil {
+			return fail("sh:in: %v", err)
+		}
+		s.In = list
+	}
+	if o := first(props[sh+"severity"]); o != nil {
+		sev, ok := strings.CutPrefix(o.RawValue(), sh)
+		if !ok || (sev != "Violation" && sev != "Warning" && sev != "Info") {
+			return fail("unknown sh:severity %s", o)
+		}
+		s.Severity = sev
+	}
+	if msgs := props[sh+"message"]; len(msgs) > 0 {
+		s.Message = msgs[0].RawValue()
+	}
+	if o := first(props[sh+"deactivated"]); o != nil {
+		s.Deactivated = o.RawValue() == "true"
+	}
+
+	for _, o := range props[sh+"property"] {
+		prop, err := p.shape(key(o))
+		if err != nil {
+			return nil, err
+		}
+		if prop.Path == "" {
+			return fail("sh:property %s has no sh:path", o)
+		}
+		s.Properties = append(s.Properties, prop)
+	}
+
+	p.shapes[subject] = s
+	return s, nil
+}
+
+// Validate checks data against the shapes and returns every failure,
+// ordered by focus node, path, constraint, and value.
+func Validate(data []*rdf.Triple, shapes []*Shape) []Result {
+	g := index(data)
+	var results []Result
+	for _, s := range shapes {
+		if s.Deactivated {
+			continue
+		}
+		for _, focus := range g.focusNodes(s) {
+			values := []rdf.Term{focus}
+			if s.Path != "" {
+				values = g.props[key(focus)][s.Path]
+			}
+			results = append(results, g.check(s, focus, values)...)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if key(a.Focus) != key(b.Focus) {
+			return key(a.Focus) < key(b.Focus)
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return valueKey(a.Value) < valueKey(b.Value)
+	})
+	return results
+}
+
+// check validates the value nodes of s for one focus node.
+func (g *graph) check(s *Shape, focus rdf.Term, values []rdf.Term) []Result {
+	if s.Deactivated {
+		return nil
+	}
+	var results []Result
+	report := func(component string, value rdf.Term, format string, args ...any) {
+		msg := s.Message
+		if msg == "" {
+			msg = fmt.Sprintf(format, args...)
+		}
+		results = append(results, Result{Focus: focus, Path: s.Path, Value: value, Component: component, Severity: s.Severity, Message: msg})
+	}
+
+	if s.MinCount >= 0 && len(values) < s.MinCount {
+		report("MinCount", nil, "%d values, want at least %d", len(values), s.MinCount)
+	}
+	if s.MaxCount >= 0 && len(values) > s.MaxCount {
+		report("MaxCount", nil, "%d values, want at most %d", len(values), s.MaxCount)
+	}
+
+	for _, v := range values {
+		if s.Datatype != "" && !hasDatatype(v, s.Datatype) {
+			report("Datatype", v, "%s is not a valid <%s>", v, s.Datatype)
+		}
+		for _, class := range s.Class {
+			if !g.isInstance(v, class) {
+				report("Class", v, "%s is not a <%s>", v, class)
+			}
+		}
+		if s.Pattern != nil && !matches(s.Pattern, v) {
+			report("Pattern", v, "%s does not match %q", v, s.patternValue)
+		}
+		if s.In != nil && !contains(s.In, v) {
+			names := make([]string, len(s.In))
+			for i, t := range s.In {
+				names[i] = t.String()
+			}
+			report("In", v, "%s is not one of %s", v, strings.Join(names, ", "))
+		}
+		for _, prop := range s.Properties {
+			results = append(results, g.check(prop, v, g.props[key(v)][prop.Path])...)
+		}
+	}
+	return results
+}
+
+func hasDatatype(v rdf.Term, datatype string) bool {
+	lit, ok := v.(*rdf.Literal)
+	if !ok {
+		return false
+	}
+	actual := xsdString
+	if lit.Language != "" {
+		actual = rdfLangString
+	} else if lit.Datatype != nil {
+		actual = lit.Datatype.RawValue()
+	}
+	if actual != datatype {
+		return false
+	}
+
+	// Ill-typed literals fail too, for the types the projection uses.
+	var err error
+	switch datatype {
+	case xsd + "dateTime":
+		_, err = time.Parse(time.RFC3339Nano, lit.Value)
+	case xsd + "integer":
+		_, err = strconv.ParseInt(lit.Value, 10, 64)
+	case xsd + "boolean":
+		if lit.Value != "true" && lit.Value != "false" && lit.Value != "1" && lit.Value != "0" {
+			return false
+		}
+	}
+	return err == nil
+}
+
+func matches(re *regexp.Regexp, v rdf.Term) bool {
+	switch t := v.(type) {
+	case *rdf.Literal:
+		return re.MatchString(t.Value)
+	case *rdf.Resource:
+		return re.MatchString(t.URI)
+	}
+	return false
+}
+
+func contains(list []rdf.Term, v rdf.Term) bool {
+	k := key(v)
+	for _, t := range list {
+		if key(t) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// graph indexes triples by subject and predicate.
+type graph struct {
+	props      map[string]map[string][]rdf.Term
+	terms      map[string]rdf.Term
+	superclass map[string][]string
+}
+
+func index(triples []*rdf.Triple) *graph {
+	g := &graph{
+		props:      make(map[string]map[string][]rdf.Term),
+		terms:      make(map[string]rdf.Term),
+		superclass: make(map[string][]string),
+	}
+	for _, t := range triples {
+		s := key(t.Subject)
+		p := t.Predicate.RawValue()
+		if g.props[s] == nil {
+			g.props[s] = make(map[string][]rdf.Term)
+		}
+		g.props[s][p] = append(g.props[s][p], t.Object)
+		g.terms[s] = t.Subject
+		if p == rdfsSubClassOf {
+			g.superclass[t.Subject.RawValue()] = append(g.superclass[t.Subject.RawValue()], t.Object.RawValue())
+		}
+	}
+	return g
+}
+
+func (g *graph) focusNodes(s *Shape) []rdf.Term {
+	seen := make(map[string]bool)
+	var nodes []rdf.Term
+	add := func(t rdf.Term) {
+		if k := key(t); !seen[k] {
+			seen[k] = true
+			nodes = append(nodes, t)
+		}
+	}
+	for _, t := range s.TargetNode {
+		add(t)
+	}
+	if len(s.TargetClass) > 0 {
+		subjects := make([]string, 0, len(g.props))
+		for subject := range g.props {
+			subjects = append(subjects, subject)
+		}
+		sort.Strings(subjects)
+		for _, subject := range subjects {
+			for _, class := range s.TargetClass {
+				if g.isInstance(g.terms[subject], class) {
+					add(g.terms[subject])
+					break
+				}
+			}
+		}
+	}
+	return nodes
+}
+
+// isInstance reports whether v has rdf:type class or one of its
+// subclasses.
+func (g *graph) isInstance(v rdf.Term, class string) bool {
+	if _, ok := v.(*rdf.Literal); ok {
+		return false
+	}
+	for _, t := range g.props[key(v)][rdfType] {
+		if g.isSubclass(t.RawValue(), class, make(map[string]bool)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *graph) isSubclass(sub, class string, seen map[string]bool) bool {
+	if sub == class {
+		return true
+	}
+	if seen[sub] {
+		return false
+	}
+	seen[sub] = true
+	for _, super := range g.superclass[sub] {
+		if g.isSubclass(super, class, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// list reads an RDF collection.
+func (g *graph) list(head rdf.Term) ([]rdf.Term, error) {
+	items := []rdf.Term{}
+	seen := make(map[string]bool)
+	for node := head; node.RawValue() != rdfNil; {
+		k := key(node)
+		if seen[k] {
+			return nil, fmt.Errorf("list %s is circular", head)
+		}
+		seen[k] = true
+		item, rest := first(g.props[k][rdfFirst]), first(g.props[k][rdfRest])
+		if item == nil || rest == nil {
+			return nil, fmt.Errorf("%s is not a well-formed list", node)
+		}
+		items = append(items, item)
+		node = rest
+	}
+	return items, nil
+}
+
+func valueKey(t rdf.Term) string {
+	if t == nil {
+		return ""
+	}
+	return key(t)
+}
+
+func first(terms []rdf.Term) rdf.Term {
+	if len(terms) == 0 {
+		return nil
+	}
+	return terms[0]
+}
+
+// key identifies a term. Plain literals and xsd:string literals are the
+// same value, whichever the parser produced.
+func key(t rdf.Term) string {
+	if lit, ok := t.(*rdf.Literal); ok {
+		if lit.Language != "" {
+			return strconv.Quote(lit.Value) + "@" + strings.ToLower(lit.Language)
+		}
+		datatype := xsdString
+		if lit.Datatype != nil {
+			datatype = lit.Datatype.RawValue()
+		}
+		return strconv.Quote(lit.Value) + "^^" + datatype
+	}
+	return t.String()
+}
@@ -0,0 +1,115 @@
+package shacl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/export"
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	rdf "github.com/deiu/rdf2go"
+)
+
+const prefixes = `@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+@prefix dcterms: <http://purl.org/dc/terms/> .
+@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+@prefix weave: <http://weave.dev/vocab#> .
+@prefix ex: <http://example.org/shapes#> .
+`
+
+func parseShapes(t *testing.T, src string) []*Shape {
+	t.Helper()
+	triples, err := export.Read(strings.NewReader(prefixes+src), export.Turtle)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	shapes, err := Parse(triples)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return shapes
+}
+
+func vault() []*rdf.Triple {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return rdfproj.VaultToTriples([]markdown.Note{
+		{ID: "decision", Title: "Pick Go", Type: "Decision", Created: ts, Links: []links.Link{{ID: "topic", Type: "broader"}}},
+		{ID: "topic", Title: "languages", Type: "Note", Created: ts},
+	}, "http://example.org")
+}
+
+func TestValidate(t *testing.T) {
+	shapes := parseShapes(t, `
+ex:Decision a sh:NodeShape ;
+  sh:targetClass weave:Decision ;
+  sh:property [ sh:path weave:status ; sh:minCount 1 ; sh:message "every Decision needs a status" ] ;
+  sh:property [ sh:path skos:broader ; sh:class skos:Concept ] .
+
+ex:Note a sh:NodeShape ;
+  sh:targetClass weave:Note ;
+  sh:property [ sh:path dcterms:title ; sh:minCount 1 ; sh:maxCount 1 ; sh:pattern "^[A-Z]" ; sh:severity sh:Warning ] ;
+  sh:property [ sh:path dcterms:created ; sh:datatype xsd:dateTime ] ;
+  sh:property [ sh:path dcterms:identifier ; sh:in ( "decision" "other" ) ] .
+`)
+
+	var got []string
+	for _, r := range Validate(vault(), shapes) {
+		got = append(got, r.Severity+" "+r.Component+" "+r.String())
+	}
+	want := []string{
+		`Violation MinCount <http://example.org/notes/decision> <http://weave.dev/vocab#status>: every Decision needs a status`,
+		`Violation Class <http://example.org/notes/decision> <http://www.w3.org/2004/02/skos/core#broader>: <http://example.org/notes/topic> is not a <http://www.w3.org/2004/02/skos/core#Concept>`,
+		`Violation In <http://example.org/notes/topic> <http://purl.org/dc/terms/identifier>: "topic" is not one of "decision", "other"`,
+		`Warning Pattern <http://example.org/notes/topic> <http://purl.org/dc/terms/title>: "languages" does not match "^[A-Z]"`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestValidateDatatype(t *testing.T) {
+	shapes := parseShapes(t, `
+ex:Created sh:targetNode <http://example.org/notes/a> ;
+  sh:path dcterms:created ;
+  sh:datatype xsd:dateTime .
+`)
+	a := rdf.NewResource("http://example.org/notes/a")
+	created := rdf.NewResource("http://purl.org/dc/terms/created")
+	data := []*rdf.Triple{
+		rdf.NewTriple(a, created, rdf.NewLiteralWithDatatype("2025-01-01T00:00:00Z", rdf.NewResource(xsd+"dateTime"))),
+		rdf.NewTriple(a, created, rdf.NewLiteralWithDatatype("yesterday", rdf.NewResource(xsd+"dateTime"))),
+		rdf.NewTriple(a, created, rdf.NewLiteral("2025-01-01T00:00:00Z")),
+	}
+
+	results := Validate(data, shapes)
+	if len(results) != 2 {
+		t.Fatalf("Validate() = %v, want 2 results", results)
+	}
+	for _, r := range results {
+		if r.Component != "Datatype" {
+			t.Errorf("Component = %s, want Datatype", r.Component)
+		}
+	}
+}
+
+func TestParseRejectsUnsupported(t *testing.T) {
+	for name, src := range map[string]string{
+		"constraint": `ex:S sh:targetClass weave:Note ; sh:minLength 3 .`,
+		"path":       `ex:S sh:targetClass weave:Note ; sh:property [ sh:path ( dcterms:title dcterms:title ) ] .`,
+		"count":      `ex:S sh:targetClass weave:Note ; sh:minCount 1 .`,
+		"flags":      `ex:S sh:targetClass weave:Note ; sh:property [ sh:path dcterms:title ; sh:pattern "a" ; sh:flags "x" ] .`,
+		"severity":   `ex:S sh:targetClass weave:Note ; sh:severity sh:Fatal .`,
+		"recursion":  `ex:S sh:targetClass weave:Note ; sh:property ex:P . ex:P sh:path dcterms:title ; sh:property ex:P .`,
+	} {
+		triples, err := export.Read(strings.NewReader(prefixes+src), export.Turtle)
+		if err != nil {
+			t.Fatalf("%s: Read() error = %v", name, err)
+		}
+		if _, err := Parse(triples); err == nil {
+			t.Errorf("%s: Parse() error = nil", name)
+		}
+	}
+}
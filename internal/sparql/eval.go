@@ -0,0 +1,291 @@
+package sparql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+type solution map[string]rdf.Term
+
+func (s solution) extend(name string, t rdf.Term) solution {
+	out := make(solution, len(s)+1)
+	for k, v := range s {
+		out[k] = v
+	}
+	out[name] = t
+	return out
+}
+
+// Result holds the outcome of a query. Which fields are set depends on the
+// query form: Vars and Bindings for SELECT, Boolean for ASK, Triples for
+// CONSTRUCT.
+type Result struct {
+	Form     QueryForm
+	Vars     []string
+	Bindings []map[string]rdf.Term
+	Boolean  bool
+	Triples  []*rdf.Triple
+}
+
+// Exec parses and evaluates a query against the store.
+func Exec(store *Store, src string) (*Result, error) {
+	q, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return q.Eval(store), nil
+}
+
+func (q *Query) Eval(store *Store) *Result {
+	solutions := q.Where.eval(store, solution{})
+
+	if q.Form == Ask {
+		return &Result{Form: Ask, Boolean: len(solutions) > 0}
+	}
+
+	if len(q.OrderBy) > 0 {
+		sortSolutions(solutions, q.OrderBy)
+	}
+
+	if q.Form == Construct {
+		solutions = slice(solutions, q.Offset, q.Limit)
+		return &Result{Form: Construct, Triples: construct(q.Template, solutions)}
+	}
+
+	bindings := make([]map[string]rdf.Term, 0, len(solutions))
+	seen := make(map[string]bool)
+	for _, s := range solutions {
+		row := make(map[string]rdf.Term, len(q.Vars))
+		var key strings.Builder
+		for _, v := range q.Vars {
+			if t, ok := s[v]; ok {
+				row[v] = t
+				key.WriteString(t.String())
+			}
+			key.WriteByte(0)
+		}
+		if q.Distinct {
+			if seen[key.String()] {
+				continue
+			}
+			seen[key.String()] = true
+		}
+		bindings = append(bindings, row)
+	}
+
+	return &Result{
+		Form:     Select,
+		Vars:     q.Vars,
+		Bindings: slice(bindings, q.Offset, q.Limit),
+	}
+}
+
+func slice[T any](rows []T, offset, limit int) []T {
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+// eval evaluates the group for one incoming solution. Bound variables are
+// substituted into patterns, so OPTIONAL and UNION see the bindings made
+// before them.
+func (g *group) eval(store *Store, in solution) []solution {
+	current := []solution{in}
+
+	for _, el := range g.elements {
+		var next []solution
+		for _, s := range current {
+			switch el := el.(type) {
+			case *bgp:
+				next = append(next, el.eval(store, s)...)
+			case *group:
+				next = append(next, el.eval(store, s)...)
+			case *optional:
+				matched := el.group.eval(store, s)
+				if len(matched) == 0 {
+					next = append(next, s)
+				} else {
+					next = append(next, matched...)
+				}
+			case *union:
+				for _, branch := range el.branches {
+					next = append(next, branch.eval(store, s)...)
+				}
+			}
+		}
+		current = next
+		if len(current) == 0 {
+			return nil
+		}
+	}
+
+	if len(g.filters) == 0 {
+		return current
+	}
+
+	var out []solution
+	for _, s := range current {
+		keep := true
+		for _, f := range g.filters {
+			if ok, err := evalBool(f, s); err != nil || !ok {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (b *bgp) eval(store *Store, in solution) []solution {
+	current := []solution{in}
+	for _, tp := range b.patterns {
+		var next []solution
+		for _, s := range current {
+			next = append(next, tp.match(store, s)...)
+		}
+		current = next
+		if len(current) == 0 {
+			return nil
+		}
+	}
+	return current
+}
+
+func (tp triplePattern) match(store *Store, s solution) []solution {
+	subj := tp.S.resolve(s)
+	pred := tp.P.resolve(s)
+	obj := tp.O.resolve(s)
+
+	var out []solution
+	for _, t := range store.Match(subj, pred, obj) {
+		ext := s
+		ok := true
+		for _, pair := range []struct {
+			n node
+			t rdf.Term
+		}{{tp.S, t.Subject}, {tp.P, t.Predicate}, {tp.O, t.Object}} {
+			if pair.n.Var == "" {
+				continue
+			}
+			if bound, exists := ext[pair.n.Var]; exists {
+				// The same variable used twice in one pattern.
+				if !bound.Equal(pair.t) {
+					ok = false
+					break
+				}
+				continue
+			}
+			ext = ext.extend(pair.n.Var, pair.t)
+		}
+		if ok {
+			out = append(out, ext)
+		}
+	}
+	return out
+}
+
+func (n node) resolve(s solution) rdf.Term {
+	if n.Var == "" {
+		return n.Term
+	}
+	return s[n.Var]
+}
+
+func construct(template []triplePattern, solutions []solution) []*rdf.Triple {
+	var out []*rdf.Triple
+	seen := make(map[string]bool)
+
+	for i, s := range solutions {
+		blanks := make(map[string]rdf.Term)
+		instantiate := func(n node) rdf.Term {
+			if strings.HasPrefix(n.Var, "_:") {
+				if _, bound := s[n.Var]; !bound {
+					if _, ok := blanks[n.Var]; !ok {
+						blanks[n.Var] = rdf.NewBlankNode(fmt.Sprintf("%s_%d", strings.TrimPrefix(n.Var, "_:"), i))
+					}
+					return blanks[n.Var]
+				}
+			}
+			return n.resolve(s)
+		}
+
+		for _, tp := range template {
+			subj, pred, obj := instantiate(tp.S), instantiate(tp.P), instantiate(tp.O)
+			if subj == nil || pred == nil || obj == nil {
+				continue
+			}
+			if _, ok := subj.(*rdf.Literal); ok {
+				continue
+			}
+			if _, ok := pred.(*rdf.Resource); !ok {
+				continue
+			}
+			t := rdf.NewTriple(subj, pred, obj)
+			if seen[t.String()] {
+				continue
+			}
+			seen[t.String()] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func sortSolutions(solutions []solution, conds []orderCond) {
+	sort.SliceStable(solutions, func(i, j int) bool {
+		for _, c := range conds {
+			a, _ := c.expr.eval(solutions[i])
+			b, _ := c.expr.eval(solutions[j])
+			cmp := orderTerms(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if c.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// orderTerms gives the ORDER BY ordering: unbound, blank nodes, IRIs, then
+// literals, with literals compared by value where possible.
+func orderTerms(a, b rdf.Term) int {
+	ra, rb := orderRank(a), orderRank(b)
+	if ra != rb {
+		return compareFloat(float64(ra), float64(rb))
+	}
+	if a == nil {
+		return 0
+	}
+	if ra == 3 {
+		if c, err := compareValues(a, b); err == nil {
+			return c
+		}
+	}
+	return strings.Compare(a.String(), b.String())
+}
+
+func orderRank(t rdf.Term) int {
+	switch t.(type) {
+	case nil:
+		return 0
+	case *rdf.BlankNode:
+		return 1
+	case *rdf.Resource:
+		return 2
+	}
+	return 3
+}
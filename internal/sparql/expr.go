@@ -0,0 +1,537 @@
+package sparql
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+// errType is returned when an expression cannot be evaluated for a
+// solution. As in SPARQL, a FILTER that errors rejects the solution.
+var errType = errors.New("type error")
+
+type expr interface {
+	eval(s solution) (rdf.Term, error)
+}
+
+type varExpr struct{ name string }
+
+type constExpr struct{ term rdf.Term }
+
+type boundExpr struct{ name string }
+
+type unaryExpr struct {
+	op  string
+	arg expr
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expr
+}
+
+type inExpr struct {
+	value   expr
+	list    []expr
+	negated bool
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+func (e varExpr) eval(s solution) (rdf.Term, error) {
+	if t, ok := s[e.name]; ok {
+		return t, nil
+	}
+	return nil, errType
+}
+
+func (e constExpr) eval(solution) (rdf.Term, error) {
+	return e.term, nil
+}
+
+func (e boundExpr) eval(s solution) (rdf.Term, error) {
+	_, ok := s[e.name]
+	return boolTerm(ok), nil
+}
+
+func (e unaryExpr) eval(s solution) (rdf.Term, error) {
+	v, err := e.arg.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "!":
+		b, err := ebv(v)
+		if err != nil {
+			return nil, err
+		}
+		return boolTerm(!b), nil
+	case "-":
+		n, ok := numericValue(v)
+		if !ok {
+			return nil, errType
+		}
+		return numberTerm(-n, datatypeOf(v)), nil
+	}
+	return nil, errType
+}
+
+func (e binaryExpr) eval(s solution) (rdf.Term, error) {
+	switch e.op {
+	case "||", "&&":
+		return e.evalLogical(s)
+	}
+
+	l, err := e.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "=":
+		eq, err := termsEqual(l, r)
+		if err != nil {
+			return nil, err
+		}
+		return boolTerm(eq), nil
+	case "!=":
+		eq, err := termsEqual(l, r)
+		if err != nil {
+			return nil, err
+		}
+		return boolTerm(!eq), nil
+	case "<", ">", "<=", ">=":
+		c, err := compareValues(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch e.op {
+		case "<":
+			return boolTerm(c < 0), nil
+		case ">":
+			return boolTerm(c > 0), nil
+		case "<=":
+			return boolTerm(c <= 0), nil
+		default:
+			return boolTerm(c >= 0), nil
+		}
+	case "+", "-", "*", "/":
+		a, ok1 := numericValue(l)
+		b, ok2 := numericValue(r)
+		if !ok1 || !ok2 {
+			return nil, errType
+		}
+		dt := widerType(datatypeOf(l), datatypeOf(r))
+		switch e.op {
+		case "+":
+			return numberTerm(a+b, dt), nil
+		case "-":
+			return numberTerm(a-b, dt), nil
+		case "*":
+			return numberTerm(a*b, dt), nil
+		default:
+			if b == 0 && dt != xsdDouble {
+				return nil, errType
+			}
+			if dt == xsdInteger {
+				dt = xsdDecimal
+			}
+			return numberTerm(a/b, dt), nil
+		}
+	}
+	return nil, errType
+}
+
+// evalLogical implements SPARQL's three-valued || and &&: an error on one
+// side is forgiven when the other side decides the result.
+func (e binaryExpr) evalLogical(s solution) (rdf.Term, error) {
+	l, lerr := evalBool(e.left, s)
+	r, rerr := evalBool(e.right, s)
+
+	if e.op == "||" {
+		if (lerr == nil && l) || (rerr == nil && r) {
+			return boolTerm(true), nil
+		}
+	} else {
+		if (lerr == nil && !l) || (rerr == nil && !r) {
+			return boolTerm(false), nil
+		}
+	}
+	if lerr != nil {
+		return nil, lerr
+	}
+	if rerr != nil {
+		return nil, rerr
+	}
+	return boolTerm(e.op == "&&"), nil
+}
+
+func (e inExpr) eval(s solution) (rdf.Term, error) {
+	v, err := e.value.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	var firstErr error
+	for _, item := range e.list {
+		t, err := item.eval(s)
+		if err == nil {
+			var eq bool
+			eq, err = termsEqual(v, t)
+			if err == nil && eq {
+				return boolTerm(!e.negated), nil
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return boolTerm(e.negated), nil
+}
+
+type builtin struct {
+	arity int // -1 means 2 or 3 (REGEX)
+	fn    func(args []rdf.Term) (rdf.Term, error)
+}
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"STR": {1, func(a []rdf.Term) (rdf.Term, error) {
+			if _, ok := a[0].(*rdf.BlankNode); ok {
+				return nil, errType
+			}
+			return rdf.NewLiteral(a[0].RawValue()), nil
+		}},
+		"LANG": {1, func(a []rdf.Term) (rdf.Term, error) {
+			lit, ok := a[0].(*rdf.Literal)
+			if !ok {
+				return nil, errType
+			}
+			return rdf.NewLiteral(lit.Language), nil
+		}},
+		"DATATYPE": {1, func(a []rdf.Term) (rdf.Term, error) {
+			lit, ok := a[0].(*rdf.Literal)
+			if !ok {
+				return nil, errType
+			}
+			return rdf.NewResource(datatypeOf(lit)), nil
+		}},
+		"ISIRI":     {1, func(a []rdf.Term) (rdf.Term, error) { return boolTerm(isIRI(a[0])), nil }},
+		"ISURI":     {1, func(a []rdf.Term) (rdf.Term, error) { return boolTerm(isIRI(a[0])), nil }},
+		"ISBLANK":   {1, func(a []rdf.Term) (rdf.Term, error) { _, ok := a[0].(*rdf.BlankNode); return boolTerm(ok), nil }},
+		"ISLITERAL": {1, func(a []rdf.Term) (rdf.Term, error) { _, ok := a[0].(*rdf.Literal); return boolTerm(ok), nil }},
+		"ISNUMERIC": {1, func(a []rdf.Term) (rdf.Term, error) { _, ok := numericValue(a[0]); return boolTerm(ok), nil }},
+		"SAMETERM":  {2, func(a []rdf.Term) (rdf.Term, error) { return boolTerm(a[0].Equal(a[1])), nil }},
+		"STRLEN": {1, func(a []rdf.Term) (rdf.Term, error) {
+			s, err := stringArg(a[0])
+			if err != nil {
+				return nil, err
+			}
+			return numberTerm(float64(len([]rune(s))), xsdInteger), nil
+		}},
+		"LCASE":     {1, stringFunc(strings.ToLower)},
+		"UCASE":     {1, stringFunc(strings.ToUpper)},
+		"CONTAINS":  {2, stringPredicate(strings.Contains)},
+		"STRSTARTS": {2, stringPredicate(strings.HasPrefix)},
+		"STRENDS":   {2, stringPredicate(strings.HasSuffix)},
+		"LANGMATCHES": {2, func(a []rdf.Term) (rdf.Term, error) {
+			tag, err := stringArg(a[0])
+			if err != nil {
+				return nil, err
+			}
+			rng, err := stringArg(a[1])
+			if err != nil {
+				return nil, err
+			}
+			if rng == "*" {
+				return boolTerm(tag != ""), nil
+			}
+			tag, rng = strings.ToLower(tag), strings.ToLower(rng)
+			return boolTerm(tag == rng || strings.HasPrefix(tag, rng+"-")), nil
+		}},
+		"REGEX": {-1, func(a []rdf.Term) (rdf.Term, error) {
+			s, err := stringArg(a[0])
+			if err != nil {
+				return nil, err
+			}
+			pattern, err := stringArg(a[1])
+			if err != nil {
+				return nil, err
+			}
+			if len(a) == 3 {
+				flags, err := stringArg(a[2])
+				if err != nil {
+					return nil, err
+				}
+				if flags != "" {
+					pattern = "(?" + flags + ")" + pattern
+				}
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errType
+			}
+			return boolTerm(re.MatchString(s)), nil
+		}},
+	}
+}
+
+func (e callExpr) eval(s solution) (rdf.Term, error) {
+	b := builtins[e.name]
+	if b.arity >= 0 && len(e.args) != b.arity {
+		return nil, errType
+	}
+	if b.arity < 0 && (len(e.args) < 2 || len(e.args) > 3) {
+		return nil, errType
+	}
+
+	args := make([]rdf.Term, len(e.args))
+	for i, a := range e.args {
+		v, err := a.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	return b.fn(args)
+}
+
+func stringFunc(f func(string) string) func([]rdf.Term) (rdf.Term, error) {
+	return func(a []rdf.Term) (rdf.Term, error) {
+		lit, ok := a[0].(*rdf.Literal)
+		if !ok {
+			return nil, errType
+		}
+		out := *lit
+		out.Value = f(lit.Value)
+		return &out, nil
+	}
+}
+
+func stringPredicate(f func(string, string) bool) func([]rdf.Term) (rdf.Term, error) {
+	return func(a []rdf.Term) (rdf.Term, error) {
+		x, err := stringArg(a[0])
+		if err != nil {
+			return nil, err
+		}
+		y, err := stringArg(a[1])
+		if err != nil {
+			return nil, err
+		}
+		return boolTerm(f(x, y)), nil
+	}
+}
+
+func stringArg(t rdf.Term) (string, error) {
+	lit, ok := t.(*rdf.Literal)
+	if !ok {
+		return "", errType
+	}
+	return lit.Value, nil
+}
+
+func evalBool(e expr, s solution) (bool, error) {
+	v, err := e.eval(s)
+	if err != nil {
+		return false, err
+	}
+	return ebv(v)
+}
+
+// ebv computes the effective boolean value of a term.
+func ebv(t rdf.Term) (bool, error) {
+	lit, ok := t.(*rdf.Literal)
+	if !ok {
+		return false, errType
+	}
+	switch datatypeOf(lit) {
+	case xsdBoolean:
+		return lit.Value == "true" || lit.Value == "1", nil
+	case xsdString, langString:
+		return lit.Value != "", nil
+	}
+	if n, ok := numericValue(lit); ok {
+		return n != 0 && !math.IsNaN(n), nil
+	}
+	return false, errType
+}
+
+const langString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+
+func datatypeOf(t rdf.Term) string {
+	lit, ok := t.(*rdf.Literal)
+	if !ok {
+		return ""
+	}
+	if lit.Language != "" {
+		return langString
+	}
+	if lit.Datatype == nil {
+		return xsdString
+	}
+	return lit.Datatype.RawValue()
+}
+
+func isIRI(t rdf.Term) bool {
+	_, ok := t.(*rdf.Resource)
+	return ok
+}
+
+func isNumericType(dt string) bool {
+	switch dt {
+	case xsdInteger, xsdDecimal, xsdDouble,
+		"http://www.w3.org/2001/XMLSchema#float",
+		"http://www.w3.org/2001/XMLSchema#int",
+		"http://www.w3.org/2001/XMLSchema#long",
+		"http://www.w3.org/2001/XMLSchema#nonNegativeInteger",
+		"http://www.w3.org/2001/XMLSchema#positiveInteger":
+		return true
+	}
+	return false
+}
+
+func numericValue(t rdf.Term) (float64, bool) {
+	lit, ok := t.(*rdf.Literal)
+	if !ok || !isNumericType(datatypeOf(lit)) {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(lit.Value, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func widerType(a, b string) string {
+	rank := func(dt string) int {
+		switch dt {
+		case xsdDouble, "http://www.w3.org/2001/XMLSchema#float":
+			return 2
+		case xsdDecimal:
+			return 1
+		}
+		return 0
+	}
+	if rank(a) >= rank(b) {
+		if rank(a) == 0 {
+			return xsdInteger
+		}
+		return a
+	}
+	return b
+}
+
+func numberTerm(n float64, dt string) rdf.Term {
+	var text string
+	switch dt {
+	case xsdInteger:
+		text = strconv.FormatInt(int64(n), 10)
+	case xsdDouble:
+		text = strconv.FormatFloat(n, 'E', -1, 64)
+	default:
+		dt = xsdDecimal
+		text = strconv.FormatFloat(n, 'f', -1, 64)
+		if !strings.Contains(text, ".") {
+			text += ".0"
+		}
+	}
+	return rdf.NewLiteralWithDatatype(text, rdf.NewResource(dt))
+}
+
+func boolTerm(b bool) rdf.Term {
+	return rdf.NewLiteralWithDatatype(strconv.FormatBool(b), rdf.NewResource(xsdBoolean))
+}
+
+func dateTimeValue(t rdf.Term) (time.Time, bool) {
+	if datatypeOf(t) != xsdDateTime {
+		return time.Time{}, false
+	}
+	v, err := time.Parse(time.RFC3339Nano, t.RawValue())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return v, true
+}
+
+func isStringLike(t rdf.Term) bool {
+	return datatypeOf(t) == xsdString
+}
+
+// termsEqual implements '=': value comparison for numbers, strings, and
+// dateTimes, RDF term equality otherwise.
+func termsEqual(a, b rdf.Term) (bool, error) {
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			return x == y, nil
+		}
+	}
+	if x, ok := dateTimeValue(a); ok {
+		if y, ok := dateTimeValue(b); ok {
+			return x.Equal(y), nil
+		}
+	}
+	if isStringLike(a) && isStringLike(b) {
+		return a.RawValue() == b.RawValue(), nil
+	}
+	if a.Equal(b) {
+		return true, nil
+	}
+	_, aLit := a.(*rdf.Literal)
+	_, bLit := b.(*rdf.Literal)
+	if aLit && bLit && datatypeOf(a) != datatypeOf(b) && datatypeOf(a) != langString && datatypeOf(b) != langString {
+		return false, errType
+	}
+	return false, nil
+}
+
+// compareValues orders two literals of comparable kinds.
+func compareValues(a, b rdf.Term) (int, error) {
+	if x, ok := numericValue(a); ok {
+		if y, ok := numericValue(b); ok {
+			return compareFloat(x, y), nil
+		}
+		return 0, errType
+	}
+	if x, ok := dateTimeValue(a); ok {
+		if y, ok := dateTimeValue(b); ok {
+			return x.Compare(y), nil
+		}
+		return 0, errType
+	}
+	if isStringLike(a) && isStringLike(b) {
+		return strings.Compare(a.RawValue(), b.RawValue()), nil
+	}
+	if datatypeOf(a) == langString && datatypeOf(b) == langString && a.(*rdf.Literal).Language == b.(*rdf.Literal).Language {
+		return strings.Compare(a.RawValue(), b.RawValue()), nil
+	}
+	if datatypeOf(a) == xsdBoolean && datatypeOf(b) == xsdBoolean {
+		return strings.Compare(a.RawValue(), b.RawValue()), nil
+	}
+	return 0, errType
+}
+
+func compareFloat(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
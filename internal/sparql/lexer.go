@@ -0,0 +1,298 @@
+package sparql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIRI
+	tokPName
+	tokVar
+	tokBlank
+	tokString
+	tokLangTag
+	tokNumber
+	tokKeyword
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// is reports whether the token is the given keyword or punctuation,
+// matching keywords case-insensitively as SPARQL does.
+func (t token) is(s string) bool {
+	switch t.kind {
+	case tokKeyword:
+		return strings.EqualFold(t.text, s)
+	case tokPunct:
+		return t.text == s
+	}
+	return false
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	tokens []token
+}
+
+func lex(src string) ([]token, error) {
+	l := &lexer{src: src}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...any) error {
+	return fmt.Errorf("syntax error at offset %d: %s", pos, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		}
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			l.pos++
+			continue
+		}
+		return
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	start := l.pos
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case c == '<':
+		if iri, ok := l.scanIRI(); ok {
+			return token{kind: tokIRI, text: iri, pos: start}, nil
+		}
+		if strings.HasPrefix(l.src[l.pos:], "<=") {
+			l.pos += 2
+			return token{kind: tokPunct, text: "<=", pos: start}, nil
+		}
+		l.pos++
+		return token{kind: tokPunct, text: "<", pos: start}, nil
+	case c == '?' || c == '$':
+		l.pos++
+		name := l.scanName()
+		if name == "" {
+			return token{}, l.errorf(start, "empty variable name")
+		}
+		return token{kind: tokVar, text: name, pos: start}, nil
+	case c == '"' || c == '\'':
+		s, err := l.scanString()
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokString, text: s, pos: start}, nil
+	case c == '@':
+		l.pos++
+		tag := l.scanWhile(func(r rune) bool { return r == '-' || isAlnum(r) })
+		if tag == "" {
+			return token{}, l.errorf(start, "empty language tag")
+		}
+		return token{kind: tokLangTag, text: tag, pos: start}, nil
+	case c == '_' && strings.HasPrefix(l.src[l.pos:], "_:"):
+		l.pos += 2
+		name := l.scanName()
+		if name == "" {
+			return token{}, l.errorf(start, "empty blank node label")
+		}
+		return token{kind: tokBlank, text: name, pos: start}, nil
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		return token{kind: tokNumber, text: l.scanNumber(), pos: start}, nil
+	}
+
+	for _, p := range []string{"^^", "&&", "||", "!=", ">=", "<="} {
+		if strings.HasPrefix(l.src[l.pos:], p) {
+			l.pos += len(p)
+			return token{kind: tokPunct, text: p, pos: start}, nil
+		}
+	}
+	if strings.ContainsRune("{}().;,*=<>!+-/", rune(c)) {
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+	}
+
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	if isNameStart(r) || r == ':' {
+		prefix := l.scanName()
+		if l.pos < len(l.src) && l.src[l.pos] == ':' {
+			l.pos++
+			local := l.scanLocal()
+			return token{kind: tokPName, text: prefix + ":" + local, pos: start}, nil
+		}
+		return token{kind: tokKeyword, text: prefix, pos: start}, nil
+	}
+
+	return token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+// scanIRI consumes an IRI reference if one starts here. A '<' that is not
+// followed by a well-formed IRI is left for the comparison operators.
+func (l *lexer) scanIRI() (string, bool) {
+	for i := l.pos + 1; i < len(l.src); i++ {
+		switch c := l.src[i]; {
+		case c == '>':
+			iri := l.src[l.pos+1 : i]
+			l.pos = i + 1
+			return iri, true
+		case c <= ' ' || strings.ContainsRune("<\"{}|^`\\", rune(c)):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func (l *lexer) scanWhile(ok func(rune) bool) string {
+	start := l.pos
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !ok(r) {
+			break
+		}
+		l.pos += size
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *lexer) scanName() string {
+	return l.scanWhile(func(r rune) bool { return isNameStart(r) || isDigit(byte(r)) && r < 128 || r == '-' })
+}
+
+// scanLocal reads the local part of a prefixed name. Dots are allowed inside
+// but not at the end, where they terminate a triple.
+func (l *lexer) scanLocal() string {
+	local := l.scanWhile(func(r rune) bool {
+		return isNameStart(r) || isDigit(byte(r)) && r < 128 || r == '-' || r == '.' || r == '%'
+	})
+	for strings.HasSuffix(local, ".") {
+		local = local[:len(local)-1]
+		l.pos--
+	}
+	return local
+}
+
+func (l *lexer) scanNumber() string {
+	start := l.pos
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	if l.pos+1 < len(l.src) && l.src[l.pos] == '.' && isDigit(l.src[l.pos+1]) {
+		l.pos++
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	}
+	return l.src[start:l.pos]
+}
+
+func (l *lexer) scanString() (string, error) {
+	start := l.pos
+	quote := l.src[l.pos]
+	long := strings.HasPrefix(l.src[l.pos:], strings.Repeat(string(quote), 3))
+	if long {
+		l.pos += 3
+	} else {
+		l.pos++
+	}
+
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if long && strings.HasPrefix(l.src[l.pos:], strings.Repeat(string(quote), 3)) {
+			l.pos += 3
+			return b.String(), nil
+		}
+		if !long && c == quote {
+			l.pos++
+			return b.String(), nil
+		}
+		if !long && (c == '\n' || c == '\r') {
+			return "", l.errorf(start, "unterminated string")
+		}
+		if c == '\\' {
+			if l.pos+1 >= len(l.src) {
+				break
+			}
+			esc := l.src[l.pos+1]
+			switch esc {
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '"', '\'', '\\':
+				b.WriteByte(esc)
+			default:
+				return "", l.errorf(l.pos, "unknown escape \\%c", esc)
+			}
+			l.pos += 2
+			continue
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	return "", l.errorf(start, "unterminated string")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isAlnum(r rune) bool {
+	return r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func isNameStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
@@ -0,0 +1,806 @@
+package sparql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+const (
+	rdfType     = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	xsdString   = "http://www.w3.org/2001/XMLSchema#string"
+	xsdInteger  = "http://www.w3.org/2001/XMLSchema#integer"
+	xsdDecimal  = "http://www.w3.org/2001/XMLSchema#decimal"
+	xsdDouble   = "http://www.w3.org/2001/XMLSchema#double"
+	xsdBoolean  = "http://www.w3.org/2001/XMLSchema#boolean"
+	xsdDateTime = "http://www.w3.org/2001/XMLSchema#dateTime"
+)
+
+type QueryForm int
+
+const (
+	Select QueryForm = iota + 1
+	Ask
+	Construct
+)
+
+// Query is a parsed SPARQL query.
+type Query struct {
+	Form     QueryForm
+	Distinct bool
+	// Vars lists the projected variables; empty means SELECT *.
+	Vars     []string
+	Template []triplePattern
+	Where    *group
+	OrderBy  []orderCond
+	Limit    int
+	Offset   int
+}
+
+// node is a triple pattern position: either a variable or a constant term.
+type node struct {
+	Var  string
+	Term rdf.Term
+}
+
+type triplePattern struct {
+	S, P, O node
+}
+
+type element interface{}
+
+type bgp struct {
+	patterns []triplePattern
+}
+
+type optional struct {
+	group *group
+}
+
+type union struct {
+	branches []*group
+}
+
+type group struct {
+	elements []element
+	filters  []expr
+}
+
+type orderCond struct {
+	expr expr
+	desc bool
+}
+
+type parser struct {
+	tokens   []token
+	pos      int
+	prefixes map[string]string
+	base     string
+	vars     []string
+	seenVars map[string]bool
+}
+
+// Parse parses a SELECT, ASK, or CONSTRUCT query.
+func Parse(src string) (*Query, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		tokens:   tokens,
+		prefixes: make(map[string]string),
+		seenVars: make(map[string]bool),
+	}
+	return p.parseQuery()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) accept(s string) bool {
+	if p.peek().is(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.accept(s) {
+		return p.errorf("expected %q, found %s", s, p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("syntax error at offset %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseQuery() (*Query, error) {
+	if err := p.parsePrologue(); err != nil {
+		return nil, err
+	}
+
+	q := &Query{Limit: -1}
+	var err error
+	switch {
+	case p.accept("SELECT"):
+		q.Form = Select
+		err = p.parseSelect(q)
+	case p.accept("ASK"):
+		q.Form = Ask
+		p.accept("WHERE")
+		q.Where, err = p.parseGroup()
+	case p.accept("CONSTRUCT"):
+		q.Form = Construct
+		err = p.parseConstruct(q)
+	default:
+		return nil, p.errorf("expected SELECT, ASK, or CONSTRUCT, found %s", p.peek())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Form != Ask {
+		if err := p.parseModifiers(q); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %s after query", p.peek())
+	}
+	return q, nil
+}
+
+func (p *parser) parsePrologue() error {
+	for {
+		switch {
+		case p.accept("PREFIX"):
+			tok := p.advance()
+			if tok.kind != tokPName || !strings.HasSuffix(tok.text, ":") {
+				return p.errorf("expected prefix name, found %s", tok)
+			}
+			iri := p.advance()
+			if iri.kind != tokIRI {
+				return p.errorf("expected IRI for prefix %s, found %s", tok.text, iri)
+			}
+			p.prefixes[strings.TrimSuffix(tok.text, ":")] = p.resolve(iri.text)
+		case p.accept("BASE"):
+			iri := p.advance()
+			if iri.kind != tokIRI {
+				return p.errorf("expected IRI after BASE, found %s", iri)
+			}
+			p.base = iri.text
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseSelect(q *Query) error {
+	if p.accept("DISTINCT") || p.accept("REDUCED") {
+		q.Distinct = true
+	}
+
+	if !p.accept("*") {
+		for p.peek().kind == tokVar {
+			q.Vars = append(q.Vars, p.advance().text)
+		}
+		if len(q.Vars) == 0 {
+			return p.errorf("expected variables or * after SELECT, found %s", p.peek())
+		}
+	}
+
+	p.accept("WHERE")
+	where, err := p.parseGroup()
+	if err != nil {
+		return err
+	}
+	q.Where = where
+
+	if len(q.Vars) == 0 {
+		for _, v := range p.vars {
+			if !strings.HasPrefix(v, "_:") {
+				q.Vars = append(q.Vars, v)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseConstruct(q *Query) error {
+	if p.accept("WHERE") {
+		where, err := p.parseGroup()
+		if err != nil {
+			return err
+		}
+		if len(where.elements) != 1 || len(where.filters) != 0 {
+			return p.errorf("CONSTRUCT WHERE requires a basic graph pattern")
+		}
+		b, ok := where.elements[0].(*bgp)
+		if !ok {
+			return p.errorf("CONSTRUCT WHERE requires a basic graph pattern")
+		}
+		q.Template = b.patterns
+		q.Where = where
+		return nil
+	}
+
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	var template []triplePattern
+	for !p.peek().is("}") {
+		patterns, err := p.parseTriples()
+		if err != nil {
+			return err
+		}
+		template = append(template, patterns...)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if err := p.expect("}"); err != nil {
+		return err
+	}
+	q.Template = template
+
+	p.accept("WHERE")
+	where, err := p.parseGroup()
+	if err != nil {
+		return err
+	}
+	q.Where = where
+	return nil
+}
+
+func (p *parser) parseModifiers(q *Query) error {
+	if p.accept("ORDER") {
+		if err := p.expect("BY"); err != nil {
+			return err
+		}
+		for {
+			var cond orderCond
+			switch {
+			case p.accept("ASC"), p.accept("DESC"):
+				cond.desc = p.tokens[p.pos-1].is("DESC")
+				if err := p.expect("("); err != nil {
+					return err
+				}
+				e, err := p.parseExpr()
+				if err != nil {
+					return err
+				}
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+				cond.expr = e
+			case p.peek().kind == tokVar:
+				cond.expr = varExpr{name: p.advance().text}
+			case p.peek().is("("):
+				p.advance()
+				e, err := p.parseExpr()
+				if err != nil {
+					return err
+				}
+				if err := p.expect(")"); err != nil {
+					return err
+				}
+				cond.expr = e
+			default:
+				if len(q.OrderBy) == 0 {
+					return p.errorf("expected ordering condition, found %s", p.peek())
+				}
+				goto limits
+			}
+			q.OrderBy = append(q.OrderBy, cond)
+		}
+	}
+
+limits:
+	for i := 0; i < 2; i++ {
+		switch {
+		case p.accept("LIMIT"):
+			n, err := p.parseInt()
+			if err != nil {
+				return err
+			}
+			q.Limit = n
+		case p.accept("OFFSET"):
+			n, err := p.parseInt()
+			if err != nil {
+				return err
+			}
+			q.Offset = n
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseInt() (int, error) {
+	tok := p.advance()
+	if tok.kind != tokNumber {
+		return 0, p.errorf("expected integer, found %s", tok)
+	}
+	n, err := strconv.Atoi(tok.text)
+	if err != nil || n < 0 {
+		return 0, p.errorf("expected non-negative integer, found %s", tok)
+	}
+	return n, nil
+}
+
+func (p *parser) parseGroup() (*group, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	g := &group{}
+	for {
+		tok := p.peek()
+		switch {
+		case tok.is("}"):
+			p.advance()
+			return g, nil
+		case tok.kind == tokEOF:
+			return nil, p.errorf("unterminated group pattern")
+		case tok.is("."):
+			p.advance()
+		case tok.is("FILTER"):
+			p.advance()
+			e, err := p.parseConstraint()
+			if err != nil {
+				return nil, err
+			}
+			g.filters = append(g.filters, e)
+		case tok.is("OPTIONAL"):
+			p.advance()
+			inner, err := p.parseGroup()
+			if err != nil {
+				return nil, err
+			}
+			g.elements = append(g.elements, &optional{group: inner})
+		case tok.is("{"):
+			first, err := p.parseGroup()
+			if err != nil {
+				return nil, err
+			}
+			branches := []*group{first}
+			for p.accept("UNION") {
+				next, err := p.parseGroup()
+				if err != nil {
+					return nil, err
+				}
+				branches = append(branches, next)
+			}
+			if len(branches) == 1 {
+				g.elements = append(g.elements, first)
+			} else {
+				g.elements = append(g.elements, &union{branches: branches})
+			}
+		default:
+			patterns, err := p.parseTriples()
+			if err != nil {
+				return nil, err
+			}
+			if last, ok := lastBGP(g); ok {
+				last.patterns = append(last.patterns, patterns...)
+			} else {
+				g.elements = append(g.elements, &bgp{patterns: patterns})
+			}
+		}
+	}
+}
+
+func lastBGP(g *group) (*bgp, bool) {
+	if len(g.elements) == 0 {
+		return nil, false
+	}
+	b, ok := g.elements[len(g.elements)-1].(*bgp)
+	return b, ok
+}
+
+// parseTriples reads one subject with its property list.
+func (p *parser) parseTriples() ([]triplePattern, error) {
+	subj, err := p.parseNode(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []triplePattern
+	for {
+		pred, err := p.parseVerb()
+		if err != nil {
+			return nil, err
+		}
+		for {
+			obj, err := p.parseNode(true)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, triplePattern{S: subj, P: pred, O: obj})
+			if !p.accept(",") {
+				break
+			}
+		}
+		if !p.accept(";") {
+			return out, nil
+		}
+		for p.accept(";") {
+		}
+		if tok := p.peek(); tok.is(".") || tok.is("}") {
+			return out, nil
+		}
+	}
+}
+
+func (p *parser) parseVerb() (node, error) {
+	if p.peek().kind == tokKeyword && p.peek().text == "a" {
+		p.advance()
+		return node{Term: rdf.NewResource(rdfType)}, nil
+	}
+	n, err := p.parseNode(false)
+	if err != nil {
+		return node{}, err
+	}
+	if n.Term != nil {
+		if _, ok := n.Term.(*rdf.Resource); !ok {
+			return node{}, p.errorf("predicate must be an IRI or variable")
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) parseNode(allowLiteral bool) (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokVar:
+		p.advance()
+		p.noteVar(tok.text)
+		return node{Var: tok.text}, nil
+	case tokBlank:
+		p.advance()
+		name := "_:" + tok.text
+		p.noteVar(name)
+		return node{Var: name}, nil
+	case tokIRI, tokPName:
+		term, err := p.parseIRI()
+		if err != nil {
+			return node{}, err
+		}
+		return node{Term: term}, nil
+	}
+
+	if !allowLiteral {
+		return node{}, p.errorf("expected variable or IRI, found %s", tok)
+	}
+	term, err := p.parseLiteral()
+	if err != nil {
+		return node{}, err
+	}
+	return node{Term: term}, nil
+}
+
+func (p *parser) noteVar(name string) {
+	if !p.seenVars[name] {
+		p.seenVars[name] = true
+		p.vars = append(p.vars, name)
+	}
+}
+
+func (p *parser) parseIRI() (rdf.Term, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokIRI:
+		return rdf.NewResource(p.resolve(tok.text)), nil
+	case tokPName:
+		prefix, local, _ := strings.Cut(tok.text, ":")
+		ns, ok := p.prefixes[prefix]
+		if !ok {
+			return nil, fmt.Errorf("syntax error at offset %d: undeclared prefix %q", tok.pos, prefix)
+		}
+		return rdf.NewResource(ns + local), nil
+	}
+	return nil, fmt.Errorf("syntax error at offset %d: expected IRI, found %s", tok.pos, tok)
+}
+
+func (p *parser) resolve(iri string) string {
+	if p.base == "" || strings.Contains(iri, ":") {
+		return iri
+	}
+	return p.base + iri
+}
+
+func (p *parser) parseLiteral() (rdf.Term, error) {
+	tok := p.peek()
+	neg := false
+	if tok.is("-") || tok.is("+") {
+		neg = tok.is("-")
+		p.advance()
+		tok = p.peek()
+		if tok.kind != tokNumber {
+			return nil, p.errorf("expected number, found %s", tok)
+		}
+	}
+
+	switch {
+	case tok.kind == tokString:
+		p.advance()
+		if p.peek().kind == tokLangTag {
+			return rdf.NewLiteralWithLanguage(tok.text, p.advance().text), nil
+		}
+		if p.accept("^^") {
+			dt, err := p.parseIRI()
+			if err != nil {
+				return nil, err
+			}
+			return rdf.NewLiteralWithDatatype(tok.text, dt), nil
+		}
+		return rdf.NewLiteral(tok.text), nil
+	case tok.kind == tokNumber:
+		p.advance()
+		text := tok.text
+		if neg {
+			text = "-" + text
+		}
+		return numberLiteral(text), nil
+	case tok.kind == tokKeyword && (strings.EqualFold(tok.text, "true") || strings.EqualFold(tok.text, "false")):
+		p.advance()
+		return rdf.NewLiteralWithDatatype(strings.ToLower(tok.text), rdf.NewResource(xsdBoolean)), nil
+	}
+	return nil, p.errorf("expected term, found %s", tok)
+}
+
+func numberLiteral(text string) rdf.Term {
+	dt := xsdInteger
+	switch {
+	case strings.ContainsAny(text, "eE"):
+		dt = xsdDouble
+	case strings.Contains(text, "."):
+		dt = xsdDecimal
+	}
+	return rdf.NewLiteralWithDatatype(text, rdf.NewResource(dt))
+}
+
+func (p *parser) parseConstraint() (expr, error) {
+	if p.peek().is("(") {
+		p.advance()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+	if p.peek().kind == tokKeyword {
+		return p.parseCall()
+	}
+	return nil, p.errorf("expected constraint after FILTER, found %s", p.peek())
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseRelational()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseRelational()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseRelational() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"=", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return binaryExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	negated := false
+	if p.peek().is("NOT") {
+		p.advance()
+		negated = true
+		if !p.peek().is("IN") {
+			return nil, p.errorf("expected IN after NOT, found %s", p.peek())
+		}
+	}
+	if p.accept("IN") {
+		list, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		return inExpr{value: left, list: list, negated: negated}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.accept("+"):
+			op = "+"
+		case p.accept("-"):
+			op = "-"
+		default:
+			return left, nil
+		}
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.accept("*"):
+			op = "*"
+		case p.accept("/"):
+			op = "/"
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	switch {
+	case p.accept("!"):
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "!", arg: e}, nil
+	case p.accept("-"):
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "-", arg: e}, nil
+	case p.accept("+"):
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.peek()
+	switch {
+	case tok.is("("):
+		p.advance()
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tok.kind == tokVar:
+		p.advance()
+		return varExpr{name: tok.text}, nil
+	case tok.kind == tokIRI || tok.kind == tokPName:
+		term, err := p.parseIRI()
+		if err != nil {
+			return nil, err
+		}
+		return constExpr{term: term}, nil
+	case tok.kind == tokKeyword && !strings.EqualFold(tok.text, "true") && !strings.EqualFold(tok.text, "false"):
+		return p.parseCall()
+	}
+
+	term, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+	return constExpr{term: term}, nil
+}
+
+func (p *parser) parseCall() (expr, error) {
+	tok := p.advance()
+	name := strings.ToUpper(tok.text)
+	if _, ok := builtins[name]; !ok && name != "BOUND" {
+		return nil, fmt.Errorf("syntax error at offset %d: unknown function %s", tok.pos, tok.text)
+	}
+
+	args, err := p.parseArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "BOUND" {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("syntax error at offset %d: BOUND takes one variable", tok.pos)
+		}
+		v, ok := args[0].(varExpr)
+		if !ok {
+			return nil, fmt.Errorf("syntax error at offset %d: BOUND takes one variable", tok.pos)
+		}
+		return boundExpr{name: v.name}, nil
+	}
+	return callExpr{name: name, args: args}, nil
+}
+
+func (p *parser) parseArgs() ([]expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []expr
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, e)
+		if p.accept(")") {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
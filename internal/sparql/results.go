@@ -0,0 +1,172 @@
+package sparql
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	rdf "github.com/deiu/rdf2go"
+)
+
+type ResultFormat string
+
+const (
+	Table ResultFormat = "table"
+	CSV   ResultFormat = "csv"
+	JSON  ResultFormat = "json"
+)
+
+func ParseResultFormat(name string) (ResultFormat, error) {
+	switch strings.ToLower(name) {
+	case "table":
+		return Table, nil
+	case "csv":
+		return CSV, nil
+	case "json", "sparql-json":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unknown results format %q (want table, csv, or json)", name)
+}
+
+// WriteResults writes SELECT or ASK results. CONSTRUCT results are RDF and
+// are written with the export package instead.
+func WriteResults(w io.Writer, res *Result, format ResultFormat) error {
+	if res.Form == Construct {
+		return fmt.Errorf("CONSTRUCT results must be written as RDF")
+	}
+
+	switch format {
+	case Table:
+		return writeTable(w, res)
+	case CSV:
+		return writeCSV(w, res)
+	case JSON:
+		return writeJSON(w, res)
+	}
+	return fmt.Errorf("unknown results format %q", format)
+}
+
+func writeTable(w io.Writer, res *Result) error {
+	if res.Form == Ask {
+		_, err := fmt.Fprintln(w, strconv.FormatBool(res.Boolean))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(res.Vars))
+	for i, v := range res.Vars {
+		header[i] = "?" + v
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, row := range res.Bindings {
+		cells := make([]string, len(res.Vars))
+		for i, v := range res.Vars {
+			if t, ok := row[v]; ok {
+				cells[i] = t.String()
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeCSV follows the SPARQL 1.1 CSV results format: plain values, with
+// blank nodes as _:label.
+func writeCSV(w io.Writer, res *Result) error {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	if res.Form == Ask {
+		cw.Write([]string{"boolean"})
+		cw.Write([]string{strconv.FormatBool(res.Boolean)})
+		cw.Flush()
+		return cw.Error()
+	}
+
+	cw.Write(res.Vars)
+	for _, row := range res.Bindings {
+		record := make([]string, len(res.Vars))
+		for i, v := range res.Vars {
+			t, ok := row[v]
+			if !ok {
+				continue
+			}
+			if b, isBlank := t.(*rdf.BlankNode); isBlank {
+				record[i] = b.String()
+			} else {
+				record[i] = t.RawValue()
+			}
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonTerm struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Lang     string `json:"xml:lang,omitempty"`
+	Datatype string `json:"datatype,omitempty"`
+}
+
+type jsonResults struct {
+	Head    jsonHead      `json:"head"`
+	Results *jsonBindings `json:"results,omitempty"`
+	Boolean *bool         `json:"boolean,omitempty"`
+}
+
+type jsonHead struct {
+	Vars []string `json:"vars,omitempty"`
+}
+
+type jsonBindings struct {
+	Bindings []map[string]jsonTerm `json:"bindings"`
+}
+
+// writeJSON writes the SPARQL 1.1 Query Results JSON format.
+func writeJSON(w io.Writer, res *Result) error {
+	out := jsonResults{}
+	if res.Form == Ask {
+		b := res.Boolean
+		out.Boolean = &b
+	} else {
+		out.Head.Vars = res.Vars
+		if out.Head.Vars == nil {
+			out.Head.Vars = []string{}
+		}
+		out.Results = &jsonBindings{Bindings: make([]map[string]jsonTerm, 0, len(res.Bindings))}
+		for _, row := range res.Bindings {
+			b := make(map[string]jsonTerm, len(row))
+			for v, t := range row {
+				b[v] = toJSONTerm(t)
+			}
+			out.Results.Bindings = append(out.Results.Bindings, b)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func toJSONTerm(t rdf.Term) jsonTerm {
+	switch t := t.(type) {
+	case *rdf.Resource:
+		return jsonTerm{Type: "uri", Value: t.URI}
+	case *rdf.BlankNode:
+		return jsonTerm{Type: "bnode", Value: t.ID}
+	case *rdf.Literal:
+		jt := jsonTerm{Type: "literal", Value: t.Value, Lang: t.Language}
+		if t.Language == "" && t.Datatype != nil {
+			jt.Datatype = t.Datatype.RawValue()
+		}
+		return jt
+	}
+	return jsonTerm{}
+}
@@ -0,0 +1,271 @@
+package sparql
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	rdf "github.com/deiu/rdf2go"
+)
+
+const prefixes = `
+PREFIX dcterms: <http://purl.org/dc/terms/>
+PREFIX skos: <http://www.w3.org/2004/02/skos/core#>
+PREFIX weave: <http://weave.dev/vocab#>
+PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+`
+
+func testStore() *Store {
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := []markdown.Note{
+		{ID: "rdf-20250101000000", Title: "RDF", Type: "Note", Tags: []string{"rdf"}, Created: ts},
+		{ID: "turtle-20250102000000", Title: "Turtle", Type: "Note", Tags: []string{"rdf"}, Created: ts.AddDate(0, 0, 1),
+			Links: []links.Link{{ID: "rdf-20250101000000", Type: "broader"}}},
+		{ID: "sparql-20250103000000", Title: "SPARQL", Type: "Note", Tags: []string{"rdf", "query"}, Created: ts.AddDate(0, 0, 2),
+			Links: []links.Link{{ID: "rdf-20250101000000", Type: "broader"}, {ID: "turtle-20250102000000", Type: "linksTo"}}},
+		{ID: "go-20250104000000", Title: "Go", Type: "Note", Tags: []string{"go"}, Created: ts.AddDate(0, 0, 3)},
+	}
+	return Load(rdfproj.VaultToTriples(notes, "http://example.org"))
+}
+
+func titles(res *Result, v string) []string {
+	var out []string
+	for _, row := range res.Bindings {
+		if t, ok := row[v]; ok {
+			out = append(out, t.RawValue())
+		} else {
+			out = append(out, "")
+		}
+	}
+	return out
+}
+
+func exec(t *testing.T, q string) *Result {
+	t.Helper()
+	res, err := Exec(testStore(), prefixes+q)
+	if err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	return res
+}
+
+func TestSelectTaggedBroader(t *testing.T) {
+	res := exec(t, `
+SELECT ?title WHERE {
+  ?note dcterms:subject <http://example.org/tags/rdf> ;
+        skos:broader ?parent ;
+        dcterms:title ?title .
+  ?parent dcterms:title "RDF" .
+}
+ORDER BY ?title`)
+
+	got := titles(res, "title")
+	want := []string{"SPARQL", "Turtle"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	res := exec(t, `
+SELECT ?title WHERE {
+  ?note dcterms:title ?title ; dcterms:created ?created .
+  FILTER (?created >= "2025-01-03T00:00:00Z"^^xsd:dateTime && !CONTAINS(LCASE(?title), "go"))
+}`)
+
+	got := titles(res, "title")
+	want := []string{"SPARQL"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestFilterRegexAndIn(t *testing.T) {
+	res := exec(t, `
+SELECT ?title WHERE {
+  ?note dcterms:title ?title .
+  FILTER (REGEX(?title, "^t", "i") || ?title IN ("Go"))
+} ORDER BY DESC(?title)`)
+
+	got := titles(res, "title")
+	want := []string{"Turtle", "Go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestOptional(t *testing.T) {
+	res := exec(t, `
+SELECT ?title ?parentTitle WHERE {
+  ?note dcterms:title ?title .
+  OPTIONAL { ?note skos:broader ?parent . ?parent dcterms:title ?parentTitle }
+} ORDER BY ?title`)
+
+	if got, want := titles(res, "title"), []string{"Go", "RDF", "SPARQL", "Turtle"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+	if got, want := titles(res, "parentTitle"), []string{"", "", "RDF", "RDF"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parent titles = %v, want %v", got, want)
+	}
+}
+
+func TestOptionalNotBound(t *testing.T) {
+	res := exec(t, `
+SELECT ?title WHERE {
+  ?note dcterms:title ?title .
+  OPTIONAL { ?note skos:broader ?parent }
+  FILTER (!BOUND(?parent))
+} ORDER BY ?title`)
+
+	if got, want := titles(res, "title"), []string{"Go", "RDF"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestUnion(t *testing.T) {
+	res := exec(t, `
+SELECT DISTINCT ?title WHERE {
+  ?note dcterms:title ?title .
+  { ?note skos:broader ?x } UNION { ?note dcterms:subject <http://example.org/tags/go> }
+} ORDER BY ?title`)
+
+	if got, want := titles(res, "title"), []string{"Go", "SPARQL", "Turtle"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestLimitOffset(t *testing.T) {
+	res := exec(t, `SELECT ?title WHERE { ?n dcterms:title ?title } ORDER BY ?title LIMIT 2 OFFSET 1`)
+
+	if got, want := titles(res, "title"), []string{"RDF", "SPARQL"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titles = %v, want %v", got, want)
+	}
+}
+
+func TestSelectStar(t *testing.T) {
+	res := exec(t, `SELECT * WHERE { ?note skos:broader ?parent }`)
+
+	if want := []string{"note", "parent"}; !reflect.DeepEqual(res.Vars, want) {
+		t.Errorf("Vars = %v, want %v", res.Vars, want)
+	}
+	if len(res.Bindings) != 2 {
+		t.Errorf("got %d rows, want 2", len(res.Bindings))
+	}
+}
+
+func TestAsk(t *testing.T) {
+	if res := exec(t, `ASK { ?n dcterms:title "SPARQL" }`); !res.Boolean {
+		t.Error("ASK for existing title = false, want true")
+	}
+	if res := exec(t, `ASK WHERE { ?n dcterms:title "Missing" }`); res.Boolean {
+		t.Error("ASK for missing title = true, want false")
+	}
+}
+
+func TestConstruct(t *testing.T) {
+	res := exec(t, `
+CONSTRUCT { ?parent skos:narrower ?child . _:b weave:about ?child }
+WHERE { ?child skos:broader ?parent }`)
+
+	narrower := 0
+	blanks := map[string]bool{}
+	for _, tr := range res.Triples {
+		if tr.Predicate.RawValue() == "http://www.w3.org/2004/02/skos/core#narrower" {
+			narrower++
+		}
+		if b, ok := tr.Subject.(*rdf.BlankNode); ok {
+			blanks[b.ID] = true
+		}
+	}
+	if narrower != 2 {
+		t.Errorf("constructed %d narrower triples, want 2", narrower)
+	}
+	if len(blanks) != 2 {
+		t.Errorf("constructed %d distinct blank nodes, want 2 (one per solution)", len(blanks))
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	queries := []string{
+		`SELECT ?x WHERE { ?x foo:bar ?y }`,
+		`SELECT WHERE { ?x ?p ?o }`,
+		`SELECT ?x WHERE { ?x ?p ?o `,
+		`DESCRIBE <http://example.org>`,
+		`SELECT ?x WHERE { ?x ?p ?o FILTER(NOPE(?x)) }`,
+	}
+	for _, q := range queries {
+		if _, err := Parse(q); err == nil {
+			t.Errorf("Parse(%q) error = nil, want error", q)
+		}
+	}
+}
+
+func TestWriteResultsJSON(t *testing.T) {
+	res := exec(t, `SELECT ?note ?title WHERE { ?note dcterms:title ?title } ORDER BY ?title LIMIT 1`)
+
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, res, JSON); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+
+	var decoded struct {
+		Head struct {
+			Vars []string `json:"vars"`
+		} `json:"head"`
+		Results struct {
+			Bindings []map[string]map[string]string `json:"bindings"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded.Head.Vars, []string{"note", "title"}) {
+		t.Errorf("head vars = %v", decoded.Head.Vars)
+	}
+	row := decoded.Results.Bindings[0]
+	if row["note"]["type"] != "uri" || row["note"]["value"] != "http://example.org/notes/go-20250104000000" {
+		t.Errorf("note binding = %v", row["note"])
+	}
+	if row["title"]["type"] != "literal" || row["title"]["value"] != "Go" {
+		t.Errorf("title binding = %v", row["title"])
+	}
+}
+
+func TestWriteResultsAsk(t *testing.T) {
+	res := exec(t, `ASK { ?n dcterms:title "Go" }`)
+
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, res, JSON); err != nil {
+		t.Fatalf("WriteResults() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"boolean": true`) {
+		t.Errorf("ASK JSON = %s, want boolean true", buf.String())
+	}
+}
+
+func TestWriteResultsCSVAndTable(t *testing.T) {
+	res := exec(t, `SELECT ?title WHERE { ?note dcterms:title ?title } ORDER BY ?title LIMIT 2`)
+
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, res, CSV); err != nil {
+		t.Fatalf("WriteResults(csv) error = %v", err)
+	}
+	if got, want := buf.String(), "title\r\nGo\r\nRDF\r\n"; got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteResults(&buf, res, Table); err != nil {
+		t.Fatalf("WriteResults(table) error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "?title\n\"Go\"\n") {
+		t.Errorf("table = %q", buf.String())
+	}
+}
@@ -0,0 +1,101 @@
+package sparql
+
+import (
+	rdf "github.com/deiu/rdf2go"
+)
+
+// Store is an in-memory triple store indexed by subject, predicate, and
+// object. Duplicate triples are stored once.
+type Store struct {
+	triples     []*rdf.Triple
+	seen        map[string]bool
+	bySubject   map[string][]int
+	byPredicate map[string][]int
+	byObject    map[string][]int
+}
+
+func NewStore() *Store {
+	return &Store{
+		seen:        make(map[string]bool),
+		bySubject:   make(map[string][]int),
+		byPredicate: make(map[string][]int),
+		byObject:    make(map[string][]int),
+	}
+}
+
+// Load builds a store from a slice of triples.
+func Load(triples []*rdf.Triple) *Store {
+	s := NewStore()
+	for _, t := range triples {
+		s.Add(t)
+	}
+	return s
+}
+
+func (s *Store) Add(t *rdf.Triple) {
+	key := t.String()
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+
+	i := len(s.triples)
+	s.triples = append(s.triples, t)
+	s.bySubject[t.Subject.String()] = append(s.bySubject[t.Subject.String()], i)
+	s.byPredicate[t.Predicate.String()] = append(s.byPredicate[t.Predicate.String()], i)
+	s.byObject[t.Object.String()] = append(s.byObject[t.Object.String()], i)
+}
+
+func (s *Store) Len() int {
+	return len(s.triples)
+}
+
+// Triples returns every triple in insertion order.
+func (s *Store) Triples() []*rdf.Triple {
+	return append([]*rdf.Triple(nil), s.triples...)
+}
+
+// Match returns the triples matching the given terms; nil matches anything.
+func (s *Store) Match(subj, pred, obj rdf.Term) []*rdf.Triple {
+	var candidates []int
+	all := true
+
+	narrow := func(index map[string][]int, t rdf.Term) {
+		if t == nil {
+			return
+		}
+		ids := index[t.String()]
+		if all || len(ids) < len(candidates) {
+			candidates = ids
+			all = false
+		}
+	}
+	narrow(s.bySubject, subj)
+	narrow(s.byObject, obj)
+	narrow(s.byPredicate, pred)
+
+	var out []*rdf.Triple
+	check := func(t *rdf.Triple) {
+		if subj != nil && !subj.Equal(t.Subject) {
+			return
+		}
+		if pred != nil && !pred.Equal(t.Predicate) {
+			return
+		}
+		if obj != nil && !obj.Equal(t.Object) {
+			return
+		}
+		out = append(out, t)
+	}
+
+	if all {
+		for _, t := range s.triples {
+			check(t)
+		}
+		return out
+	}
+	for _, i := range candidates {
+		check(s.triples[i])
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/DeDude/weave2/internal/graph"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"github.com/DeDude/weave2/internal/search"
+)
+
+// Indexer keeps a search index and link graph in step with the vault by
+// applying watcher events one file at a time.
+type Indexer struct {
+	Search *search.Index
+	Graph  *graph.Graph
+
+	mu  sync.Mutex
+	ids map[string]string
+}
+
+// NewIndexer loads the vault into a fresh index and graph. Files that fail
+// to load are returned and left out.
+func NewIndexer(ctx context.Context, vaultPath string, proj *rdfproj.Projector) (*Indexer, []error) {
+	ix := &Indexer{
+		Search: search.NewIndex(),
+		Graph:  graph.New(proj),
+		ids:    make(map[string]string),
+	}
+
+	var errs []error
+	for entry, err := range notes.All(ctx, vaultPath, notes.Filter{}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ix.set(entry)
+	}
+
+	return ix, errs
+}
+
+// Apply updates the index and graph for a batch of events. Files that can
+// no longer be parsed are dropped and their errors returned.
+func (ix *Indexer) Apply(events []Event) []error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	var errs []error
+	for _, ev := range events {
+		switch ev.Op {
+		case Deleted:
+			ix.remove(ev.Path)
+		case Renamed:
+			ix.remove(ev.OldPath)
+			if err := ix.load(ev.Path); err != nil {
+				errs = append(errs, err)
+			}
+		case Created, Modified:
+			if err := ix.load(ev.Path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errs
+}
+
+func (ix *Indexer) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		ix.remove(path)
+		return &notes.LoadError{Path: path, Err: fmt.Errorf("read failed: %w", err)}
+	}
+
+	note, err := markdown.Read(data)
+	if err != nil {
+		ix.remove(path)
+		return &notes.LoadError{Path: path, Err: fmt.Errorf("parse failed: %w", err)}
+	}
+
+	if id, ok := ix.ids[path]; ok && id != note.ID {
+		ix.Graph.RemoveNote(id)
+	}
+	ix.set(notes.Entry{Path: path, Note: note})
+	return nil
+}
+
+func (ix *Indexer) set(entry notes.Entry) {
+	ix.ids[entry.Path] = entry.Note.ID
+	ix.Search.Set(entry)
+	ix.Graph.SetNote(entry.Note)
+}
+
+func (ix *Indexer) remove(path string) {
+	ix.Search.Remove(path)
+	if id, ok := ix.ids[path]; ok {
+		ix.Graph.RemoveNote(id)
+		delete(ix.ids, path)
+	}
+}
@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/links"
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/DeDude/weave2/internal/rdfproj"
+	"github.com/DeDude/weave2/internal/search"
+)
+
+func TestIndexerApply(t *testing.T) {
+	vault := t.TempDir()
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	targetID, err := notes.Create(vault, markdown.Note{Title: "Target"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	ix, errs := NewIndexer(context.Background(), vault, rdfproj.New("http://example.org", nil))
+	if len(errs) > 0 {
+		t.Fatalf("NewIndexer() errors = %v", errs)
+	}
+
+	sourceID, err := notes.Create(vault, markdown.Note{
+		Title: "Source",
+		Links: []links.Link{{ID: targetID, Type: links.DefaultLinkType}},
+	}, ts.Add(time.Second))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	sourcePath, _ := notes.ResolvePath(vault, sourceID)
+
+	if errs := ix.Apply([]Event{{Op: Created, Path: sourcePath}}); len(errs) > 0 {
+		t.Fatalf("Apply(created) errors = %v", errs)
+	}
+	if got := ix.Search.Search(search.Query{Term: "source"}); len(got) != 1 {
+		t.Errorf("Search after create returned %d results, want 1", len(got))
+	}
+	if got := ix.Graph.Backlinks(targetID); len(got) != 1 {
+		t.Errorf("Backlinks after create = %v, want 1", got)
+	}
+
+	if err := os.Remove(sourcePath); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if errs := ix.Apply([]Event{{Op: Deleted, Path: sourcePath}}); len(errs) > 0 {
+		t.Fatalf("Apply(deleted) errors = %v", errs)
+	}
+	if got := ix.Search.Search(search.Query{Term: "source"}); len(got) != 0 {
+		t.Errorf("Search after delete returned %d results, want 0", len(got))
+	}
+	if got := ix.Graph.Backlinks(targetID); len(got) != 0 {
+		t.Errorf("Backlinks after delete = %v, want none", got)
+	}
+}
@@ -0,0 +1,313 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type Op int
+
+const (
+	Created Op = iota + 1
+	Modified
+	Deleted
+	Renamed
+)
+
+func (op Op) String() string {
+	switch op {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Deleted:
+		return "deleted"
+	case Renamed:
+		return "renamed"
+	}
+	return fmt.Sprintf("Op(%d)", int(op))
+}
+
+// Event describes a change to a note file. OldPath is only set for Renamed.
+type Event struct {
+	Op      Op
+	Path    string
+	OldPath string
+}
+
+type Options struct {
+	// Debounce is how long the vault must be quiet before a batch of
+	// changes is reported. Editors and safe writes touch several files in
+	// quick succession; they are collapsed into one batch.
+	Debounce time.Duration
+	// Poll forces the polling backend even where file system notifications
+	// are available.
+	Poll         bool
+	PollInterval time.Duration
+	// OnError receives backend errors the watcher recovers from, such as
+	// an overflowing event queue. It may be nil.
+	OnError func(error)
+}
+
+const (
+	defaultDebounce     = 200 * time.Millisecond
+	defaultPollInterval = time.Second
+)
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher reports created, modified, deleted, and renamed notes in a vault.
+// Backends only signal that something may have changed; the watcher then
+// rescans the vault and diffs it against the last known state, so both
+// backends produce the same events.
+type Watcher struct {
+	vaultPath string
+	opts      Options
+	state     map[string]fileState
+}
+
+func New(vaultPath string, opts Options) (*Watcher, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = defaultDebounce
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	state, err := scan(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{vaultPath: vaultPath, opts: opts, state: state}, nil
+}
+
+// Run watches until ctx is cancelled, calling fn with each non-empty batch
+// of events. fn runs on the watcher's goroutine.
+func (w *Watcher) Run(ctx context.Context, fn func([]Event)) error {
+	if !w.opts.Poll {
+		fsw, err := fsnotify.NewWatcher()
+		if err == nil {
+			defer fsw.Close()
+			return w.runNotify(ctx, fsw, fn)
+		}
+	}
+	return w.runPoll(ctx, fn)
+}
+
+func (w *Watcher) runPoll(ctx context.Context, fn func([]Event)) error {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.flush(fn); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) runNotify(ctx context.Context, fsw *fsnotify.Watcher, fn func([]Event)) error {
+	if err := addDirs(fsw, w.vaultPath); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(w.opts.Debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			// Events may have been lost, so rescan everything rather than
+			// stop watching; directories created meanwhile need watches.
+			if w.opts.OnError != nil {
+				w.opts.OnError(fmt.Errorf("watch vault: %w", err))
+			}
+			if err := addDirs(fsw, w.vaultPath); err != nil {
+				return err
+			}
+			if err := w.flush(fn); err != nil {
+				return err
+			}
+		case ev, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := addDirs(fsw, ev.Name); err != nil {
+						return err
+					}
+				}
+			}
+			if ignored(ev.Name) {
+				continue
+			}
+			timer.Reset(w.opts.Debounce)
+		case <-timer.C:
+			if err := w.flush(fn); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher) flush(fn func([]Event)) error {
+	state, err := scan(w.vaultPath)
+	if err != nil {
+		return err
+	}
+
+	events := diff(w.state, state)
+	w.state = state
+	if len(events) > 0 {
+		fn(events)
+	}
+	return nil
+}
+
+// ignored reports whether a notification can be dropped without rescanning:
+// temp files from safe writes, editor swap/backup files, and anything else
+// that is not a note. Paths without an extension are kept since they may be
+// directories that were removed.
+func ignored(path string) bool {
+	ext := filepath.Ext(path)
+	return ext != "" && ext != ".md"
+}
+
+func skipDir(root, path string, d fs.DirEntry) bool {
+	return path != root && strings.HasPrefix(d.Name(), ".")
+}
+
+func addDirs(fsw *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if skipDir(root, path, d) {
+			return filepath.SkipDir
+		}
+		if err := fsw.Add(path); err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func scan(vaultPath string) (map[string]fileState, error) {
+	state := make(map[string]fileState)
+
+	err := filepath.WalkDir(vaultPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			if skipDir(vaultPath, path, d) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".md" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		state[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan vault: %w", err)
+	}
+
+	return state, nil
+}
+
+// diff compares two scans. A file that disappears while another with the
+// same size and modification time appears is reported as a rename, since
+// renames keep both.
+func diff(old, cur map[string]fileState) []Event {
+	var created, deleted []string
+	var events []Event
+
+	for path, st := range cur {
+		prev, ok := old[path]
+		if !ok {
+			created = append(created, path)
+			continue
+		}
+		if prev != st {
+			events = append(events, Event{Op: Modified, Path: path})
+		}
+	}
+	for path := range old {
+		if _, ok := cur[path]; !ok {
+			deleted = append(deleted, path)
+		}
+	}
+
+	sort.Strings(created)
+	sort.Strings(deleted)
+
+	paired := make(map[string]bool)
+	for _, from := range deleted {
+		renamed := false
+		for _, to := range created {
+			if paired[to] || old[from] != cur[to] {
+				continue
+			}
+			paired[to] = true
+			renamed = true
+			events = append(events, Event{Op: Renamed, Path: to, OldPath: from})
+			break
+		}
+		if !renamed {
+			events = append(events, Event{Op: Deleted, Path: from})
+		}
+	}
+	for _, path := range created {
+		if !paired[path] {
+			events = append(events, Event{Op: Created, Path: path})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Path != events[j].Path {
+			return events[i].Path < events[j].Path
+		}
+		return events[i].Op < events[j].Op
+	})
+	return events
+}
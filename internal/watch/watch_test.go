@@ -0,0 +1,176 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DeDude/weave2/internal/markdown"
+	"github.com/DeDude/weave2/internal/notes"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestDiff(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+
+	old := map[string]fileState{
+		"/v/a.md": {size: 10, modTime: t0},
+		"/v/b.md": {size: 20, modTime: t0},
+		"/v/c.md": {size: 30, modTime: t0},
+	}
+	cur := map[string]fileState{
+		"/v/a.md": {size: 11, modTime: t1},
+		"/v/d.md": {size: 20, modTime: t0},
+		"/v/e.md": {size: 5, modTime: t1},
+	}
+
+	got := diff(old, cur)
+	want := []Event{
+		{Op: Modified, Path: "/v/a.md"},
+		{Op: Deleted, Path: "/v/c.md"},
+		{Op: Renamed, Path: "/v/d.md", OldPath: "/v/b.md"},
+		{Op: Created, Path: "/v/e.md"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff() = %v, want %v", got, want)
+	}
+}
+
+func runWatcher(t *testing.T, vault string, opts Options) (<-chan []Event, context.CancelFunc) {
+	t.Helper()
+
+	w, err := New(vault, opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := make(chan []Event, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := w.Run(ctx, func(events []Event) { batches <- events }); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}()
+	// Give the backend a moment to register its watches.
+	time.Sleep(50 * time.Millisecond)
+
+	return batches, func() {
+		cancel()
+		<-done
+	}
+}
+
+func waitForEvent(t *testing.T, batches <-chan []Event, op Op, path string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case events := <-batches:
+			for _, ev := range events {
+				if filepath.Ext(ev.Path) != ".md" {
+					t.Errorf("event for non-note file: %v", ev)
+				}
+				if ev.Op == op && ev.Path == path {
+					return
+				}
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s %s", op, path)
+		}
+	}
+}
+
+func testWatcherBackend(t *testing.T, opts Options) {
+	vault := t.TempDir()
+	batches, stop := runWatcher(t, vault, opts)
+	defer stop()
+
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := notes.Create(vault, markdown.Note{Title: "Watched"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	path, _ := notes.ResolvePath(vault, id)
+	waitForEvent(t, batches, Created, path)
+
+	if err := notes.Update(vault, id, markdown.Note{Title: "Watched", Body: "changed body"}, ts.Add(time.Hour)); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	waitForEvent(t, batches, Modified, path)
+
+	renamed := filepath.Join(filepath.Dir(path), "renamed-20250101000000.md")
+	if err := os.Rename(path, renamed); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	waitForEvent(t, batches, Renamed, renamed)
+
+	if err := os.Remove(renamed); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	waitForEvent(t, batches, Deleted, renamed)
+}
+
+func TestWatcherNotify(t *testing.T) {
+	testWatcherBackend(t, Options{Debounce: 20 * time.Millisecond})
+}
+
+func TestWatcherPoll(t *testing.T) {
+	testWatcherBackend(t, Options{Poll: true, PollInterval: 20 * time.Millisecond})
+}
+
+func TestWatcherNotifyRecoversFromErrors(t *testing.T) {
+	vault := t.TempDir()
+	reported := make(chan error, 1)
+	w, err := New(vault, Options{Debounce: 20 * time.Millisecond, OnError: func(err error) { reported <- err }})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// Written before the backend starts, so only a rescan can find it.
+	ts := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	id, err := notes.Create(vault, markdown.Note{Title: "Missed"}, ts)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	missed, _ := notes.ResolvePath(vault, id)
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Skipf("no file system notifications: %v", err)
+	}
+	defer fsw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	batches := make(chan []Event, 16)
+	done := make(chan error)
+	go func() {
+		done <- w.runNotify(ctx, fsw, func(events []Event) { batches <- events })
+	}()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("runNotify() error = %v", err)
+		}
+	}()
+
+	fsw.Errors <- fsnotify.ErrEventOverflow
+	if err := <-reported; !errors.Is(err, fsnotify.ErrEventOverflow) {
+		t.Errorf("OnError() got %v", err)
+	}
+	waitForEvent(t, batches, Created, missed)
+
+	id, err = notes.Create(vault, markdown.Note{Title: "Later"}, ts.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	later, _ := notes.ResolvePath(vault, id)
+	waitForEvent(t, batches, Created, later)
+}